@@ -0,0 +1,65 @@
+package geo
+
+// Represents a street address broken out into its components.
+// Each component holds both its long name (e.g. "California")
+// and its short name (e.g. "CA"), and is left empty if the provider did not return it.
+type Address struct {
+	FormattedAddress string
+
+	StreetNumber AddressComponent
+	Route        AddressComponent
+	Locality     AddressComponent
+	// The first-order civil entity below the country, e.g. a state or province.
+	AdministrativeArea AddressComponent
+	PostalCode         AddressComponent
+	Country            AddressComponent
+
+	// Every component that was returned, including those without a field of their own.
+	Components []*AddressComponent
+}
+
+type AddressComponent struct {
+	LongName  string   `json:"long_name"`
+	ShortName string   `json:"short_name"`
+	Types     []string `json:"types"`
+}
+
+// Returns whether or not the AddressComponent is of the passed in type.
+func (c *AddressComponent) HasType(t string) bool {
+	for _, componentType := range c.Types {
+		if componentType == t {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Creates and returns a pointer to a new Address, filling in each of its fields
+// from the first matching component of the passed in components.
+func newAddress(formattedAddress string, components []*AddressComponent) *Address {
+	a := &Address{FormattedAddress: formattedAddress, Components: components}
+
+	fields := []struct {
+		componentType string
+		field         *AddressComponent
+	}{
+		{"street_number", &a.StreetNumber},
+		{"route", &a.Route},
+		{"locality", &a.Locality},
+		{"administrative_area_level_1", &a.AdministrativeArea},
+		{"postal_code", &a.PostalCode},
+		{"country", &a.Country},
+	}
+
+	for _, f := range fields {
+		for _, c := range components {
+			if c.HasType(f.componentType) {
+				*f.field = *c
+				break
+			}
+		}
+	}
+
+	return a
+}
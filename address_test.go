@@ -0,0 +1,35 @@
+package geo
+
+import (
+	"testing"
+)
+
+func TestNewAddress(t *testing.T) {
+	components := []*AddressComponent{
+		{LongName: "Brooklyn", ShortName: "Brooklyn", Types: []string{"sublocality", "political"}},
+		{LongName: "New York", ShortName: "NY", Types: []string{"administrative_area_level_1", "political"}},
+		{LongName: "United States", ShortName: "US", Types: []string{"country", "political"}},
+	}
+
+	a := newAddress("Brooklyn, NY, USA", components)
+
+	if a.AdministrativeArea.ShortName != "NY" || a.Country.LongName != "United States" {
+		t.Errorf("Mismatched address components: %+v", a)
+	}
+
+	if a.Locality.LongName != "" || a.PostalCode.LongName != "" {
+		t.Errorf("Expected components that were not returned to be empty: %+v", a)
+	}
+}
+
+func TestAddressComponentHasType(t *testing.T) {
+	c := &AddressComponent{Types: []string{"locality", "political"}}
+
+	if !c.HasType("political") {
+		t.Error("Expected the component to have the political type")
+	}
+
+	if c.HasType("country") {
+		t.Error("Did not expect the component to have the country type")
+	}
+}
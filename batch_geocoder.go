@@ -0,0 +1,369 @@
+package geo
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// This is the error that consumers receive for a CSV row that does not have the address column.
+var ErrMissingAddressColumn = errors.New("row does not have the address column")
+
+// This is the error that consumers receive for a nil point passed to ReverseGeocodeMany.
+var ErrNilPoint = errors.New("point is nil")
+
+// A BatchGeocoder geocodes many queries with a bounded number of concurrent requests to another Geocoder.
+// Every query gets a result of its own, so one failure does not abort the rest of the batch.
+type BatchGeocoder struct {
+	Geocoder Geocoder
+
+	// The maximum number of requests in flight at once.  Values below 1 are treated as 1.
+	Concurrency int
+
+	// How long each query of GeocodeBatch, GeocodeBatchContext, and GeocodeCSV may take before it is given up on,
+	// so that one slow query does not hold up the rest of the batch.  A query that times out gets
+	// a result with context.DeadlineExceeded as its Err, possibly wrapped, and the rest are still geocoded.
+	// Zero, the default, does not time queries out.
+	//
+	// Queries are cancelled if the Geocoder is a ContextGeocoder.  Otherwise they are only abandoned:
+	// the result is returned on time, but the Geocoder's call keeps running in the background until it returns.
+	ItemTimeout time.Duration
+
+	// The most requests sent to the Geocoder per second, across all of the workers and batches,
+	// to stay within a provider's rate limit, such as Google's 50 requests per second.
+	// Zero, the default, does not limit the rate.  Addresses served from the cache are not counted.
+	RateLimit float64
+
+	// How long ReverseGeocodeMany keeps each address that it looked up, so that a point repeated within a batch,
+	// or across batches, is only reverse geocoded once.  Zero, the default, does not cache.
+	// Addresses are cached by a TTLCachingGeocoder wrapping the Geocoder, in the same way.
+	// RateLimit and CacheTTL are read when the BatchGeocoder is first used, so set them before then.
+	CacheTTL time.Duration
+
+	// The rate limiter and cache, made from the RateLimit and CacheTTL when the BatchGeocoder is first used.
+	setupOnce sync.Once
+	limiter   *rateLimiter
+	reverser  Geocoder
+}
+
+// Creates and returns a pointer to a new BatchGeocoder
+// that sends at most the passed in number of concurrent requests to the passed in Geocoder.
+func NewBatchGeocoder(g Geocoder, concurrency int) *BatchGeocoder {
+	return &BatchGeocoder{Geocoder: g, Concurrency: concurrency}
+}
+
+// A single query in a batch, and the error that prevented it from being read, if any.
+// Reverse jobs reverse geocode their point rather than geocoding their query.
+type batchJob struct {
+	index   int
+	query   string
+	point   *Point
+	reverse bool
+	err     error
+}
+
+// A single result in a batch, along with its position.
+type batchResult struct {
+	index  int
+	result GeocodeResult
+}
+
+// Geocodes each of the passed in queries, and returns a result for each one, in the same order.
+// Each result holds the query as its Input, and either the Point it was geocoded to or the Err that occurred.
+func (b *BatchGeocoder) GeocodeBatch(queries []string, opts ...GeocodeOption) []GeocodeResult {
+	return b.GeocodeBatchContext(context.Background(), queries, opts...)
+}
+
+// Geocodes each of the passed in queries like GeocodeBatch, within the passed in context.
+// Each query is given a context of its own, derived from it, that times out after the ItemTimeout.
+// Once the passed in context is cancelled, the queries that have not finished get results with its error as their Err.
+func (b *BatchGeocoder) GeocodeBatchContext(ctx context.Context, queries []string, opts ...GeocodeOption) []GeocodeResult {
+	jobs := make(chan batchJob)
+	go func() {
+		defer close(jobs)
+		for i, q := range queries {
+			jobs <- batchJob{index: i, query: q}
+		}
+	}()
+
+	return b.geocodeJobs(ctx, jobs, opts)
+}
+
+// Reverse geocodes each of the passed in points, and returns an address and an error for each one, in the same order.
+// A nil point gets ErrNilPoint as its error, without a request to the Geocoder.
+// Requests are sent at most RateLimit per second, and the addresses are cached for the CacheTTL, if they are set.
+// With a cache, a point repeated within the batch is only reverse geocoded once, and shares its address and error.
+func (b *BatchGeocoder) ReverseGeocodeMany(points []*Point, opts ...GeocodeOption) ([]string, []error) {
+	b.setup()
+
+	// The position of the first occurrence of each point, when repeats are served from the cache.
+	first := make([]int, len(points))
+	seen := map[string]int{}
+	jobs := make(chan batchJob)
+	go func() {
+		defer close(jobs)
+		for i, p := range points {
+			first[i] = i
+			if b.CacheTTL > 0 && p != nil {
+				key := pointCacheKey(p)
+				if j, ok := seen[key]; ok {
+					first[i] = j
+					continue
+				}
+				seen[key] = i
+			}
+
+			job := batchJob{index: i, point: p, reverse: true}
+			if p == nil {
+				job.err = ErrNilPoint
+			}
+
+			jobs <- job
+		}
+	}()
+
+	results := b.geocodeJobs(context.Background(), jobs, opts)
+
+	// The jobs channel is closed after every point is recorded, and geocodeJobs only returns once it is closed.
+	addresses := make([]string, len(points))
+	errs := make([]error, len(points))
+	for i := range points {
+		if j := first[i]; j < len(results) {
+			addresses[i], errs[i] = results[j].FormattedAddress, results[j].Err
+		}
+	}
+
+	return addresses, errs
+}
+
+// Reads CSV records from the passed in reader, geocodes the passed in (zero-based) column of each row,
+// and returns a result for each row, in the same order.  Every row is geocoded, so skip any header row beforehand.
+// Rows are read as they are geocoded, rather than all at once.
+// A row that is malformed, or that lacks the address column, gets a result with the parse error or ErrMissingAddressColumn
+// as its Err, and the rest of the file is still geocoded.
+// Returns an error, along with the results so far, only if reading from the passed in reader fails.
+func (b *BatchGeocoder) GeocodeCSV(r io.Reader, addressColumn int, opts ...GeocodeOption) ([]GeocodeResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	var readErr error
+	jobs := make(chan batchJob)
+	go func() {
+		defer close(jobs)
+		for i := 0; ; i++ {
+			record, err := reader.Read()
+			if err == io.EOF {
+				return
+			}
+
+			if err != nil {
+				if _, ok := err.(*csv.ParseError); !ok {
+					readErr = err
+					return
+				}
+
+				jobs <- batchJob{index: i, err: err}
+				continue
+			}
+
+			if addressColumn < 0 || addressColumn >= len(record) {
+				jobs <- batchJob{index: i, err: ErrMissingAddressColumn}
+				continue
+			}
+
+			jobs <- batchJob{index: i, query: record[addressColumn]}
+		}
+	}()
+
+	results := b.geocodeJobs(context.Background(), jobs, opts)
+
+	// The jobs channel is closed after readErr is set, and geocodeJobs only returns once it is closed.
+	return results, readErr
+}
+
+// Geocodes the passed in jobs with at most Concurrency workers until the channel is closed,
+// and returns their results ordered by job index.
+func (b *BatchGeocoder) geocodeJobs(ctx context.Context, jobs <-chan batchJob, opts []GeocodeOption) []GeocodeResult {
+	b.setup()
+
+	concurrency := b.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	out := make(chan batchResult)
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				out <- batchResult{index: job.index, result: b.geocodeJob(ctx, job, opts)}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	results := []GeocodeResult{}
+	for r := range out {
+		for len(results) <= r.index {
+			results = append(results, GeocodeResult{})
+		}
+
+		results[r.index] = r.result
+	}
+
+	return results
+}
+
+// Geocodes, or reverse geocodes, the passed in job, unless it could not be read.
+func (b *BatchGeocoder) geocodeJob(ctx context.Context, job batchJob, opts []GeocodeOption) GeocodeResult {
+	res := GeocodeResult{Input: job.query, Err: job.err}
+	if job.err != nil {
+		return res
+	}
+
+	if job.reverse {
+		res.Point = job.point
+		res.FormattedAddress, res.Err = b.reverser.ReverseGeocode(job.point, opts...)
+		return res
+	}
+
+	res.Point, res.Err = b.geocodeWithin(ctx, job.query, opts)
+	return res
+}
+
+// Geocodes the passed in query within the passed in context, and within the ItemTimeout if one is set.
+// The query is passed the context if the Geocoder is a ContextGeocoder, and is otherwise abandoned once the context is done.
+func (b *BatchGeocoder) geocodeWithin(ctx context.Context, query string, opts []GeocodeOption) (*Point, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if b.ItemTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.ItemTimeout)
+		defer cancel()
+	}
+
+	if err := b.limiter.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	if g, ok := b.Geocoder.(ContextGeocoder); ok {
+		return g.GeocodeContext(ctx, query, opts...)
+	}
+
+	if ctx.Done() == nil {
+		return b.Geocoder.Geocode(query, opts...)
+	}
+
+	type geocoded struct {
+		point *Point
+		err   error
+	}
+
+	// Buffered, so that an abandoned call can still finish and be collected.
+	done := make(chan geocoded, 1)
+	go func() {
+		p, err := b.Geocoder.Geocode(query, opts...)
+		done <- geocoded{p, err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.point, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Makes the rate limiter from the RateLimit, and the Geocoder that reverse geocodes points within it,
+// through a cache if the CacheTTL is set, the first time the current BatchGeocoder is used.
+func (b *BatchGeocoder) setup() {
+	b.setupOnce.Do(func() {
+		b.limiter = newRateLimiter(b.RateLimit)
+
+		b.reverser = b.Geocoder
+		if b.limiter != nil {
+			b.reverser = &rateLimitedGeocoder{Geocoder: b.Geocoder, limiter: b.limiter}
+		}
+
+		if b.CacheTTL > 0 {
+			b.reverser = NewTTLCachingGeocoder(b.reverser, b.CacheTTL)
+		}
+	})
+}
+
+// Spaces out the requests of a BatchGeocoder's workers so that, together, they send at most a given number per second.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// Creates and returns a pointer to a new rateLimiter for the passed in number of requests per second,
+// or nil, which does not limit the rate, if it is not positive.
+func newRateLimiter(perSecond float64) *rateLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / perSecond)}
+}
+
+// Waits until the next request may be sent, or returns the passed in context's error if it is done first.
+// A nil rateLimiter never waits.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	start := l.next
+	if start.Before(now) {
+		start = now
+	}
+	l.next = start.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := start.Sub(now)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// A Geocoder that waits for a rateLimiter before each request to the Geocoder it wraps.
+type rateLimitedGeocoder struct {
+	Geocoder Geocoder
+	limiter  *rateLimiter
+}
+
+func (r *rateLimitedGeocoder) Geocode(query string, opts ...GeocodeOption) (*Point, error) {
+	r.limiter.wait(context.Background())
+	return r.Geocoder.Geocode(query, opts...)
+}
+
+func (r *rateLimitedGeocoder) ReverseGeocode(p *Point, opts ...GeocodeOption) (string, error) {
+	r.limiter.wait(context.Background())
+	return r.Geocoder.ReverseGeocode(p, opts...)
+}
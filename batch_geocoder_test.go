@@ -0,0 +1,354 @@
+package geo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// A Geocoder used in tests that fails for queries containing "fail",
+// and tracks the most requests it has had in flight at once.
+type concurrencyTrackingGeocoder struct {
+	mu       sync.Mutex
+	inFlight int
+	max      int
+}
+
+var errTestGeocode = errors.New("geocode failed")
+
+func (g *concurrencyTrackingGeocoder) Geocode(query string, opts ...GeocodeOption) (*Point, error) {
+	g.track()
+
+	if strings.Contains(query, "fail") {
+		return nil, errTestGeocode
+	}
+
+	return NewPoint(float64(len(query)), 0), nil
+}
+
+// Fails for points in the southern hemisphere.
+func (g *concurrencyTrackingGeocoder) ReverseGeocode(p *Point, opts ...GeocodeOption) (string, error) {
+	g.track()
+
+	if p.Lat() < 0 {
+		return "", errTestGeocode
+	}
+
+	return fmt.Sprintf("%.0f, %.0f", p.Lat(), p.Lng()), nil
+}
+
+// Records a request as in flight for a moment.
+func (g *concurrencyTrackingGeocoder) track() {
+	g.mu.Lock()
+	g.inFlight++
+	if g.inFlight > g.max {
+		g.max = g.inFlight
+	}
+	g.mu.Unlock()
+
+	time.Sleep(time.Millisecond)
+
+	g.mu.Lock()
+	g.inFlight--
+	g.mu.Unlock()
+}
+
+func TestGeocodeBatch(t *testing.T) {
+	g := &concurrencyTrackingGeocoder{}
+	queries := []string{"a", "bb", "fail", "dddd", "eeeee", "ffffff", "ggggggg", "hhhhhhhh"}
+
+	results := NewBatchGeocoder(g, 3).GeocodeBatch(queries)
+	if len(results) != len(queries) {
+		t.Fatalf("Mismatched number of results.  Expected: %d.  Actual: %d", len(queries), len(results))
+	}
+
+	for i, res := range results {
+		if res.Input != queries[i] {
+			t.Errorf("Mismatched input for result %d.  Expected: %s.  Actual: %s", i, queries[i], res.Input)
+		}
+
+		if queries[i] == "fail" {
+			if res.Err != errTestGeocode {
+				t.Errorf("Mismatched error for result %d.  Expected: %v.  Actual: %v", i, errTestGeocode, res.Err)
+			}
+			continue
+		}
+
+		if res.Err != nil || res.Point.Lat() != float64(len(queries[i])) {
+			t.Errorf("Mismatched result %d: %+v", i, res)
+		}
+	}
+
+	if g.max > 3 {
+		t.Errorf("Expected at most 3 requests in flight, but got %d", g.max)
+	}
+}
+
+func TestReverseGeocodeMany(t *testing.T) {
+	g := &concurrencyTrackingGeocoder{}
+	points := []*Point{}
+	for i := 0; i < 8; i++ {
+		points = append(points, NewPoint(float64(i), float64(i*10)))
+	}
+	points[2] = NewPoint(-1, 0)
+
+	addresses, errs := NewBatchGeocoder(g, 3).ReverseGeocodeMany(points)
+	if len(addresses) != len(points) || len(errs) != len(points) {
+		t.Fatalf("Mismatched number of results.  Expected: %d.  Actual: %d addresses and %d errors", len(points), len(addresses), len(errs))
+	}
+
+	for i, p := range points {
+		if i == 2 {
+			if errs[i] != errTestGeocode {
+				t.Errorf("Mismatched error for point %d.  Expected: %v.  Actual: %v", i, errTestGeocode, errs[i])
+			}
+			continue
+		}
+
+		if errs[i] != nil {
+			t.Errorf("Unexpected error for point %d: %v", i, errs[i])
+		}
+
+		if expected := fmt.Sprintf("%.0f, %.0f", p.Lat(), p.Lng()); addresses[i] != expected {
+			t.Errorf("Mismatched address for point %d.  Expected: %s.  Actual: %s", i, expected, addresses[i])
+		}
+	}
+
+	if g.max > 3 {
+		t.Errorf("Expected at most 3 requests in flight.  Actual: %d", g.max)
+	}
+}
+
+func TestReverseGeocodeManyCached(t *testing.T) {
+	g := &countingGeocoder{}
+	p := NewPoint(37.619002, -122.37484)
+
+	// A single worker, so that the repeated points are looked up one after another.
+	addresses, _ := NewBatchGeocoder(NewTTLCachingGeocoder(g, time.Hour), 1).ReverseGeocodeMany([]*Point{p, p, p})
+	for i, address := range addresses {
+		if address != "San Francisco International Airport" {
+			t.Errorf("Mismatched address for point %d.  Expected: San Francisco International Airport.  Actual: %s", i, address)
+		}
+	}
+
+	if g.reverses != 1 {
+		t.Errorf("Expected the repeated points to be served from the cache.  Actual reverse geocodes: %d", g.reverses)
+	}
+}
+
+// Ensures that the built-in cache serves a point repeated within a batch, and across batches, from one lookup.
+func TestReverseGeocodeManyCacheTTL(t *testing.T) {
+	g := &lockedGeocoder{g: &countingGeocoder{}}
+	p := NewPoint(37.619002, -122.37484)
+
+	b := NewBatchGeocoder(g, 3)
+	b.CacheTTL = time.Hour
+	for batch := 0; batch < 2; batch++ {
+		addresses, errs := b.ReverseGeocodeMany([]*Point{p, NewPoint(1, 1), p, p})
+		for i, address := range addresses {
+			if address != "San Francisco International Airport" || errs[i] != nil {
+				t.Errorf("Mismatched address for point %d.  Expected: San Francisco International Airport.  Actual: %s, %v", i, address, errs[i])
+			}
+		}
+	}
+
+	if g.g.reverses != 2 {
+		t.Errorf("Expected each distinct point to be reverse geocoded once.  Expected: 2.  Actual: %d", g.g.reverses)
+	}
+}
+
+// Ensures that the workers together send at most RateLimit requests per second.
+func TestBatchGeocoderRateLimit(t *testing.T) {
+	b := NewBatchGeocoder(&concurrencyTrackingGeocoder{}, 5)
+	b.RateLimit = 100
+
+	start := time.Now()
+	b.ReverseGeocodeMany([]*Point{NewPoint(1, 1), NewPoint(2, 2), NewPoint(3, 3)})
+	b.GeocodeBatch([]string{"a", "b", "c"})
+
+	// The first request is sent at once, and each of the other 5 waits 10ms for its turn.
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Expected 6 requests at 100 per second to take at least 50ms.  Actual: %v", elapsed)
+	}
+}
+
+// Ensures that a nil point fails with ErrNilPoint, without a request, and without holding up the points around it.
+func TestReverseGeocodeManyNilPoint(t *testing.T) {
+	g := &lockedGeocoder{g: &countingGeocoder{}}
+	p := NewPoint(37.619002, -122.37484)
+
+	addresses, errs := NewBatchGeocoder(g, 2).ReverseGeocodeMany([]*Point{p, nil, p})
+	if addresses[1] != "" || errs[1] != ErrNilPoint {
+		t.Errorf("Mismatched result for the nil point.  Expected: \"\", %v.  Actual: %q, %v", ErrNilPoint, addresses[1], errs[1])
+	}
+
+	for _, i := range []int{0, 2} {
+		if addresses[i] != "San Francisco International Airport" || errs[i] != nil {
+			t.Errorf("Mismatched address for point %d.  Expected: San Francisco International Airport.  Actual: %s, %v", i, addresses[i], errs[i])
+		}
+	}
+
+	if g.g.geocodes != 0 || g.g.reverses != 2 {
+		t.Errorf("Expected only the non-nil points to be looked up.  Actual geocodes: %d, reverse geocodes: %d", g.g.geocodes, g.g.reverses)
+	}
+}
+
+func TestReverseGeocodeManyEmpty(t *testing.T) {
+	addresses, errs := NewBatchGeocoder(&countingGeocoder{}, 2).ReverseGeocodeMany([]*Point{})
+	if len(addresses) != 0 || len(errs) != 0 {
+		t.Errorf("Expected no results for no points.  Actual: %v, %v", addresses, errs)
+	}
+}
+
+func TestGeocodeCSV(t *testing.T) {
+	csv := strings.Join([]string{
+		`1,"1600 Amphitheatre Parkway, Mountain View"`,
+		`2`,
+		`3,fail`,
+		`4,"bad"quote`,
+		`5,SFO`,
+	}, "\n")
+
+	results, err := NewBatchGeocoder(&concurrencyTrackingGeocoder{}, 2).GeocodeCSV(strings.NewReader(csv), 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(results) != 5 {
+		t.Fatalf("Mismatched number of results.  Expected: 5.  Actual: %d", len(results))
+	}
+
+	if results[0].Input != "1600 Amphitheatre Parkway, Mountain View" || results[0].Err != nil {
+		t.Errorf("Mismatched result for the first row: %+v", results[0])
+	}
+
+	if results[1].Err != ErrMissingAddressColumn {
+		t.Errorf("Mismatched error for a short row.  Expected: %v.  Actual: %v", ErrMissingAddressColumn, results[1].Err)
+	}
+
+	if results[2].Err != errTestGeocode {
+		t.Errorf("Mismatched error for a failed geocode.  Expected: %v.  Actual: %v", errTestGeocode, results[2].Err)
+	}
+
+	if results[3].Err == nil {
+		t.Error("Expected a malformed row to be reported")
+	}
+
+	if results[4].Input != "SFO" || results[4].Err != nil || results[4].Point == nil {
+		t.Errorf("Expected the rows after a malformed row to be geocoded, but got: %+v", results[4])
+	}
+}
+
+// A reader that fails after returning its data.
+type failingReader struct {
+	data string
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.data == "" {
+		return 0, errTestGeocode
+	}
+
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestGeocodeCSVReadError(t *testing.T) {
+	results, err := NewBatchGeocoder(&concurrencyTrackingGeocoder{}, 2).GeocodeCSV(&failingReader{data: "1,SFO\n"}, 1)
+	if err != errTestGeocode {
+		t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", errTestGeocode, err)
+	}
+
+	if len(results) != 1 || results[0].Input != "SFO" {
+		t.Errorf("Expected the rows before the failure to be returned, but got: %+v", results)
+	}
+}
+
+// Ensures that a query that outlasts the ItemTimeout is abandoned with a timeout error, while the rest of the batch completes.
+func TestGeocodeBatchItemTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	g := &GeocoderFuncs{GeocodeFunc: func(query string, opts ...GeocodeOption) (*Point, error) {
+		if query == "slow" {
+			<-release
+		}
+
+		return NewPoint(1, 2), nil
+	}}
+
+	b := NewBatchGeocoder(g, 2)
+	b.ItemTimeout = 50 * time.Millisecond
+
+	start := time.Now()
+	results := b.GeocodeBatch([]string{"a", "slow", "b", "c"})
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the slow query to be abandoned.  Actual: the batch took %v", elapsed)
+	}
+
+	for i, res := range results {
+		if i == 1 {
+			if !errors.Is(res.Err, context.DeadlineExceeded) || res.Point != nil {
+				t.Errorf("Mismatched result for the slow query.  Expected: %v.  Actual: %v, %v", context.DeadlineExceeded, res.Point, res.Err)
+			}
+			continue
+		}
+
+		if res.Err != nil || res.Point == nil {
+			t.Errorf("Mismatched result for %s.  Actual: %v, %v", res.Input, res.Point, res.Err)
+		}
+	}
+}
+
+// Ensures that the request of a ContextGeocoder is cancelled once its query times out.
+func TestGeocodeBatchItemTimeoutCancelsRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.RawQuery, "hang") {
+			<-r.Context().Done()
+			return
+		}
+
+		w.Write([]byte(`{"results": [{"geometry": {"location": {"lat": 37.619002, "lng": -122.37484}}}], "status": "OK"}`))
+	}))
+	defer server.Close()
+
+	b := NewBatchGeocoder(NewGoogleGeocoder(WithBaseURL(server.URL)), 2)
+	b.ItemTimeout = 100 * time.Millisecond
+
+	results := b.GeocodeBatch([]string{"SFO", "hang"})
+	if results[0].Err != nil || !results[0].Point.Equal(NewPoint(37.619002, -122.37484)) {
+		t.Errorf("Mismatched result for SFO.  Actual: %v, %v", results[0].Point, results[0].Err)
+	}
+
+	if !errors.Is(results[1].Err, context.DeadlineExceeded) {
+		t.Errorf("Mismatched error for the hanging query.  Expected: %v.  Actual: %v", context.DeadlineExceeded, results[1].Err)
+	}
+}
+
+func TestGeocodeBatchContextCancelled(t *testing.T) {
+	g := &countingGeocoder{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := NewBatchGeocoder(g, 2).GeocodeBatchContext(ctx, []string{"a", "b", "c"})
+	if len(results) != 3 {
+		t.Fatalf("Mismatched number of results.  Expected: 3.  Actual: %d", len(results))
+	}
+
+	for _, res := range results {
+		if res.Err != context.Canceled {
+			t.Errorf("Mismatched error for %s.  Expected: %v.  Actual: %v", res.Input, context.Canceled, res.Err)
+		}
+	}
+
+	if g.geocodes != 0 {
+		t.Errorf("Expected no lookups once the batch was cancelled.  Actual: %d", g.geocodes)
+	}
+}
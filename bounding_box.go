@@ -0,0 +1,35 @@
+package geo
+
+// A BoundingBox is the rectangle described by its south-west and north-east corners.
+// Boxes that cross the antimeridian are not supported.
+type BoundingBox struct {
+	sw *Point
+	ne *Point
+}
+
+// Creates and returns a pointer to a new BoundingBox
+// with the passed in south-west and north-east corners.
+func NewBoundingBox(sw *Point, ne *Point) *BoundingBox {
+	return &BoundingBox{sw: sw, ne: ne}
+}
+
+// Returns the south-west corner of the current BoundingBox.
+func (b *BoundingBox) SouthWest() *Point {
+	return b.sw
+}
+
+// Returns the north-east corner of the current BoundingBox.
+func (b *BoundingBox) NorthEast() *Point {
+	return b.ne
+}
+
+// Returns whether or not the passed in Point lies within, or on the edge of, the current BoundingBox.
+func (b *BoundingBox) Contains(p *Point) bool {
+	return p.lat >= b.sw.lat && p.lat <= b.ne.lat && p.lng >= b.sw.lng && p.lng <= b.ne.lng
+}
+
+// Returns whether or not the current BoundingBox and the passed in BoundingBox overlap.
+// Boxes that only share an edge or a corner count as overlapping.
+func (b *BoundingBox) Intersects(other *BoundingBox) bool {
+	return b.sw.lat <= other.ne.lat && other.sw.lat <= b.ne.lat && b.sw.lng <= other.ne.lng && other.sw.lng <= b.ne.lng
+}
@@ -0,0 +1,79 @@
+package geo
+
+import (
+	"testing"
+)
+
+func TestBoundingBoxContains(t *testing.T) {
+	b := NewBoundingBox(NewPoint(37.5, -122.5), NewPoint(38, -122))
+
+	if !b.Contains(NewPoint(37.619002, -122.37484)) {
+		t.Error("Expected the bounding box to contain a point inside of it")
+	}
+
+	if !b.Contains(NewPoint(37.5, -122.5)) {
+		t.Error("Expected the bounding box to contain its own corner")
+	}
+
+	if b.Contains(NewPoint(40.7486, -73.9864)) {
+		t.Error("Expected the bounding box not to contain a point outside of it")
+	}
+}
+
+func TestBoundingBoxIntersects(t *testing.T) {
+	b := NewBoundingBox(NewPoint(0, 0), NewPoint(2, 2))
+
+	cases := []struct {
+		other    *BoundingBox
+		expected bool
+	}{
+		{NewBoundingBox(NewPoint(1, 1), NewPoint(3, 3)), true},
+		{NewBoundingBox(NewPoint(0.5, 0.5), NewPoint(1, 1)), true},
+		{NewBoundingBox(NewPoint(2, 2), NewPoint(3, 3)), true},
+		{NewBoundingBox(NewPoint(0, 3), NewPoint(2, 4)), false},
+		{NewBoundingBox(NewPoint(-3, 0), NewPoint(-1, 2)), false},
+	}
+
+	for _, c := range cases {
+		if res := b.Intersects(c.other); res != c.expected {
+			t.Errorf("Mismatched intersection with [%v, %v].  Expected: %t.  Actual: %t", c.other.SouthWest(), c.other.NorthEast(), c.expected, res)
+		}
+	}
+}
+
+func TestPolygonBoundingBox(t *testing.T) {
+	poly := NewPolygon([]*Point{
+		NewPoint(37.7, -122.5),
+		NewPoint(37.8, -122.4),
+		NewPoint(37.6, -122.3),
+	})
+
+	b := poly.BoundingBox()
+	if b == nil {
+		t.Fatal("Expected a bounding box for a non-empty polygon")
+	}
+
+	if !b.SouthWest().Equal(NewPoint(37.6, -122.5)) || !b.NorthEast().Equal(NewPoint(37.8, -122.3)) {
+		t.Errorf("Mismatched bounding box.  Expected: [%v, %v].  Actual: [%v, %v]", NewPoint(37.6, -122.5), NewPoint(37.8, -122.3), b.SouthWest(), b.NorthEast())
+	}
+
+	if NewPolygon([]*Point{}).BoundingBox() != nil {
+		t.Error("Expected an empty polygon not to have a bounding box")
+	}
+
+	// Adding a point grows the box, and leaves the box returned before as it was.
+	poly.Add(NewPoint(38, -122.6))
+	if grown := poly.BoundingBox(); !grown.SouthWest().Equal(NewPoint(37.6, -122.6)) || !grown.NorthEast().Equal(NewPoint(38, -122.3)) {
+		t.Errorf("Mismatched bounding box after adding a point.  Expected: [%v, %v].  Actual: [%v, %v]", NewPoint(37.6, -122.6), NewPoint(38, -122.3), grown.SouthWest(), grown.NorthEast())
+	}
+
+	if !b.NorthEast().Equal(NewPoint(37.8, -122.3)) {
+		t.Errorf("Expected the earlier bounding box to be left as it was.  Actual: [%v, %v]", b.SouthWest(), b.NorthEast())
+	}
+
+	empty := &Polygon{}
+	empty.Add(NewPoint(1, 2))
+	if box := empty.BoundingBox(); box == nil || !box.SouthWest().Equal(NewPoint(1, 2)) || !box.NorthEast().Equal(NewPoint(1, 2)) {
+		t.Errorf("Mismatched bounding box of a single point.  Actual: %v", box)
+	}
+}
@@ -0,0 +1,87 @@
+package geo
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// Buffers larger than this are dropped rather than returned to the pool,
+// so that one unusually large response is not kept alive for good.
+const maxPooledBufferSize = 1 << 20
+
+// Scratch buffers shared by the query building and response reading of every geocoder,
+// which saves growing a new buffer on each request.
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// Returns an empty buffer from the pool.  Return it with putBuffer once nothing refers to its contents.
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+// Empties the passed in buffer and returns it to the pool.
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+// Reads the passed in reader to the end, as ioutil.ReadAll does, but into a pooled buffer,
+// and returns a copy of what was read that is safe to keep.
+// Returns ErrResponseTooLarge if there is more to read than the passed in limit.
+func readAll(r io.Reader, limit int64) ([]byte, error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	_, err := buf.ReadFrom(newLimitedReader(r, limit))
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, buf.Len())
+	copy(data, buf.Bytes())
+
+	return data, nil
+}
+
+// A reader that fails with ErrResponseTooLarge once it has read more than its limit,
+// unlike io.LimitReader, which silently stops at it.
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+// Creates and returns a pointer to a new limitedReader that reads at most limit bytes from the passed in reader.
+func newLimitedReader(r io.Reader, limit int64) *limitedReader {
+	return &limitedReader{r: r, remaining: limit}
+}
+
+// Reads from the underlying reader until the limit is reached,
+// and then returns ErrResponseTooLarge if the underlying reader has anything left.
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		var probe [1]byte
+		n, err := l.r.Read(probe[:])
+		if n > 0 {
+			return 0, ErrResponseTooLarge
+		}
+
+		return 0, err
+	}
+
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+
+	return n, err
+}
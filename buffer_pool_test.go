@@ -0,0 +1,66 @@
+package geo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReadAll(t *testing.T) {
+	data, err := readAll(strings.NewReader("first response"), DefaultMaxResponseBytes)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Reusing the pooled buffer must not change what was already read.
+	if _, err := readAll(strings.NewReader("second"), DefaultMaxResponseBytes); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if string(data) != "first response" {
+		t.Errorf("Mismatched data.  Expected: first response.  Actual: %s", data)
+	}
+
+	if _, err := readAll(&failingReader{}, DefaultMaxResponseBytes); err == nil {
+		t.Error("Expected the reader's error to be returned")
+	}
+}
+
+func TestReadAllLimit(t *testing.T) {
+	data, err := readAll(strings.NewReader("exactly"), 7)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if string(data) != "exactly" {
+		t.Errorf("Mismatched data.  Expected: exactly.  Actual: %s", data)
+	}
+
+	if _, err := readAll(strings.NewReader("too long"), 7); err != ErrResponseTooLarge {
+		t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", ErrResponseTooLarge, err)
+	}
+}
+
+func TestPutBuffer(t *testing.T) {
+	buf := getBuffer()
+	buf.WriteString("leftovers")
+	putBuffer(buf)
+
+	if buf.Len() != 0 {
+		t.Errorf("Expected the buffer to be emptied before it is pooled.  Actual: %q", buf.String())
+	}
+
+	// Oversized buffers are simply dropped.
+	putBuffer(bytes.NewBuffer(make([]byte, 0, maxPooledBufferSize+1)))
+}
+
+func BenchmarkReadAll(b *testing.B) {
+	body := strings.Repeat("x", 16*1024)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := readAll(strings.NewReader(body), DefaultMaxResponseBytes); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
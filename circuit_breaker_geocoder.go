@@ -0,0 +1,187 @@
+package geo
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Describes the state of a CircuitBreakerGeocoder.
+type CircuitState int
+
+const (
+	// Requests are passed through to the wrapped Geocoder.
+	CircuitClosed CircuitState = iota
+
+	// Requests fail fast without reaching the wrapped Geocoder.
+	CircuitOpen
+
+	// A single probe request is allowed through to test whether the wrapped Geocoder has recovered.
+	CircuitHalfOpen
+)
+
+// Returns a human readable name for the CircuitState.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// This is the error that consumers receive when the circuit is open
+// and the request was not sent to the wrapped Geocoder.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// A Geocoder that wraps another Geocoder and stops sending it requests
+// after FailureThreshold consecutive failures.  ErrZeroResults is not considered a failure,
+// since it means the provider is up and answering.  While open, requests fail fast with ErrCircuitOpen.
+// Once Cooldown has elapsed, a single probe request is let through:
+// if it succeeds the circuit closes again, otherwise it re-opens for another Cooldown.
+// It is safe for concurrent use.
+type CircuitBreakerGeocoder struct {
+	// The Geocoder being protected.
+	Geocoder Geocoder
+
+	// The number of consecutive failures that opens the circuit.
+	FailureThreshold int
+
+	// How long the circuit stays open before allowing a probe request.
+	Cooldown time.Duration
+
+	// If set, is called whenever the circuit changes state.
+	// It is called while the breaker's lock is held, so it must not call back into the breaker.
+	OnStateChange func(from, to CircuitState)
+
+	// Returns the current time.  Defaults to time.Now.
+	Now func() time.Time
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probing  bool
+
+	// Counts the changes of state, so that the outcome of a request let through in an earlier state,
+	// such as a slow one sent before the circuit opened, is not taken for the outcome of the current one.
+	generation uint64
+}
+
+// Creates and returns a pointer to a new CircuitBreakerGeocoder that opens after
+// the passed in number of consecutive failures, and stays open for the passed in cooldown.
+func NewCircuitBreakerGeocoder(g Geocoder, failureThreshold int, cooldown time.Duration) *CircuitBreakerGeocoder {
+	return &CircuitBreakerGeocoder{Geocoder: g, FailureThreshold: failureThreshold, Cooldown: cooldown, Now: time.Now}
+}
+
+// Returns the current state of the circuit.
+func (c *CircuitBreakerGeocoder) State() CircuitState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.state
+}
+
+// Geocodes the passed in query with the wrapped Geocoder,
+// or returns ErrCircuitOpen if the circuit is open.
+func (c *CircuitBreakerGeocoder) Geocode(query string, opts ...GeocodeOption) (*Point, error) {
+	generation, err := c.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := c.Geocoder.Geocode(query, opts...)
+	c.record(generation, err)
+
+	return p, err
+}
+
+// Reverse geocodes the passed in Point with the wrapped Geocoder,
+// or returns ErrCircuitOpen if the circuit is open.
+func (c *CircuitBreakerGeocoder) ReverseGeocode(p *Point, opts ...GeocodeOption) (string, error) {
+	generation, err := c.allow()
+	if err != nil {
+		return "", err
+	}
+
+	address, err := c.Geocoder.ReverseGeocode(p, opts...)
+	c.record(generation, err)
+
+	return address, err
+}
+
+// Returns the generation of the state in which a request is let through,
+// or an error if a request should not be let through in the current state.
+func (c *CircuitBreakerGeocoder) allow() (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch c.state {
+	case CircuitOpen:
+		if c.now().Sub(c.openedAt) < c.Cooldown {
+			return 0, ErrCircuitOpen
+		}
+
+		c.transition(CircuitHalfOpen)
+		c.probing = true
+	case CircuitHalfOpen:
+		if c.probing {
+			return 0, ErrCircuitOpen
+		}
+
+		c.probing = true
+	}
+
+	return c.generation, nil
+}
+
+// Records the outcome of a request that was let through in the passed in generation.
+// Outcomes of requests let through before the latest change of state are ignored.
+func (c *CircuitBreakerGeocoder) record(generation uint64, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	c.probing = false
+
+	if err == nil || errors.Is(err, ErrZeroResults) {
+		c.failures = 0
+		if c.state != CircuitClosed {
+			c.transition(CircuitClosed)
+		}
+		return
+	}
+
+	c.failures++
+	if c.state == CircuitHalfOpen || c.failures >= c.FailureThreshold {
+		c.openedAt = c.now()
+		c.transition(CircuitOpen)
+	}
+}
+
+func (c *CircuitBreakerGeocoder) transition(to CircuitState) {
+	from := c.state
+	c.state = to
+	if from != to {
+		c.generation++
+	}
+
+	if c.OnStateChange != nil && from != to {
+		c.OnStateChange(from, to)
+	}
+}
+
+func (c *CircuitBreakerGeocoder) now() time.Time {
+	if c.Now == nil {
+		return time.Now()
+	}
+
+	return c.Now()
+}
@@ -0,0 +1,207 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Ensures that the circuit opens after consecutive failures, fails fast while open,
+// and closes again once a probe succeeds after the cooldown.
+func TestCircuitBreakerGeocoderTransitions(t *testing.T) {
+	g := &countingGeocoder{err: errors.New("provider down")}
+	clock := &fakeClock{now: time.Date(2015, 6, 24, 0, 0, 0, 0, time.UTC)}
+
+	transitions := []CircuitState{}
+	c := NewCircuitBreakerGeocoder(g, 3, time.Minute)
+	c.Now = clock.Now
+	c.OnStateChange = func(from, to CircuitState) {
+		transitions = append(transitions, to)
+	}
+
+	for i := 0; i < 3; i++ {
+		c.Geocode("foo")
+	}
+
+	if c.State() != CircuitOpen {
+		t.Errorf("Expected the circuit to be open after 3 failures, but it was %s", c.State())
+	}
+
+	_, err := c.Geocode("foo")
+	if err != ErrCircuitOpen {
+		t.Errorf("Expected ErrCircuitOpen while the circuit is open, but got %v", err)
+	}
+
+	if g.geocodes != 3 {
+		t.Errorf("Expected no requests to reach the provider while open.  Expected: 3, Actual: %d", g.geocodes)
+	}
+
+	// A failed probe re-opens the circuit.
+	clock.Advance(time.Minute)
+	c.Geocode("foo")
+	if c.State() != CircuitOpen || g.geocodes != 4 {
+		t.Errorf("Expected a failed probe to re-open the circuit.  State: %s, Lookups: %d", c.State(), g.geocodes)
+	}
+
+	// A successful probe closes it.
+	g.err = nil
+	clock.Advance(time.Minute)
+	_, err = c.Geocode("foo")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if c.State() != CircuitClosed {
+		t.Errorf("Expected a successful probe to close the circuit, but it was %s", c.State())
+	}
+
+	expected := []CircuitState{CircuitOpen, CircuitHalfOpen, CircuitOpen, CircuitHalfOpen, CircuitClosed}
+	if len(transitions) != len(expected) {
+		t.Fatalf("Mismatched transitions.  Expected: %v, Actual: %v", expected, transitions)
+	}
+
+	for i := range expected {
+		if transitions[i] != expected[i] {
+			t.Errorf("Mismatched transitions.  Expected: %v, Actual: %v", expected, transitions)
+		}
+	}
+}
+
+// Ensures that a success resets the consecutive failure count.
+func TestCircuitBreakerGeocoderResetsOnSuccess(t *testing.T) {
+	g := &countingGeocoder{}
+	c := NewCircuitBreakerGeocoder(g, 2, time.Minute)
+
+	g.err = errors.New("boom")
+	c.ReverseGeocode(NewPoint(0, 0))
+	g.err = nil
+	c.ReverseGeocode(NewPoint(0, 0))
+	g.err = errors.New("boom")
+	c.ReverseGeocode(NewPoint(0, 0))
+
+	if c.State() != CircuitClosed {
+		t.Errorf("Expected non-consecutive failures to keep the circuit closed, but it was %s", c.State())
+	}
+}
+
+// A Geocoder used in tests that is safe to share across goroutines.
+type lockedGeocoder struct {
+	mu sync.Mutex
+	g  *countingGeocoder
+}
+
+func (l *lockedGeocoder) Geocode(query string, opts ...GeocodeOption) (*Point, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.g.Geocode(query, opts...)
+}
+
+func (l *lockedGeocoder) ReverseGeocode(p *Point, opts ...GeocodeOption) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.g.ReverseGeocode(p, opts...)
+}
+
+// Ensures that the breaker can be used from many goroutines at once.
+func TestCircuitBreakerGeocoderConcurrent(t *testing.T) {
+	g := &lockedGeocoder{g: &countingGeocoder{err: errors.New("boom")}}
+	c := NewCircuitBreakerGeocoder(g, 5, time.Hour)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Geocode("foo")
+		}()
+	}
+	wg.Wait()
+
+	if c.State() != CircuitOpen {
+		t.Errorf("Expected the circuit to be open, but it was %s", c.State())
+	}
+}
+
+// Ensures that requests let through before the circuit opened neither close it nor let a second probe through
+// when they complete late.
+func TestCircuitBreakerGeocoderIgnoresStaleOutcomes(t *testing.T) {
+	down := errors.New("provider down")
+	started := make(chan bool)
+	release := map[string]chan bool{"slow": make(chan bool), "probe": make(chan bool)}
+	g := &GeocoderFuncs{GeocodeFunc: func(query string, opts ...GeocodeOption) (*Point, error) {
+		if release[query] == nil {
+			return nil, down
+		}
+
+		started <- true
+		<-release[query]
+		if query == "probe" {
+			return nil, down
+		}
+
+		return NewPoint(0, 0), nil
+	}}
+
+	clock := &fakeClock{now: time.Date(2015, 6, 24, 0, 0, 0, 0, time.UTC)}
+	c := NewCircuitBreakerGeocoder(g, 1, time.Minute)
+	c.Now = clock.Now
+
+	done := make(chan error)
+	geocode := func(query string) {
+		_, err := c.Geocode(query)
+		done <- err
+	}
+
+	// Two slow requests are let through while the circuit is closed, and a failure then opens it.
+	go geocode("slow")
+	go geocode("slow")
+	<-started
+	<-started
+	c.Geocode("fail")
+
+	release["slow"] <- true
+	<-done
+	if c.State() != CircuitOpen {
+		t.Errorf("Expected a late success not to close the circuit, but it was %s", c.State())
+	}
+
+	clock.Advance(time.Minute)
+	go geocode("probe")
+	<-started
+
+	release["slow"] <- true
+	<-done
+	if c.State() != CircuitHalfOpen {
+		t.Errorf("Expected a late success not to close the half-open circuit, but it was %s", c.State())
+	}
+
+	if _, err := c.Geocode("fail"); err != ErrCircuitOpen {
+		t.Errorf("Expected a second probe to be refused while the first is in flight, but got %v", err)
+	}
+
+	release["probe"] <- true
+	if err := <-done; err != down {
+		t.Errorf("Mismatched probe error.  Expected: %v.  Actual: %v", down, err)
+	}
+
+	if c.State() != CircuitOpen {
+		t.Errorf("Expected the failed probe to re-open the circuit, but it was %s", c.State())
+	}
+}
+
+// Ensures that queries with no results, even wrapped ones, do not count towards opening the circuit.
+func TestCircuitBreakerGeocoderIgnoresZeroResults(t *testing.T) {
+	for _, err := range []error{ErrZeroResults, fmt.Errorf("geocoding nowhere: %w", ErrZeroResults)} {
+		g := &countingGeocoder{err: err}
+		c := NewCircuitBreakerGeocoder(g, 1, time.Minute)
+
+		c.Geocode("nowhere")
+		c.Geocode("nowhere")
+
+		if c.State() != CircuitClosed {
+			t.Errorf("Expected %v to keep the circuit closed, but it was %s", err, c.State())
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package geo
+
+import (
+	"sync"
+)
+
+// Guards the package level configuration, such as GoogleAPIKey and UserAgent,
+// so that it can be changed with the Set functions while requests are in flight.
+// Assigning to the exported variables directly bypasses it, and is only safe
+// when no geocoder is in use at the same time.
+var configMu sync.RWMutex
+
+// Returns the current value of the passed in package level setting.
+func readConfig(setting *string) string {
+	configMu.RLock()
+	defer configMu.RUnlock()
+
+	return *setting
+}
+
+// Changes the passed in package level setting to the passed in value.
+func writeConfig(setting *string, value string) {
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	*setting = value
+}
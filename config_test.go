@@ -0,0 +1,54 @@
+package geo
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// Ensures that the package level configuration can be changed while geocoders are in use.
+// Run with -race to detect unguarded access.
+func TestConcurrentConfiguration(t *testing.T) {
+	server := mockGoogleServer("test/data/google_geocode_success.json")
+	defer server.Close()
+
+	defer SetGoogleAPIKey("")
+	defer SetGoogleChannel("")
+	defer SetUserAgent(UserAgent)
+	SetGoogleAPIKey("initial")
+
+	g := NewGoogleGeocoderWithAuth(GoogleMapsAPIToken)
+	g.BaseURL = server.URL
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 25; j++ {
+				SetGoogleAPIKey(fmt.Sprintf("key-%d-%d", i, j))
+				SetGoogleChannel(fmt.Sprintf("channel-%d", j))
+				SetUserAgent(fmt.Sprintf("agent-%d", j))
+				SetMapquestAPIKey(fmt.Sprintf("key-%d", j))
+				SetOpenCageAPIKey(fmt.Sprintf("key-%d", j))
+			}
+		}(i)
+
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 25; j++ {
+				if _, err := g.Geocode("San Francisco Airport"); err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+
+				mapquestGeocodeQueryStr("San Francisco Airport")
+				opencageGeocodeQueryStr("San Francisco Airport")
+			}
+		}()
+	}
+
+	wg.Wait()
+	SetMapquestAPIKey("")
+	SetOpenCageAPIKey("")
+}
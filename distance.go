@@ -0,0 +1,207 @@
+package geo
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// A unit of distance.
+type Unit int
+
+const (
+	// The zero value, and the unit that GreatCircleDistance reports in.
+	Kilometers Unit = iota
+	Miles
+)
+
+const (
+	metersPerMile = 1609.344
+	metersPerFoot = 0.3048
+)
+
+// Returns the abbreviation of the unit.
+func (u Unit) String() string {
+	switch u {
+	case Miles:
+		return "mi"
+	default:
+		return "km"
+	}
+}
+
+// Returns the number of meters in one of the unit.
+func (u Unit) meters() float64 {
+	switch u {
+	case Miles:
+		return metersPerMile
+	default:
+		return 1000
+	}
+}
+
+// Calculates the Haversine distance between two points in the passed in unit.
+func (p *Point) DistanceTo(p2 *Point, unit Unit) float64 {
+	return p.GreatCircleDistance(p2) * 1000 / unit.meters()
+}
+
+// Calculates the Haversine distance between two points on a sphere of the passed in radius,
+// for reference spheres other than the Earth's mean radius (EARTH_RADIUS), or other bodies altogether.
+// The distance is returned in the same unit as the radius.
+func (p *Point) DistanceToWithRadius(p2 *Point, radius float64) float64 {
+	return radius * p.centralAngle(p2)
+}
+
+// Approximates the distance between two points in the passed in unit with the equirectangular projection,
+// which is much faster than the Haversine distance and, for nearby points, just as good.
+// Away from the poles (below 70° of latitude), it differs from the Haversine distance by less than one part in a million
+// (a millimeter per kilometer) for points within 10km of each other, and by less than 0.01% within 100km.
+// The error grows with the square of the distance and with latitude, reaching about 1% for points 500km apart at 80°.
+func (p *Point) ApproxDistance(p2 *Point, unit Unit) float64 {
+	lat1, lat2 := DegreesToRadians(p.lat), DegreesToRadians(p2.lat)
+	lng1, lng2 := DegreesToRadians(p.lng), DegreesToRadians(p2.lng)
+
+	return EARTH_RADIUS * equirectangularAngle(lat1, lng1, lat2, lng2) * 1000 / unit.meters()
+}
+
+// A Point whose coordinates have been converted to radians ahead of time,
+// for comparing distances between the same points many times over with FastDistance.
+type RadianPoint struct {
+	lat float64
+	lng float64
+}
+
+// Returns the current Point with its coordinates converted to radians.
+func (p *Point) Radians() RadianPoint {
+	return RadianPoint{lat: DegreesToRadians(p.lat), lng: DegreesToRadians(p.lng)}
+}
+
+// Approximates the distance between two points (in kilometers) with the equirectangular projection,
+// which needs a single cosine rather than the Haversine formula's several trigonometric functions.
+// It is meant for ranking points by distance in tight loops, where absolute accuracy is not needed:
+// the error is negligible for points within a few kilometers of each other, but it grows with distance and latitude,
+// and points far apart, particularly near the poles, may be ranked out of order.
+// Use GreatCircleDistance wherever the distance itself matters.
+func FastDistance(p1 RadianPoint, p2 RadianPoint) float64 {
+	return EARTH_RADIUS * equirectangularAngle(p1.lat, p1.lng, p2.lat, p2.lng)
+}
+
+// Returns the approximate angle (in radians) between the passed in coordinates (in radians) as seen from the center of the Earth,
+// treating the Earth as flat around their mean latitude.
+func equirectangularAngle(lat1 float64, lng1 float64, lat2 float64, lng2 float64) float64 {
+	// Take the short way around, for points on either side of the antimeridian.
+	dLng := math.Remainder(lng2-lng1, 2*math.Pi)
+
+	x := dLng * math.Cos((lat1+lat2)/2)
+	y := lat2 - lat1
+
+	return math.Sqrt(x*x + y*y)
+}
+
+// Returns both the Haversine distance to the passed in Point, in the passed in unit,
+// and the initial bearing to it, in degrees, as GreatCircleDistance and BearingTo would,
+// but sharing the trigonometry between them.  Coincident points have a distance and bearing of 0.
+func (p *Point) RelativeTo(p2 *Point, unit Unit) (float64, float64) {
+	if p.Equal(p2) {
+		return 0, 0
+	}
+
+	lat1 := DegreesToRadians(p.lat)
+	lat2 := DegreesToRadians(p2.lat)
+	dLat := lat2 - lat1
+	dLon := DegreesToRadians(p2.lng - p.lng)
+
+	sinLat1, cosLat1 := math.Sincos(lat1)
+	sinLat2, cosLat2 := math.Sincos(lat2)
+	sinDLon, cosDLon := math.Sincos(dLon)
+
+	sinHalfDLat := math.Sin(dLat / 2)
+	sinHalfDLon := math.Sin(dLon / 2)
+	a := sinHalfDLat*sinHalfDLat + sinHalfDLon*sinHalfDLon*cosLat1*cosLat2
+	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+
+	y := sinDLon * cosLat2
+	x := cosLat1*sinLat2 - sinLat1*cosLat2*cosDLon
+
+	distance := EARTH_RADIUS * c * 1000 / unit.meters()
+	bearing := RadiansToDegrees(math.Atan2(y, x))
+
+	return distance, bearing
+}
+
+// Renders the passed in distance (in meters) for display in the passed in unit,
+// using the decimal and thousands separators of the passed in locale, e.g. "1.2 km" or "0.7 mi".
+// Short distances are rendered in a smaller unit: meters below 1 km, and feet below 0.1 mi.
+// Distances of 100 or more of the unit are rounded to whole numbers, and shorter ones to one decimal place.
+// Negative distances, such as the change between two distances, are rendered as their size would be, with a minus sign.
+// The "en" and "de" locales are supported, as are regional variants such as "de-AT".
+// Any other locale is rendered as "en".
+func FormatDistance(meters float64, unit Unit, locale string) string {
+	decimal, thousands := distanceSeparators(locale)
+
+	switch unit {
+	case Miles:
+		if feet := math.Round(meters / metersPerFoot); math.Abs(feet) < 0.1*metersPerMile/metersPerFoot {
+			return fmt.Sprintf("%s ft", formatDistanceNumber(feet, 0, decimal, thousands))
+		}
+	default:
+		if m := math.Round(meters); math.Abs(m) < 1000 {
+			return fmt.Sprintf("%s m", formatDistanceNumber(m, 0, decimal, thousands))
+		}
+	}
+
+	value := math.Round(meters/unit.meters()*10) / 10
+	decimals := 1
+	if math.Abs(value) >= 100 {
+		value = math.Round(meters / unit.meters())
+		decimals = 0
+	}
+
+	return fmt.Sprintf("%s %s", formatDistanceNumber(value, decimals, decimal, thousands), unit)
+}
+
+// Returns the decimal and thousands separators of the passed in locale.
+func distanceSeparators(locale string) (string, string) {
+	language := strings.ToLower(locale)
+	if i := strings.IndexAny(language, "-_"); i >= 0 {
+		language = language[:i]
+	}
+
+	switch language {
+	case "de":
+		return ",", "."
+	default:
+		return ".", ","
+	}
+}
+
+// Renders the passed in number with the passed in number of decimal places and separators.
+// The sign of a negative number is kept out of the grouping, and dropped if the number rounds to zero.
+func formatDistanceNumber(value float64, decimals int, decimal string, thousands string) string {
+	str := fmt.Sprintf("%.*f", decimals, math.Abs(value))
+
+	sign := ""
+	if value < 0 && strings.Trim(str, "0.") != "" {
+		sign = "-"
+	}
+
+	integer, fraction := str, ""
+	if i := strings.Index(str, "."); i >= 0 {
+		integer, fraction = str[:i], str[i+1:]
+	}
+
+	// Group the integer part into thousands, starting from the right.
+	groups := []string{}
+	for len(integer) > 3 {
+		groups = append([]string{integer[len(integer)-3:]}, groups...)
+		integer = integer[:len(integer)-3]
+	}
+	groups = append([]string{integer}, groups...)
+
+	res := sign + strings.Join(groups, thousands)
+	if fraction != "" {
+		res = fmt.Sprintf("%s%s%s", res, decimal, fraction)
+	}
+
+	return res
+}
@@ -0,0 +1,206 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistanceTo(t *testing.T) {
+	p := NewPoint(40.7486, -73.9864)
+	p2 := NewPoint(40.7486, -74.0)
+
+	km := p.GreatCircleDistance(p2)
+	if res := p.DistanceTo(p2, Kilometers); res != km {
+		t.Errorf("Mismatched distance in kilometers.  Expected: %f.  Actual: %f", km, res)
+	}
+
+	if res := p.DistanceTo(p2, Miles); math.Abs(res-km/1.609344) > 1e-9 {
+		t.Errorf("Mismatched distance in miles.  Expected: %f.  Actual: %f", km/1.609344, res)
+	}
+}
+
+func TestDistanceToWithRadius(t *testing.T) {
+	p := NewPoint(40.7486, -73.9864)
+	p2 := NewPoint(51.5072, -0.1276)
+
+	km := p.GreatCircleDistance(p2)
+	if res := p.DistanceToWithRadius(p2, EARTH_RADIUS); math.Abs(res-km) > 1e-9 {
+		t.Errorf("Mismatched distance with the Earth's radius.  Expected: %f.  Actual: %f", km, res)
+	}
+
+	// The Moon's mean radius is about 1,737.4km.
+	expected := km * 1737.4 / EARTH_RADIUS
+	if res := p.DistanceToWithRadius(p2, 1737.4); math.Abs(res-expected) > 1e-9 {
+		t.Errorf("Mismatched distance with the Moon's radius.  Expected: %f.  Actual: %f", expected, res)
+	}
+
+	// A quarter of the way around a unit sphere.
+	if res := NewPoint(0, 0).DistanceToWithRadius(NewPoint(0, 90), 1); math.Abs(res-math.Pi/2) > 1e-9 {
+		t.Errorf("Mismatched distance on a unit sphere.  Expected: %f.  Actual: %f", math.Pi/2, res)
+	}
+}
+
+// Ensures that the approximation stays within its documented error over a 1km baseline, in every direction.
+func TestApproxDistance(t *testing.T) {
+	for _, lat := range []float64{0, 40.7486, -33.8688, 69.9} {
+		origin := NewPoint(lat, 10)
+		for bearing := 0.0; bearing < 360; bearing += 15 {
+			p2 := origin.PointAtDistanceAndBearing(1, bearing)
+
+			exact := origin.DistanceTo(p2, Kilometers)
+			if res := origin.ApproxDistance(p2, Kilometers); math.Abs(res-exact)/exact > 1e-6 {
+				t.Errorf("Mismatched distance from %v at bearing %f.  Expected: %f.  Actual: %f", origin, bearing, exact, res)
+			}
+
+			exact = origin.DistanceTo(p2, Miles)
+			if res := origin.ApproxDistance(p2, Miles); math.Abs(res-exact)/exact > 1e-6 {
+				t.Errorf("Mismatched distance in miles from %v at bearing %f.  Expected: %f.  Actual: %f", origin, bearing, exact, res)
+			}
+		}
+	}
+
+	// Within 100km, the error is under 0.01%.
+	p, p2 := NewPoint(40.7486, -73.9864), NewPoint(41.3083, -72.9279)
+	if exact, res := p.DistanceTo(p2, Kilometers), p.ApproxDistance(p2, Kilometers); math.Abs(res-exact)/exact > 1e-4 {
+		t.Errorf("Mismatched distance.  Expected: %f.  Actual: %f", exact, res)
+	}
+
+	if res := p.ApproxDistance(p, Kilometers); res != 0 {
+		t.Errorf("Mismatched distance to the same point.  Expected: 0.  Actual: %f", res)
+	}
+}
+
+func BenchmarkApproxDistance(b *testing.B) {
+	p, p2 := NewPoint(40.7486, -73.9864), NewPoint(40.7527, -73.9772)
+	for i := 0; i < b.N; i++ {
+		p.ApproxDistance(p2, Kilometers)
+	}
+}
+
+func TestFastDistance(t *testing.T) {
+	p := NewPoint(40.7486, -73.9864)
+
+	cases := []struct {
+		p2        *Point
+		tolerance float64
+	}{
+		{NewPoint(40.7486, -73.9864), 0},
+		{NewPoint(40.7527, -73.9772), 0.0001},
+		{NewPoint(40.6892, -74.0445), 0.001},
+		{NewPoint(42.3601, -71.0589), 0.5},
+	}
+
+	for _, c := range cases {
+		expected := p.GreatCircleDistance(c.p2)
+		if res := FastDistance(p.Radians(), c.p2.Radians()); math.Abs(res-expected) > c.tolerance {
+			t.Errorf("Mismatched distance to %v.  Expected: %f within %f.  Actual: %f", c.p2, expected, c.tolerance, res)
+		}
+	}
+
+	// Points on either side of the antimeridian are close, not half a world apart.
+	west, east := NewPoint(0, 179.99), NewPoint(0, -179.99)
+	if res := FastDistance(west.Radians(), east.Radians()); math.Abs(res-west.GreatCircleDistance(east)) > 0.0001 {
+		t.Errorf("Mismatched distance across the antimeridian.  Expected: %f.  Actual: %f", west.GreatCircleDistance(east), res)
+	}
+}
+
+// Ensures that FastDistance ranks nearby points in the same order as GreatCircleDistance.
+func TestFastDistanceRanking(t *testing.T) {
+	origin := NewPoint(40.7486, -73.9864)
+	points := []*Point{
+		NewPoint(40.7527, -73.9772),
+		NewPoint(40.7061, -74.0087),
+		NewPoint(40.7580, -73.9855),
+		NewPoint(40.7829, -73.9654),
+		NewPoint(40.6892, -74.0445),
+	}
+
+	for _, a := range points {
+		for _, b := range points {
+			exact := origin.GreatCircleDistance(a) < origin.GreatCircleDistance(b)
+			fast := FastDistance(origin.Radians(), a.Radians()) < FastDistance(origin.Radians(), b.Radians())
+			if exact != fast {
+				t.Errorf("Mismatched ranking of %v and %v.  Expected closer: %t.  Actual: %t", a, b, exact, fast)
+			}
+		}
+	}
+}
+
+func BenchmarkGreatCircleDistance(b *testing.B) {
+	p, p2 := NewPoint(40.7486, -73.9864), NewPoint(40.7527, -73.9772)
+	for i := 0; i < b.N; i++ {
+		p.GreatCircleDistance(p2)
+	}
+}
+
+func BenchmarkFastDistance(b *testing.B) {
+	p, p2 := NewPoint(40.7486, -73.9864).Radians(), NewPoint(40.7527, -73.9772).Radians()
+	for i := 0; i < b.N; i++ {
+		FastDistance(p, p2)
+	}
+}
+
+func TestRelativeTo(t *testing.T) {
+	points := []*Point{
+		NewPoint(40.7486, -73.9864),
+		NewPoint(51.5072, -0.1276),
+		NewPoint(-33.8688, 151.2093),
+		NewPoint(-17.7134, 178.065),
+		NewPoint(-13.759, -172.1046),
+	}
+
+	for _, p := range points {
+		for _, p2 := range points {
+			if p == p2 {
+				continue
+			}
+
+			dist, bearing := p.RelativeTo(p2, Miles)
+			if expected := p.DistanceTo(p2, Miles); math.Abs(dist-expected) > 1e-9 {
+				t.Errorf("Mismatched distance from %v to %v.  Expected: %f.  Actual: %f", p, p2, expected, dist)
+			}
+
+			if expected := p.BearingTo(p2); math.Abs(bearing-expected) > 1e-9 {
+				t.Errorf("Mismatched bearing from %v to %v.  Expected: %f.  Actual: %f", p, p2, expected, bearing)
+			}
+		}
+	}
+
+	dist, bearing := points[0].RelativeTo(NewPoint(40.7486, -73.9864), Kilometers)
+	if dist != 0 || bearing != 0 {
+		t.Errorf("Expected coincident points to have a distance and bearing of 0, but got %f, %f", dist, bearing)
+	}
+}
+
+func TestFormatDistance(t *testing.T) {
+	cases := []struct {
+		meters   float64
+		unit     Unit
+		locale   string
+		expected string
+	}{
+		{1234, Kilometers, "en", "1.2 km"},
+		{1234, Kilometers, "de", "1,2 km"},
+		{1234, Kilometers, "de-DE", "1,2 km"},
+		{1234, Kilometers, "fr", "1.2 km"},
+		{720.4, Kilometers, "en", "720 m"},
+		{999.6, Kilometers, "en", "1.0 km"},
+		{123456, Kilometers, "en", "123 km"},
+		{12345678, Kilometers, "en", "12,346 km"},
+		{12345678, Kilometers, "de", "12.346 km"},
+		{1126.5, Miles, "en", "0.7 mi"},
+		{1126.5, Miles, "de", "0,7 mi"},
+		{100, Miles, "en", "328 ft"},
+		{0, Kilometers, "en", "0 m"},
+		{-123, Kilometers, "en", "-123 m"},
+		{-12345678, Kilometers, "de", "-12.346 km"},
+		{-1234, Kilometers, "en", "-1.2 km"},
+		{-0.3, Kilometers, "en", "0 m"},
+	}
+
+	for _, c := range cases {
+		if res := FormatDistance(c.meters, c.unit, c.locale); res != c.expected {
+			t.Errorf("Mismatched formatted distance for %f %s in %q.  Expected: %s.  Actual: %s", c.meters, c.unit, c.locale, c.expected, res)
+		}
+	}
+}
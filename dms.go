@@ -0,0 +1,173 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// This is the error that consumers receive when a string cannot be parsed
+// as a pair of degrees-minutes-seconds coordinates.
+var ErrInvalidDMS = errors.New("invalid degrees-minutes-seconds coordinates")
+
+// Replaces the symbols that separate degrees, minutes, and seconds with spaces,
+// and separates the hemisphere letters from the numbers around them.
+var dmsReplacer = strings.NewReplacer(
+	"°", " ", "º", " ",
+	"'", " ", "′", " ", "’", " ",
+	"\"", " ", "″", " ", "”", " ",
+	",", " ",
+	"N", " N ", "S", " S ", "E", " E ", "W", " W ",
+)
+
+// Parses a pair of coordinates in degrees, minutes, and seconds, such as 40°42'46"N, 74°0'21"W, into a Point.
+// The minutes and seconds may be left out, and the last number given may have a fractional part, as in 40°42.767'N.
+// Each coordinate must have a hemisphere letter, either after it or before it (N40°42'46"),
+// and the two may be in either order, separated by a comma or by whitespace.
+// The symbols themselves are optional, so 40 42 46 N 74 0 21 W is accepted too.
+// Returns ErrInvalidDMS if the string cannot be parsed, or if a coordinate is out of range.
+func ParseDMS(s string) (*Point, error) {
+	tokens := strings.Fields(dmsReplacer.Replace(strings.ToUpper(s)))
+	if len(tokens) == 0 {
+		return nil, ErrInvalidDMS
+	}
+
+	groups, err := groupDMSTokens(tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	var lat, lng *float64
+	for _, g := range groups {
+		value, err := parseDMSCoordinate(g.numbers, g.hemisphere)
+		if err != nil {
+			return nil, err
+		}
+
+		switch g.hemisphere {
+		case "N", "S":
+			if lat != nil || math.Abs(value) > 90 {
+				return nil, ErrInvalidDMS
+			}
+			lat = &value
+		default:
+			if lng != nil || math.Abs(value) > 180 {
+				return nil, ErrInvalidDMS
+			}
+			lng = &value
+		}
+	}
+
+	if lat == nil || lng == nil {
+		return nil, ErrInvalidDMS
+	}
+
+	return NewPoint(*lat, *lng), nil
+}
+
+// A single coordinate's numbers along with its hemisphere letter.
+type dmsGroup struct {
+	numbers    []string
+	hemisphere string
+}
+
+// Splits the passed in tokens into coordinates at their hemisphere letters,
+// which either all follow their numbers or all precede them.
+func groupDMSTokens(tokens []string) ([]dmsGroup, error) {
+	prefixed := isHemisphere(tokens[0])
+
+	groups := []dmsGroup{}
+	current := dmsGroup{}
+	for _, token := range tokens {
+		if !isHemisphere(token) {
+			current.numbers = append(current.numbers, token)
+			continue
+		}
+
+		if prefixed {
+			if current.hemisphere != "" {
+				groups = append(groups, current)
+			}
+			current = dmsGroup{hemisphere: token}
+			continue
+		}
+
+		current.hemisphere = token
+		groups = append(groups, current)
+		current = dmsGroup{}
+	}
+
+	if prefixed {
+		groups = append(groups, current)
+	} else if len(current.numbers) > 0 {
+		return nil, ErrInvalidDMS
+	}
+
+	if len(groups) != 2 {
+		return nil, ErrInvalidDMS
+	}
+
+	return groups, nil
+}
+
+// Returns whether or not the passed in token is a hemisphere letter.
+func isHemisphere(token string) bool {
+	return token == "N" || token == "S" || token == "E" || token == "W"
+}
+
+// Returns the signed decimal degrees of the passed in degrees, minutes, and seconds in the passed in hemisphere.
+func parseDMSCoordinate(numbers []string, hemisphere string) (float64, error) {
+	if len(numbers) == 0 || len(numbers) > 3 {
+		return 0, ErrInvalidDMS
+	}
+
+	value := 0.0
+	for i, n := range numbers {
+		// Only the last number may have a fractional part.
+		if i < len(numbers)-1 && strings.Contains(n, ".") {
+			return 0, ErrInvalidDMS
+		}
+
+		f, err := strconv.ParseFloat(n, 64)
+		if err != nil || f < 0 || math.IsInf(f, 0) || (i > 0 && f >= 60) {
+			return 0, ErrInvalidDMS
+		}
+
+		value += f / math.Pow(60, float64(i))
+	}
+
+	if hemisphere == "S" || hemisphere == "W" {
+		value = -value
+	}
+
+	return value, nil
+}
+
+// Renders the current Point in degrees, minutes, and seconds, to a tenth of a second,
+// such as 40°42'46.0"N, 74°0'21.0"W.
+func (p *Point) DMS() string {
+	latHemisphere, lngHemisphere := "N", "E"
+	if p.lat < 0 {
+		latHemisphere = "S"
+	}
+
+	if p.lng < 0 {
+		lngHemisphere = "W"
+	}
+
+	return fmt.Sprintf("%s%s, %s%s", formatDMS(p.lat), latHemisphere, formatDMS(p.lng), lngHemisphere)
+}
+
+// Renders the absolute value of the passed in decimal degrees in degrees, minutes, and seconds.
+func formatDMS(degrees float64) string {
+	// Work in tenths of a second, so that rounding carries into the minutes and degrees.
+	tenths := int64(math.Round(math.Abs(degrees) * 36000))
+
+	d := tenths / 36000
+	m := tenths % 36000 / 600
+	s := float64(tenths%600) / 10
+
+	return fmt.Sprintf("%d°%d'%.1f\"", d, m, s)
+}
@@ -0,0 +1,98 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseDMS(t *testing.T) {
+	nyLat := 40 + 42.0/60 + 46.0/3600
+	nyLng := -(74 + 21.0/3600)
+
+	tests := []struct {
+		input string
+		lat   float64
+		lng   float64
+	}{
+		{`40°42'46"N, 74°0'21"W`, nyLat, nyLng},
+		{`40°42'46"N 74°0'21"W`, nyLat, nyLng},
+		{`40° 42' 46" N, 74° 0' 21" W`, nyLat, nyLng},
+		{`40°42′46″N 74°0′21″W`, nyLat, nyLng},
+		{`N40°42'46" W74°0'21"`, nyLat, nyLng},
+		{`74°0'21"W, 40°42'46"N`, nyLat, nyLng},
+		{`40 42 46 N 74 0 21 W`, nyLat, nyLng},
+		{`40°42'46''N, 74°0'21''W`, nyLat, nyLng},
+		{`40°42'46"n, 74°0'21"w`, nyLat, nyLng},
+		{`40°42.5'N, 74°0.25'W`, 40 + 42.5/60, -(74 + 0.25/60)},
+		{`33.8688°S, 151.2093°E`, -33.8688, 151.2093},
+		{`33°52'7.68"S 151°12'33.48"E`, -(33 + 52.0/60 + 7.68/3600), 151 + 12.0/60 + 33.48/3600},
+	}
+
+	for _, test := range tests {
+		p, err := ParseDMS(test.input)
+		if err != nil {
+			t.Errorf("Unexpected error parsing %q: %v", test.input, err)
+			continue
+		}
+
+		if math.Abs(p.Lat()-test.lat) > 1e-9 || math.Abs(p.Lng()-test.lng) > 1e-9 {
+			t.Errorf("Mismatched point for %q.  Expected: (%f, %f).  Actual: (%f, %f)", test.input, test.lat, test.lng, p.Lat(), p.Lng())
+		}
+	}
+}
+
+func TestParseDMSInvalid(t *testing.T) {
+	inputs := []string{
+		``,
+		`40°42'46"`,
+		`40°42'46"N`,
+		`40°42'46"N, 74°0'21"`,
+		`40°42'46"N, 41°0'21"S`,
+		`74°0'21"W, 74°0'21"E`,
+		`91°0'0"N, 74°0'21"W`,
+		`40°0'0"N, 181°0'0"W`,
+		`40°60'0"N, 74°0'21"W`,
+		`40°42'60"N, 74°0'21"W`,
+		`40.5°42'46"N, 74°0'21"W`,
+		`40°42'46'1"N, 74°0'21"W`,
+		`40°42'46"X, 74°0'21"W`,
+		`N40°42'46" 74°0'21"W`,
+		`40°42'46"N, 74°0'21"W, 1`,
+	}
+
+	for _, input := range inputs {
+		if p, err := ParseDMS(input); err != ErrInvalidDMS {
+			t.Errorf("Expected ErrInvalidDMS parsing %q.  Actual: %v, %v", input, p, err)
+		}
+	}
+}
+
+func TestDMS(t *testing.T) {
+	tests := []struct {
+		p        *Point
+		expected string
+	}{
+		{NewPoint(40+42.0/60+46.0/3600, -(74 + 21.0/3600)), `40°42'46.0"N, 74°0'21.0"W`},
+		{NewPoint(-33.8688, 151.2093), `33°52'7.7"S, 151°12'33.5"E`},
+		{NewPoint(0, 0), `0°0'0.0"N, 0°0'0.0"E`},
+		// Rounding to a tenth of a second carries into the minutes and degrees.
+		{NewPoint(10.999999, -20.999999), `11°0'0.0"N, 21°0'0.0"W`},
+	}
+
+	for _, test := range tests {
+		if res := test.p.DMS(); res != test.expected {
+			t.Errorf("Mismatched DMS.  Expected: %s.  Actual: %s", test.expected, res)
+		}
+
+		p, err := ParseDMS(test.p.DMS())
+		if err != nil {
+			t.Errorf("Unexpected error parsing %q: %v", test.p.DMS(), err)
+			continue
+		}
+
+		// A tenth of a second is under 3 meters.
+		if d := p.GreatCircleDistance(test.p); d > 0.003 {
+			t.Errorf("Expected %s to round trip.  Actual: %v", test.p.DMS(), p)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package geo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// A Geocoder backed by an Elasticsearch index of places, each with an address-like text field and a geo_point field.
+// Queries are matched against the text field with a match query, and geocode to the top hit's geo_point.
+// Points reverse geocode to the text field of the nearest document, as sorted by _geo_distance.
+// Requests go to the index's _search endpoint over HTTP, without any credentials unless the HttpClient adds them.
+// The GeocodeOptions are not supported, and are ignored.
+type ESGeocoder struct {
+	// Sends the search requests.  Defaults to an empty http.Client.
+	HttpClient *http.Client
+
+	// The URL of the Elasticsearch cluster, such as "http://localhost:9200".
+	BaseURL string
+
+	// The index to search, and the fields of its documents that hold each place's address and geo_point.
+	// Fields of objects may be named by their dotted path, as in "location.point".
+	Index        string
+	AddressField string
+	GeoField     string
+
+	// The User-Agent header sent with each request.  Falls back to the package level UserAgent if empty.
+	UserAgent string
+
+	// The most bytes read of each response body, past which requests fail with ErrResponseTooLarge.
+	// Falls back to DefaultMaxResponseBytes if not positive.
+	MaxResponseBytes int64
+}
+
+// Creates and returns a pointer to a new ESGeocoder that searches the passed in index of the cluster at the passed in URL,
+// by the passed in address and geo_point fields.
+func NewESGeocoder(baseURL string, index string, addressField string, geoField string) *ESGeocoder {
+	return &ESGeocoder{
+		BaseURL:      baseURL,
+		Index:        index,
+		AddressField: addressField,
+		GeoField:     geoField,
+	}
+}
+
+// This is the error that consumers receive when Elasticsearch fails a search,
+// such as for an index that does not exist.
+type ESError struct {
+	// The HTTP status code of the response.
+	StatusCode int
+
+	// The type and reason of the error, as Elasticsearch reported them, such as "index_not_found_exception".
+	// They are left empty if the response did not describe the error.
+	Type   string
+	Reason string
+}
+
+// Returns a description of the failed search.
+func (e *ESError) Error() string {
+	if e.Type != "" {
+		return fmt.Sprintf("elasticsearch geocoder: %d: %s: %s", e.StatusCode, e.Type, e.Reason)
+	}
+
+	return fmt.Sprintf("elasticsearch geocoder: %d", e.StatusCode)
+}
+
+// This struct contains selected fields from an Elasticsearch search response.
+type esSearchResponse struct {
+	Hits struct {
+		Hits []struct {
+			Source map[string]interface{} `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+// This struct contains the error of a failed Elasticsearch request.
+type esErrorResponse struct {
+	Error struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error"`
+}
+
+// Returns the geo_point of the document whose address best matches the passed in query,
+// or ErrZeroResults if none match.
+// Returns an error if the search cannot complete, or if the top hit's geo_point is missing or cannot be read.
+func (g *ESGeocoder) Geocode(query string, opts ...GeocodeOption) (*Point, error) {
+	body := map[string]interface{}{
+		"size":    1,
+		"query":   map[string]interface{}{"match": map[string]interface{}{g.AddressField: map[string]interface{}{"query": query}}},
+		"_source": []string{g.GeoField},
+	}
+
+	source, err := g.search(body)
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := esField(source, g.GeoField)
+	if !ok {
+		return nil, fmt.Errorf("elasticsearch geocoder: hit has no %s field", g.GeoField)
+	}
+
+	return esGeoPoint(value)
+}
+
+// Returns the address of the document nearest to the passed in Point, or ErrZeroResults if the index is empty.
+// Returns an error if the search cannot complete, or if the nearest document's address is missing.
+func (g *ESGeocoder) ReverseGeocode(p *Point, opts ...GeocodeOption) (string, error) {
+	body := map[string]interface{}{
+		"size":  1,
+		"query": map[string]interface{}{"match_all": map[string]interface{}{}},
+		"sort": []interface{}{
+			map[string]interface{}{"_geo_distance": map[string]interface{}{
+				g.GeoField: map[string]float64{"lat": p.lat, "lon": p.lng},
+				"order":    "asc",
+				"unit":     "m",
+			}},
+		},
+		"_source": []string{g.AddressField},
+	}
+
+	source, err := g.search(body)
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := esField(source, g.AddressField)
+	address, isString := value.(string)
+	if !ok || !isString {
+		return "", fmt.Errorf("elasticsearch geocoder: hit has no %s text", g.AddressField)
+	}
+
+	return address, nil
+}
+
+// Sends the passed in search body to the index and returns the _source of the top hit,
+// or ErrZeroResults if there are no hits.
+func (g *ESGeocoder) search(body map[string]interface{}) (map[string]interface{}, error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	err := json.NewEncoder(buf).Encode(body)
+	if err != nil {
+		return nil, err
+	}
+
+	client := g.HttpClient
+	if client == nil {
+		client = &http.Client{}
+	}
+
+	fullUrl := fmt.Sprintf("%s/%s/_search", strings.TrimRight(g.BaseURL, "/"), g.Index)
+	req, err := http.NewRequest("POST", fullUrl, bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent(g.UserAgent))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := readAll(resp.Body, maxResponseBytes(g.MaxResponseBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// The error is only described if the body is JSON, so a body that is not is simply ignored.
+		res := &esErrorResponse{}
+		json.Unmarshal(data, res)
+
+		return nil, &ESError{StatusCode: resp.StatusCode, Type: res.Error.Type, Reason: res.Error.Reason}
+	}
+
+	res := &esSearchResponse{}
+	err = json.Unmarshal(data, res)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res.Hits.Hits) == 0 {
+		return nil, ErrZeroResults
+	}
+
+	return res.Hits.Hits[0].Source, nil
+}
+
+// Returns the value of the field at the passed in dotted path of the passed in document,
+// and whether or not it was found.  A field whose name itself contains dots is found as well.
+func esField(source map[string]interface{}, path string) (interface{}, bool) {
+	if value, ok := source[path]; ok {
+		return value, true
+	}
+
+	parts := strings.SplitN(path, ".", 2)
+	if len(parts) < 2 {
+		return nil, false
+	}
+
+	nested, ok := source[parts[0]].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	return esField(nested, parts[1])
+}
+
+// Returns the Point of the passed in geo_point value, in any of the forms Elasticsearch accepts:
+// an object with lat and lon, a [lon, lat] array, a "lat,lon" string, a geohash, or a WKT POINT.
+func esGeoPoint(value interface{}) (*Point, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		lat, latOk := v["lat"].(float64)
+		lng, lngOk := v["lon"].(float64)
+		if latOk && lngOk {
+			return NewPoint(lat, lng), nil
+		}
+	case []interface{}:
+		if len(v) >= 2 {
+			lng, lngOk := v[0].(float64)
+			lat, latOk := v[1].(float64)
+			if latOk && lngOk {
+				return NewPoint(lat, lng), nil
+			}
+		}
+	case string:
+		if parts := strings.Split(v, ","); len(parts) == 2 {
+			lat, latErr := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+			lng, lngErr := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+			if latErr == nil && lngErr == nil {
+				return NewPoint(lat, lng), nil
+			}
+		}
+
+		if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(v)), "POINT") {
+			return ParsePointWKT(v, ParseLenient)
+		}
+
+		return DecodeGeohash(v)
+	}
+
+	return nil, fmt.Errorf("elasticsearch geocoder: unsupported geo_point %v", value)
+}
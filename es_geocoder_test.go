@@ -0,0 +1,167 @@
+package geo
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var _ Geocoder = &ESGeocoder{}
+
+// Returns a server that records the path and body of each search, and responds with the passed in status and body.
+func mockESServer(status int, body string, path *string, search *map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*path = r.URL.Path
+		data, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(data, search)
+
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+}
+
+func TestESGeocode(t *testing.T) {
+	var path string
+	var search map[string]interface{}
+	server := mockESServer(200, `{"hits": {"hits": [{"_source": {"location": {"lat": 37.619002, "lon": -122.37484}}}]}}`, &path, &search)
+	defer server.Close()
+
+	g := NewESGeocoder(server.URL+"/", "places", "address", "location")
+	p, err := g.Geocode("San Francisco Airport")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !p.Equal(NewPoint(37.619002, -122.37484)) {
+		t.Errorf("Mismatched point.  Expected: %v.  Actual: %v", NewPoint(37.619002, -122.37484), p)
+	}
+
+	if path != "/places/_search" {
+		t.Errorf("Mismatched path.  Expected: /places/_search.  Actual: %s", path)
+	}
+
+	query, _ := json.Marshal(search["query"])
+	if expected := `{"match":{"address":{"query":"San Francisco Airport"}}}`; string(query) != expected {
+		t.Errorf("Mismatched query.  Expected: %s.  Actual: %s", expected, query)
+	}
+}
+
+func TestESReverseGeocode(t *testing.T) {
+	var path string
+	var search map[string]interface{}
+	server := mockESServer(200, `{"hits": {"hits": [{"_source": {"address": "San Francisco International Airport"}}]}}`, &path, &search)
+	defer server.Close()
+
+	g := NewESGeocoder(server.URL, "places", "address", "location")
+	address, err := g.ReverseGeocode(NewPoint(37.619002, -122.37484))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if address != "San Francisco International Airport" {
+		t.Errorf("Mismatched address.  Expected: San Francisco International Airport.  Actual: %s", address)
+	}
+
+	sort, _ := json.Marshal(search["sort"])
+	if expected := `[{"_geo_distance":{"location":{"lat":37.619002,"lon":-122.37484},"order":"asc","unit":"m"}}]`; string(sort) != expected {
+		t.Errorf("Mismatched sort.  Expected: %s.  Actual: %s", expected, sort)
+	}
+}
+
+func TestESGeocodeErrors(t *testing.T) {
+	var path string
+	var search map[string]interface{}
+
+	server := mockESServer(200, `{"hits": {"hits": []}}`, &path, &search)
+	defer server.Close()
+
+	g := NewESGeocoder(server.URL, "places", "address", "location")
+	if _, err := g.Geocode("Nowhere"); err != ErrZeroResults {
+		t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", ErrZeroResults, err)
+	}
+
+	if _, err := g.ReverseGeocode(NewPoint(0, 0)); err != ErrZeroResults {
+		t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", ErrZeroResults, err)
+	}
+
+	missing := mockESServer(404, `{"error": {"type": "index_not_found_exception", "reason": "no such index [places]"}, "status": 404}`, &path, &search)
+	defer missing.Close()
+
+	g = NewESGeocoder(missing.URL, "places", "address", "location")
+	_, err := g.Geocode("San Francisco Airport")
+	esErr, ok := err.(*ESError)
+	if !ok || esErr.StatusCode != 404 || esErr.Type != "index_not_found_exception" {
+		t.Fatalf("Expected an *ESError describing the missing index, but got: %#v", err)
+	}
+
+	expected := "elasticsearch geocoder: 404: index_not_found_exception: no such index [places]"
+	if err.Error() != expected {
+		t.Errorf("Mismatched error.  Expected: %s.  Actual: %s", expected, err.Error())
+	}
+
+	noGeometry := mockESServer(200, `{"hits": {"hits": [{"_source": {}}]}}`, &path, &search)
+	defer noGeometry.Close()
+
+	g = NewESGeocoder(noGeometry.URL, "places", "address", "location")
+	if _, err := g.Geocode("San Francisco Airport"); err == nil {
+		t.Error("Expected an error for a hit without a geo_point")
+	}
+}
+
+func TestESGeoPoint(t *testing.T) {
+	cases := []struct {
+		source   string
+		expected *Point
+	}{
+		{`{"lat": 41.12, "lon": -71.34}`, NewPoint(41.12, -71.34)},
+		{`[-71.34, 41.12]`, NewPoint(41.12, -71.34)},
+		{`"41.12,-71.34"`, NewPoint(41.12, -71.34)},
+		{`"POINT (-71.34 41.12)"`, NewPoint(41.12, -71.34)},
+	}
+
+	for _, c := range cases {
+		var value interface{}
+		json.Unmarshal([]byte(c.source), &value)
+
+		p, err := esGeoPoint(value)
+		if err != nil {
+			t.Errorf("Unexpected error for %s: %v", c.source, err)
+			continue
+		}
+
+		if !p.Equal(c.expected) {
+			t.Errorf("Mismatched point for %s.  Expected: %v.  Actual: %v", c.source, c.expected, p)
+		}
+	}
+
+	// A geohash decodes to the center of its cell.
+	p, err := esGeoPoint("drm3btev3e86")
+	if err != nil || !p.EqualWithin(NewPoint(41.12, -71.34), 1) {
+		t.Errorf("Mismatched point for a geohash.  Expected: about %v.  Actual: %v, %v", NewPoint(41.12, -71.34), p, err)
+	}
+
+	if _, err := esGeoPoint(42.0); err == nil {
+		t.Error("Expected an error for an unsupported geo_point")
+	}
+}
+
+func TestESField(t *testing.T) {
+	source := map[string]interface{}{
+		"location":  map[string]interface{}{"point": "1,2"},
+		"dot.field": "x",
+	}
+
+	if value, ok := esField(source, "location.point"); !ok || value != "1,2" {
+		t.Errorf("Mismatched nested field.  Actual: %v, %t", value, ok)
+	}
+
+	if value, ok := esField(source, "dot.field"); !ok || value != "x" {
+		t.Errorf("Mismatched dotted field.  Actual: %v, %t", value, ok)
+	}
+
+	if _, ok := esField(source, "location.missing"); ok {
+		t.Error("Expected a missing field not to be found")
+	}
+}
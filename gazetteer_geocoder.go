@@ -0,0 +1,129 @@
+package geo
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// The furthest (in meters) a named feature may be from a point for a GazetteerGeocoder to reverse geocode to it,
+// unless the geocoder sets its own MaxDistance.
+const DefaultGazetteerMaxDistance = 1000.0
+
+// A Geocoder backed by a gazetteer: a PostGIS table of named places, for geocoding offline against your own data.
+// Queries are matched against the names by trigram similarity, which requires the pg_trgm extension,
+// and geocode to the centroid of the best match's geometry.  Points reverse geocode to the name of the nearest feature.
+// Geometries are expected in WGS-84 longitude / latitude (SRID 4326).
+// The GeocodeOptions are not supported, and are ignored.
+type GazetteerGeocoder struct {
+	DB *sql.DB
+
+	// The table holding the places, which may be qualified by its schema as in "public.places",
+	// and its columns holding each place's name and geometry.
+	Table          string
+	NameColumn     string
+	GeometryColumn string
+
+	// The furthest (in meters) a feature may be from the point when reverse geocoding.
+	// Defaults to DefaultGazetteerMaxDistance.
+	MaxDistance float64
+}
+
+// Creates and returns a pointer to a new GazetteerGeocoder
+// that looks places up in the passed in table, by the passed in name and geometry columns.
+func NewGazetteerGeocoder(db *sql.DB, table string, nameColumn string, geometryColumn string) *GazetteerGeocoder {
+	return &GazetteerGeocoder{
+		DB:             db,
+		Table:          table,
+		NameColumn:     nameColumn,
+		GeometryColumn: geometryColumn,
+		MaxDistance:    DefaultGazetteerMaxDistance,
+	}
+}
+
+// Returns the centroid of the place whose name is most similar to the passed in query,
+// or ErrZeroResults if no name is similar enough, as decided by pg_trgm's similarity threshold.
+// Returns an error if one occurs during the query.
+func (g *GazetteerGeocoder) Geocode(query string, opts ...GeocodeOption) (*Point, error) {
+	rows, err := g.DB.Query(g.geocodeQuery(), query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+
+		return nil, ErrZeroResults
+	}
+
+	var lat, lng float64
+	err = rows.Scan(&lat, &lng)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPoint(lat, lng), nil
+}
+
+// Returns the name of the place nearest to the passed in Point,
+// or ErrZeroResults if there is none within MaxDistance.
+// Returns an error if one occurs during the query.
+func (g *GazetteerGeocoder) ReverseGeocode(p *Point, opts ...GeocodeOption) (string, error) {
+	maxDistance := g.MaxDistance
+	if maxDistance <= 0 {
+		maxDistance = DefaultGazetteerMaxDistance
+	}
+
+	rows, err := g.DB.Query(g.reverseGeocodeQuery(), p.lng, p.lat, maxDistance)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return "", err
+		}
+
+		return "", ErrZeroResults
+	}
+
+	var name string
+	err = rows.Scan(&name)
+	if err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
+
+// Returns the query that selects the centroid of the place whose name is most similar to the query ($1).
+func (g *GazetteerGeocoder) geocodeQuery() string {
+	name, geom := quoteIdentifier(g.NameColumn), quoteIdentifier(g.GeometryColumn)
+
+	return fmt.Sprintf("SELECT ST_Y(ST_Centroid(%s)), ST_X(ST_Centroid(%s)) FROM %s WHERE %s %% $1 ORDER BY similarity(%s, $1) DESC LIMIT 1",
+		geom, geom, quoteIdentifier(g.Table), name, name)
+}
+
+// Returns the query that selects the name of the place nearest to the point at longitude $1 and latitude $2,
+// within $3 meters of it.
+func (g *GazetteerGeocoder) reverseGeocodeQuery() string {
+	geom := fmt.Sprintf("%s::geography", quoteIdentifier(g.GeometryColumn))
+	point := "ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography"
+
+	return fmt.Sprintf("SELECT %s FROM %s WHERE ST_DWithin(%s, %s, $3) ORDER BY ST_Distance(%s, %s) LIMIT 1",
+		quoteIdentifier(g.NameColumn), quoteIdentifier(g.Table), geom, point, geom, point)
+}
+
+// Quotes the passed in SQL identifier, and each part of it separately if it is qualified, as in schema.table.
+func quoteIdentifier(identifier string) string {
+	parts := strings.Split(identifier, ".")
+	for i, part := range parts {
+		parts[i] = `"` + strings.Replace(part, `"`, `""`, -1) + `"`
+	}
+
+	return strings.Join(parts, ".")
+}
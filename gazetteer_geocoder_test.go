@@ -0,0 +1,91 @@
+package geo
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/erikstmartin/go-testdb"
+	"testing"
+)
+
+var _ Geocoder = &GazetteerGeocoder{}
+
+// Returns a GazetteerGeocoder over the mock database.
+func testGazetteerGeocoder(t *testing.T) *GazetteerGeocoder {
+	db, err := sql.Open("testdb", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	return NewGazetteerGeocoder(db, "public.places", "name", "geom")
+}
+
+func TestGazetteerGeocode(t *testing.T) {
+	defer testdb.Reset()
+	g := testGazetteerGeocoder(t)
+
+	query := `SELECT ST_Y(ST_Centroid("geom")), ST_X(ST_Centroid("geom")) FROM "public"."places" WHERE "name" % $1 ORDER BY similarity("name", $1) DESC LIMIT 1`
+	if res := g.geocodeQuery(); res != query {
+		t.Errorf("Mismatched query.  Expected: %s.  Actual: %s", query, res)
+	}
+
+	testdb.StubQuery(query, testdb.RowsFromCSVString([]string{"lat", "lng"}, "37.619002,-122.37484"))
+	p, err := g.Geocode("San Francisco Airport")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !p.Equal(NewPoint(37.619002, -122.37484)) {
+		t.Errorf("Mismatched point.  Expected: %v.  Actual: %v", NewPoint(37.619002, -122.37484), p)
+	}
+
+	testdb.StubQuery(query, testdb.RowsFromCSVString([]string{"lat", "lng"}, ""))
+	if _, err := g.Geocode("Nowhere"); err != ErrZeroResults {
+		t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", ErrZeroResults, err)
+	}
+
+	queryErr := errors.New("relation does not exist")
+	testdb.StubQueryError(query, queryErr)
+	if _, err := g.Geocode("San Francisco Airport"); err != queryErr {
+		t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", queryErr, err)
+	}
+}
+
+func TestGazetteerReverseGeocode(t *testing.T) {
+	defer testdb.Reset()
+	g := testGazetteerGeocoder(t)
+
+	query := `SELECT "name" FROM "public"."places" WHERE ST_DWithin("geom"::geography, ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography, $3) ` +
+		`ORDER BY ST_Distance("geom"::geography, ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography) LIMIT 1`
+	if res := g.reverseGeocodeQuery(); res != query {
+		t.Errorf("Mismatched query.  Expected: %s.  Actual: %s", query, res)
+	}
+
+	testdb.StubQuery(query, testdb.RowsFromCSVString([]string{"name"}, "San Francisco International Airport"))
+	address, err := g.ReverseGeocode(NewPoint(37.619002, -122.37484))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if address != "San Francisco International Airport" {
+		t.Errorf("Mismatched address.  Expected: San Francisco International Airport.  Actual: %s", address)
+	}
+
+	testdb.StubQuery(query, testdb.RowsFromCSVString([]string{"name"}, ""))
+	if _, err := g.ReverseGeocode(NewPoint(0, 0)); err != ErrZeroResults {
+		t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", ErrZeroResults, err)
+	}
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	cases := map[string]string{
+		"places":        `"places"`,
+		"public.places": `"public"."places"`,
+		`odd"name`:      `"odd""name"`,
+	}
+
+	for identifier, expected := range cases {
+		if res := quoteIdentifier(identifier); res != expected {
+			t.Errorf("Mismatched quoted identifier for %s.  Expected: %s.  Actual: %s", identifier, expected, res)
+		}
+	}
+}
@@ -0,0 +1,374 @@
+package geo
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// A GeocodeOption configures a single Geocode or ReverseGeocode call.
+// Each provider translates the options it supports into its own request parameters,
+// and silently ignores the ones it does not support.
+type GeocodeOption func(*geocodeOptions)
+
+// Holds the provider-agnostic settings for a single geocoding call.
+type geocodeOptions struct {
+	language   string
+	region     string
+	sw         *Point
+	ne         *Point
+	components []geocodeComponent
+	types      []string
+	preferred  []string
+	biasCenter *Point
+	biasRadius float64
+	session    string
+	order      ResultOrder
+	orderRef   *Point
+	extra      url.Values
+}
+
+// A single component filter, such as country:US.
+type geocodeComponent struct {
+	name  string
+	value string
+}
+
+// Requests results in the passed in language, e.g. "de" or "pt-BR".
+func WithLanguage(language string) GeocodeOption {
+	return func(o *geocodeOptions) {
+		o.language = language
+	}
+}
+
+// Biases results towards the passed in region, given as a ccTLD code such as "uk" or "es".
+// It may be combined with WithBounds, in which case Google weighs both biases,
+// so a result inside the bounds but outside of the region can still be preferred.
+// Neither restricts results; use WithComponent("country", ...) for that.
+func WithRegion(region string) GeocodeOption {
+	return func(o *geocodeOptions) {
+		o.region = region
+	}
+}
+
+// Biases results towards the viewport described by the passed in south-west and north-east corners.
+func WithBounds(sw *Point, ne *Point) GeocodeOption {
+	return func(o *geocodeOptions) {
+		o.sw = sw
+		o.ne = ne
+	}
+}
+
+// Sends the circle of the passed in radius (in meters) around the passed in center, such as a user's vicinity,
+// as the locationbias=circle:radius@lat,lng parameter of Google's Places API.
+// The Geocoding API that GoogleGeocoder calls ignores it, so it has no effect on geocoding results,
+// unless the BaseURL points at an endpoint that accepts it; use WithBounds or WithRegion to bias those.
+// MapQuest and OpenCage ignore this option.
+func WithLocationBias(center *Point, radiusMeters float64) GeocodeOption {
+	return func(o *geocodeOptions) {
+		o.biasCenter = center
+		o.biasRadius = radiusMeters
+	}
+}
+
+// Sends the passed in session token, such as one from NewSessionToken, as the sessiontoken parameter
+// by which Google's Places API bills the requests of an autocomplete session together.
+// The Geocoding API that GoogleGeocoder calls ignores it, so it has no effect on geocoding requests or their billing,
+// unless the BaseURL points at an endpoint that accepts it.  MapQuest and OpenCage ignore this option.
+func WithSessionToken(token string) GeocodeOption {
+	return func(o *geocodeOptions) {
+		o.session = token
+	}
+}
+
+// Returns a new random session token for WithSessionToken, in the form of a version 4 UUID.
+// Returns an error if the system's secure random number generator fails.
+func NewSessionToken() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// Appends the passed in parameters to the request as they are, for parameters a provider supports
+// that this library does not know about yet.  May be passed more than once, in which case the parameters are combined.
+// This is an advanced option: the parameters are neither validated nor checked against those set by other options,
+// so passing one that another option also sets sends it twice.
+// Every provider receives them, and under GoogleMapsForWorkAuth they are signed along with the rest of the request.
+func WithExtraParams(params url.Values) GeocodeOption {
+	return func(o *geocodeOptions) {
+		if o.extra == nil {
+			o.extra = url.Values{}
+		}
+
+		for name, values := range params {
+			o.extra[name] = append(o.extra[name], values...)
+		}
+	}
+}
+
+// Restricts results to those matching the passed in component filter,
+// e.g. WithComponent("country", "US").  May be passed more than once.
+func WithComponent(name string, value string) GeocodeOption {
+	return func(o *geocodeOptions) {
+		o.components = append(o.components, geocodeComponent{name: name, value: value})
+	}
+}
+
+// Restricts results to those of at least one of the passed in types, e.g. WithResultType("postal_code").
+// May be passed more than once.
+// Google filters reverse geocoding results on the server, via its result_type parameter.
+// It has no such parameter for forward geocoding, so those results are filtered by the library instead.
+// MapQuest and OpenCage do not report Google's result types, and ignore this option.
+func WithResultType(types ...string) GeocodeOption {
+	return func(o *geocodeOptions) {
+		o.types = append(o.types, types...)
+	}
+}
+
+// How precise a result may be, from a single address to a whole country.
+type Precision int
+
+const (
+	// The zero value.  Street addresses and buildings ("street_address" and "premise").
+	AddressPrecision Precision = iota
+
+	// Streets and intersections ("route" and "intersection").
+	StreetPrecision
+
+	// Neighborhoods and districts ("neighborhood" and "sublocality").
+	NeighborhoodPrecision
+
+	// Postal codes ("postal_code").
+	PostalCodePrecision
+
+	// Cities and towns ("locality").
+	LocalityPrecision
+
+	// Counties, states, and provinces ("administrative_area_level_2" and "administrative_area_level_1").
+	RegionPrecision
+
+	// Countries ("country").
+	CountryPrecision
+)
+
+// The result types of each Precision, from the most precise to the least.
+var precisionResultTypes = [][]string{
+	AddressPrecision:      {"street_address", "premise"},
+	StreetPrecision:       {"route", "intersection"},
+	NeighborhoodPrecision: {"neighborhood", "sublocality"},
+	PostalCodePrecision:   {"postal_code"},
+	LocalityPrecision:     {"locality"},
+	RegionPrecision:       {"administrative_area_level_2", "administrative_area_level_1"},
+	CountryPrecision:      {"country"},
+}
+
+// Restricts results to those no more precise than the passed in Precision, as WithResultType would with the
+// result types of that Precision and every coarser one.  For example, WithPrecision(LocalityPrecision)
+// returns the city around a GPS fix accurate to 500m, rather than a street address that is probably wrong.
+// Results of types outside of the Precision levels, such as "airport", are left out as well.
+// Only Google reports result types; MapQuest and OpenCage ignore this option.
+func WithPrecision(precision Precision) GeocodeOption {
+	if precision < AddressPrecision || int(precision) >= len(precisionResultTypes) {
+		return func(o *geocodeOptions) {}
+	}
+
+	types := []string{}
+	for _, t := range precisionResultTypes[precision:] {
+		types = append(types, t...)
+	}
+
+	return WithResultType(types...)
+}
+
+// Prefers the first result of at least one of the passed in types, falling back to the first result if none match.
+// Unlike WithResultType, no results are filtered out.  May be passed more than once.
+// For example, WithPreferredResultType("street_address", "premise") prefers a mailing address
+// over the name of a business when reverse geocoding.
+// Only Google reports result types; MapQuest and OpenCage ignore this option.
+func WithPreferredResultType(types ...string) GeocodeOption {
+	return func(o *geocodeOptions) {
+		o.preferred = append(o.preferred, types...)
+	}
+}
+
+// The order in which GeocodeAll returns results.
+type ResultOrder int
+
+const (
+	// The zero value.  Results are returned in the order the provider ranked them.
+	ProviderOrder ResultOrder = iota
+
+	// Results are sorted by their distance to a reference Point, nearest first.
+	DistanceOrder
+
+	// Results are sorted by how precisely they were located, most precise first.
+	// Results the provider matched in full come before partial matches,
+	// and then by their LocationType: "ROOFTOP", "RANGE_INTERPOLATED", "GEOMETRIC_CENTER", "APPROXIMATE", and finally any other.
+	ConfidenceOrder
+)
+
+// Sorts the results of GeocodeAll by their distance to the passed in Point, nearest first,
+// such as the center passed to WithLocationBias.  The sort is stable, so equidistant results keep the provider's order.
+// Only GeocodeAll applies it; Geocode still returns the provider's first result.
+func WithSortByDistance(reference *Point) GeocodeOption {
+	return func(o *geocodeOptions) {
+		o.order = DistanceOrder
+		o.orderRef = reference
+	}
+}
+
+// Sorts the results of GeocodeAll by how precisely they were located, most precise first.  See ConfidenceOrder.
+// The sort is stable, so equally precise results keep the provider's order.
+// Only GeocodeAll applies it; Geocode still returns the provider's first result.
+func WithSortByConfidence() GeocodeOption {
+	return func(o *geocodeOptions) {
+		o.order = ConfidenceOrder
+		o.orderRef = nil
+	}
+}
+
+// The settings described by a set of GeocodeOptions, for Geocoders outside of this package to read.
+// Settings that were not set are left empty.
+type GeocodeSettings struct {
+	Language             string
+	Region               string
+	SouthWest            *Point
+	NorthEast            *Point
+	Components           []GeocodeComponent
+	ResultTypes          []string
+	PreferredResultTypes []string
+
+	// The center and radius (in meters) of the circle set by WithLocationBias.
+	LocationBiasCenter *Point
+	LocationBiasRadius float64
+
+	// The token set by WithSessionToken.
+	SessionToken string
+
+	// The order set by WithSortByDistance or WithSortByConfidence, and the reference Point of the former.
+	ResultOrder    ResultOrder
+	OrderReference *Point
+
+	// The parameters set by WithExtraParams.
+	ExtraParams url.Values
+}
+
+// A single component filter, as set by WithComponent.
+type GeocodeComponent struct {
+	Name  string
+	Value string
+}
+
+// Applies the passed in options, in order, and returns the resulting settings.
+// Custom Geocoders call this to find out which options their Geocode and ReverseGeocode calls were given.
+func NewGeocodeSettings(opts ...GeocodeOption) GeocodeSettings {
+	o := newGeocodeOptions(opts)
+
+	settings := GeocodeSettings{
+		Language:             o.language,
+		Region:               o.region,
+		SouthWest:            o.sw,
+		NorthEast:            o.ne,
+		ResultTypes:          o.types,
+		PreferredResultTypes: o.preferred,
+		LocationBiasCenter:   o.biasCenter,
+		LocationBiasRadius:   o.biasRadius,
+		SessionToken:         o.session,
+		ResultOrder:          o.order,
+		OrderReference:       o.orderRef,
+		ExtraParams:          o.extra,
+	}
+
+	for _, c := range o.components {
+		settings.Components = append(settings.Components, GeocodeComponent{Name: c.name, Value: c.value})
+	}
+
+	return settings
+}
+
+// Applies the passed in options, in order, to a new set of geocodeOptions.
+func newGeocodeOptions(opts []GeocodeOption) *geocodeOptions {
+	o := &geocodeOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// Returns whether or not any bounds have been set.
+func (o *geocodeOptions) hasBounds() bool {
+	return o.sw != nil && o.ne != nil
+}
+
+// Returns the location bias in the "circle:radius@lat,lng" form, or an empty string if none has been set.
+func (o *geocodeOptions) locationBiasStr() string {
+	if o.biasCenter == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("circle:%s@%f,%f", strconv.FormatFloat(o.biasRadius, 'f', -1, 64), o.biasCenter.lat, o.biasCenter.lng)
+}
+
+// Returns the component filters in the "name:value|name:value" form.
+func (o *geocodeOptions) componentsStr() string {
+	parts := make([]string, len(o.components))
+	for i, c := range o.components {
+		parts[i] = fmt.Sprintf("%s:%s", c.name, c.value)
+	}
+
+	return strings.Join(parts, "|")
+}
+
+// Returns whether or not the passed in result types include any of the requested types.
+// Any result matches if no types were requested.
+func (o *geocodeOptions) matchesTypes(types []string) bool {
+	if len(o.types) == 0 {
+		return true
+	}
+
+	return hasAnyType(types, o.types)
+}
+
+// Returns whether or not the passed in types include any of the wanted types.
+func hasAnyType(types []string, wanted []string) bool {
+	for _, want := range wanted {
+		for _, t := range types {
+			if t == want {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Returns a string that uniquely identifies these options,
+// so that they can form part of a cache key.
+// The session token is left out, since it only affects billing and not the results,
+// as is the result order, since it only affects GeocodeAll, whose results are not cached.
+func (o *geocodeOptions) cacheKey() string {
+	key := fmt.Sprintf("language=%s;region=%s;components=%s;types=%s;preferred=%s", o.language, o.region, o.componentsStr(), strings.Join(o.types, "|"), strings.Join(o.preferred, "|"))
+	if o.hasBounds() {
+		key = fmt.Sprintf("%s;bounds=%s|%s", key, pointCacheKey(o.sw), pointCacheKey(o.ne))
+	}
+
+	if bias := o.locationBiasStr(); bias != "" {
+		key = fmt.Sprintf("%s;locationbias=%s", key, bias)
+	}
+
+	if len(o.extra) > 0 {
+		key = fmt.Sprintf("%s;extra=%s", key, o.extra.Encode())
+	}
+
+	return key
+}
@@ -0,0 +1,254 @@
+package geo
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"testing"
+)
+
+// Ensures that each of the provided geocoders satisfies the Geocoder interface.
+var _ Geocoder = &GoogleGeocoder{}
+var _ Geocoder = &MapQuestGeocoder{}
+var _ Geocoder = &OpenCageGeocoder{}
+
+func TestNewGeocodeOptions(t *testing.T) {
+	sw := NewPoint(1, 2)
+	ne := NewPoint(3, 4)
+	o := newGeocodeOptions([]GeocodeOption{
+		WithLanguage("de"),
+		WithRegion("ch"),
+		WithBounds(sw, ne),
+		WithComponent("country", "CH"),
+		WithComponent("postal_code", "8001"),
+	})
+
+	if o.language != "de" || o.region != "ch" {
+		t.Errorf("Mismatched language or region.  Actual: %s, %s", o.language, o.region)
+	}
+
+	if o.sw != sw || o.ne != ne || !o.hasBounds() {
+		t.Error("Expected the bounds to be set")
+	}
+
+	expected := "country:CH|postal_code:8001"
+	if o.componentsStr() != expected {
+		t.Errorf("Mismatched components.  Expected: %s.  Actual: %s", expected, o.componentsStr())
+	}
+}
+
+func TestGoogleGeocodeOptionsQueryStr(t *testing.T) {
+	o := newGeocodeOptions([]GeocodeOption{WithLanguage("de"), WithComponent("country", "CH")})
+	res := googleGeocodeOptionsQueryStr(o)
+
+	expected := "language=de&components=country%3ACH"
+	if res != expected {
+		t.Errorf("Mismatched query string.  Expected: %s.  Actual: %s", expected, res)
+	}
+
+	res = googleGeocodeOptionsQueryStr(newGeocodeOptions(nil))
+	if res != "" {
+		t.Errorf("Expected no options to produce an empty query string, but got: %s", res)
+	}
+}
+
+func TestWithLocationBias(t *testing.T) {
+	o := newGeocodeOptions([]GeocodeOption{WithLocationBias(NewPoint(47.6918452, -122.2226413), 1500.5)})
+
+	expected := "circle:1500.5@47.691845,-122.222641"
+	if o.locationBiasStr() != expected {
+		t.Errorf("Mismatched location bias.  Expected: %s.  Actual: %s", expected, o.locationBiasStr())
+	}
+
+	expected = "locationbias=circle%3A1500.5%4047.691845%2C-122.222641"
+	if res := googleGeocodeOptionsQueryStr(o); res != expected {
+		t.Errorf("Mismatched query string.  Expected: %s.  Actual: %s", expected, res)
+	}
+
+	if newGeocodeOptions(nil).locationBiasStr() != "" {
+		t.Errorf("Expected no location bias by default.  Actual: %s", newGeocodeOptions(nil).locationBiasStr())
+	}
+
+	if o.cacheKey() == newGeocodeOptions(nil).cacheKey() {
+		t.Error("Expected the location bias to be part of the cache key")
+	}
+
+	settings := NewGeocodeSettings(WithLocationBias(NewPoint(1, 2), 300))
+	if settings.LocationBiasCenter == nil || !settings.LocationBiasCenter.Equal(NewPoint(1, 2)) || settings.LocationBiasRadius != 300 {
+		t.Errorf("Mismatched location bias settings.  Actual: %v, %f", settings.LocationBiasCenter, settings.LocationBiasRadius)
+	}
+}
+
+func TestWithSessionToken(t *testing.T) {
+	token, err := NewSessionToken()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(token) {
+		t.Errorf("Expected a version 4 UUID.  Actual: %s", token)
+	}
+
+	if other, _ := NewSessionToken(); other == token {
+		t.Errorf("Expected each session token to be different.  Actual: %s twice", token)
+	}
+
+	o := newGeocodeOptions([]GeocodeOption{WithSessionToken("a b&c")})
+	if res := googleGeocodeOptionsQueryStr(o); res != "sessiontoken=a+b%26c" {
+		t.Errorf("Mismatched query string.  Expected: sessiontoken=a+b%%26c.  Actual: %s", res)
+	}
+
+	if res := googleReverseGeocodeOptionsQueryStr(o); res != "sessiontoken=a+b%26c" {
+		t.Errorf("Mismatched reverse query string.  Expected: sessiontoken=a+b%%26c.  Actual: %s", res)
+	}
+
+	if o.cacheKey() != newGeocodeOptions(nil).cacheKey() {
+		t.Error("Expected the session token not to affect the cache key")
+	}
+
+	if settings := NewGeocodeSettings(WithSessionToken(token)); settings.SessionToken != token {
+		t.Errorf("Mismatched session token setting.  Expected: %s.  Actual: %s", token, settings.SessionToken)
+	}
+}
+
+// Ensures that options that a provider does not support are ignored.
+func TestProviderOptionsIgnoreUnsupported(t *testing.T) {
+	o := newGeocodeOptions([]GeocodeOption{WithRegion("ch"), WithBounds(NewPoint(1, 2), NewPoint(3, 4))})
+
+	if res := mapquestOptionsQueryStr(o); res != "" {
+		t.Errorf("Expected MapQuest to ignore unsupported options, but got: %s", res)
+	}
+
+	if res := opencageOptionsQueryStr(o); res != "" {
+		t.Errorf("Expected OpenCage to ignore unsupported options, but got: %s", res)
+	}
+
+	o = newGeocodeOptions([]GeocodeOption{WithLanguage("de")})
+	if res := mapquestOptionsQueryStr(o); res != "&accept-language=de" {
+		t.Errorf("Mismatched MapQuest query string.  Actual: %s", res)
+	}
+
+	if res := opencageOptionsQueryStr(o); res != "&language=de" {
+		t.Errorf("Mismatched OpenCage query string.  Actual: %s", res)
+	}
+}
+
+func TestWithResultType(t *testing.T) {
+	o := newGeocodeOptions([]GeocodeOption{WithResultType("postal_code"), WithResultType("locality", "country")})
+
+	if !o.matchesTypes([]string{"locality", "political"}) {
+		t.Error("Expected a result of a requested type to match")
+	}
+
+	if o.matchesTypes([]string{"route"}) {
+		t.Error("Expected a result of no requested type not to match")
+	}
+
+	if !newGeocodeOptions(nil).matchesTypes(nil) {
+		t.Error("Expected every result to match when no types are requested")
+	}
+
+	expected := "result_type=postal_code%7Clocality%7Ccountry"
+	if res := googleReverseGeocodeOptionsQueryStr(o); res != expected {
+		t.Errorf("Mismatched query string.  Expected: %s.  Actual: %s", expected, res)
+	}
+
+	// Google does not filter forward geocoding results, so the parameter is not sent.
+	if res := googleGeocodeOptionsQueryStr(o); res != "" {
+		t.Errorf("Expected no forward geocoding parameters, but got: %s", res)
+	}
+}
+
+func TestSortGeocodeResults(t *testing.T) {
+	results := []*GeocodeResult{
+		{FormattedAddress: "a", Point: NewPoint(0, 2), LocationType: "APPROXIMATE"},
+		{FormattedAddress: "b", Point: NewPoint(0, 1), LocationType: "ROOFTOP", PartialMatch: true},
+		{FormattedAddress: "c", Point: NewPoint(0, 1), LocationType: "UNKNOWN"},
+		{FormattedAddress: "d", Point: NewPoint(0, 3), LocationType: "ROOFTOP"},
+	}
+
+	cases := []struct {
+		opts     []GeocodeOption
+		expected string
+	}{
+		{nil, "[a b c d]"},
+		{[]GeocodeOption{WithSortByDistance(NewPoint(0, 0))}, "[b c a d]"},
+		{[]GeocodeOption{WithSortByDistance(nil)}, "[a b c d]"},
+		{[]GeocodeOption{WithSortByConfidence()}, "[d a c b]"},
+	}
+
+	for _, c := range cases {
+		sorted := append([]*GeocodeResult{}, results...)
+		sortGeocodeResults(sorted, newGeocodeOptions(c.opts))
+		if res := fmt.Sprint(formattedAddresses(sorted)); res != c.expected {
+			t.Errorf("Mismatched order.  Expected: %s.  Actual: %s", c.expected, res)
+		}
+	}
+
+	settings := NewGeocodeSettings(WithSortByDistance(NewPoint(1, 2)))
+	if settings.ResultOrder != DistanceOrder || !settings.OrderReference.Equal(NewPoint(1, 2)) {
+		t.Errorf("Mismatched order settings.  Actual: %v, %v", settings.ResultOrder, settings.OrderReference)
+	}
+
+	if newGeocodeOptions([]GeocodeOption{WithSortByConfidence()}).cacheKey() != newGeocodeOptions(nil).cacheKey() {
+		t.Error("Expected the result order to be left out of the cache key")
+	}
+}
+
+func TestWithExtraParams(t *testing.T) {
+	o := newGeocodeOptions([]GeocodeOption{
+		WithLanguage("de"),
+		WithExtraParams(url.Values{"newparam": {"a,b"}}),
+		WithExtraParams(url.Values{"newparam": {"c"}, "other": {"1"}}),
+	})
+
+	expected := "language=de&newparam=a%2Cb&newparam=c&other=1"
+	if res := googleGeocodeOptionsQueryStr(o); res != expected {
+		t.Errorf("Mismatched query string.  Expected: %s.  Actual: %s", expected, res)
+	}
+
+	if res := googleReverseGeocodeOptionsQueryStr(o); res != expected {
+		t.Errorf("Mismatched reverse query string.  Expected: %s.  Actual: %s", expected, res)
+	}
+
+	if res := mapquestOptionsQueryStr(o); res != "&accept-language=de&newparam=a%2Cb&newparam=c&other=1" {
+		t.Errorf("Mismatched MapQuest query string.  Actual: %s", res)
+	}
+
+	if res := opencageOptionsQueryStr(newGeocodeOptions([]GeocodeOption{WithExtraParams(url.Values{"other": {"1"}})})); res != "&other=1" {
+		t.Errorf("Mismatched OpenCage query string.  Actual: %s", res)
+	}
+
+	if o.cacheKey() == newGeocodeOptions([]GeocodeOption{WithLanguage("de")}).cacheKey() {
+		t.Error("Expected the extra parameters to be part of the cache key")
+	}
+
+	if settings := NewGeocodeSettings(WithExtraParams(url.Values{"other": {"1"}})); settings.ExtraParams.Get("other") != "1" {
+		t.Errorf("Mismatched extra parameter settings.  Actual: %v", settings.ExtraParams)
+	}
+}
+
+func TestWithPrecision(t *testing.T) {
+	o := newGeocodeOptions([]GeocodeOption{WithPrecision(LocalityPrecision)})
+
+	expected := "result_type=locality%7Cadministrative_area_level_2%7Cadministrative_area_level_1%7Ccountry"
+	if res := googleReverseGeocodeOptionsQueryStr(o); res != expected {
+		t.Errorf("Mismatched query string.  Expected: %s.  Actual: %s", expected, res)
+	}
+
+	if !o.matchesTypes([]string{"locality", "political"}) || o.matchesTypes([]string{"street_address"}) {
+		t.Errorf("Mismatched result types for LocalityPrecision.  Actual: %v", o.types)
+	}
+
+	if o := newGeocodeOptions([]GeocodeOption{WithPrecision(CountryPrecision)}); fmt.Sprint(o.types) != "[country]" {
+		t.Errorf("Mismatched result types for CountryPrecision.  Expected: [country].  Actual: %v", o.types)
+	}
+
+	if o := newGeocodeOptions([]GeocodeOption{WithPrecision(AddressPrecision)}); len(o.types) != 11 {
+		t.Errorf("Expected AddressPrecision to allow every level's result types.  Actual: %v", o.types)
+	}
+
+	if o := newGeocodeOptions([]GeocodeOption{WithPrecision(Precision(42))}); len(o.types) != 0 {
+		t.Errorf("Expected an unknown precision to be ignored.  Actual: %v", o.types)
+	}
+}
@@ -0,0 +1,158 @@
+package geo
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// Holds a single geocoding match along with the details that the provider returned for it.
+// Details that the provider does not support are left empty.
+type GeocodeResult struct {
+	Point            *Point
+	FormattedAddress string
+	PlusCode         PlusCode
+
+	// The recommended viewport for displaying the result, or nil if the provider did not return one.
+	Viewport *BoundingBox
+
+	// The provider's types for the result, such as "street_address" or "postal_code".
+	Types []string
+
+	// Whether the provider could only match part of the query, and so may have guessed.
+	PartialMatch bool
+
+	// How precisely the provider located the Point, such as Google's "ROOFTOP" or "APPROXIMATE".
+	LocationType string
+
+	// How precisely the provider reported the Point, for providers that report coordinates as text, such as MapQuest's Nominatim.
+	// It is nil for providers that report them as JSON numbers, whose precision is lost when they are parsed.
+	ReportedPrecision *ReportedPrecision
+
+	// The query that produced the result, and the error that occurred instead, if any.
+	// These are only set for results that are returned in bulk, such as by a BatchGeocoder.
+	Input string
+	Err   error
+
+	// The provider's response body that the result was parsed from.
+	// This is only set by geocoders that are asked to keep it for debugging, such as a GoogleGeocoder with DebugKeepRaw.
+	Raw json.RawMessage
+}
+
+// How precisely a provider reported the coordinates of a result, which hints at how accurately it located it.
+type ReportedPrecision struct {
+	// The number of digits after the decimal point of the latitude and longitude as reported, as in 7 for "52.5170365".
+	// Fewer decimals hint at a coarser location, such as a town's rather than a building's.
+	LatDecimals int
+	LngDecimals int
+}
+
+// Returns the number of digits after the decimal point of the passed in number, as written,
+// not counting any exponent.
+func reportedDecimals(number string) int {
+	dot := strings.IndexByte(number, '.')
+	if dot < 0 {
+		return 0
+	}
+
+	digits := number[dot+1:]
+	if e := strings.IndexAny(digits, "eE"); e >= 0 {
+		digits = digits[:e]
+	}
+
+	return len(digits)
+}
+
+// Holds the plus codes (Open Location Codes) of a location as returned by a provider.
+// The global code is a full code such as "849VCWC8+R9", and the compound code
+// shortens it relative to a nearby locality, such as "CWC8+R9 Mountain View, CA, USA".
+type PlusCode struct {
+	GlobalCode   string `json:"global_code"`
+	CompoundCode string `json:"compound_code"`
+}
+
+// The rank of each location type in the ConfidenceOrder, most precise first.
+// Any other location type ranks after all of these.
+var locationTypeRanks = map[string]int{
+	"ROOFTOP":            0,
+	"RANGE_INTERPOLATED": 1,
+	"GEOMETRIC_CENTER":   2,
+	"APPROXIMATE":        3,
+}
+
+// Stably sorts the passed in results into the order requested by the passed in options.
+// Results are left in the provider's order if no order was requested,
+// or if DistanceOrder was requested without a reference Point.
+func sortGeocodeResults(results []*GeocodeResult, o *geocodeOptions) {
+	switch o.order {
+	case DistanceOrder:
+		if o.orderRef == nil {
+			return
+		}
+
+		sort.SliceStable(results, func(i, j int) bool {
+			return o.orderRef.GreatCircleDistance(results[i].Point) < o.orderRef.GreatCircleDistance(results[j].Point)
+		})
+	case ConfidenceOrder:
+		sort.SliceStable(results, func(i, j int) bool {
+			return moreConfident(results[i], results[j])
+		})
+	}
+}
+
+// Returns whether or not the first of the passed in results comes before the second in the ConfidenceOrder:
+// full matches before partial ones, and then more precise location types before less precise ones.
+func moreConfident(a *GeocodeResult, b *GeocodeResult) bool {
+	if a.PartialMatch != b.PartialMatch {
+		return !a.PartialMatch
+	}
+
+	return locationTypeRank(a.LocationType) < locationTypeRank(b.LocationType)
+}
+
+// Merges the passed in results, such as those of several providers combined, so that each place appears only once.
+// Results whose Points are within the passed in tolerance (in meters) of the first result found for a place
+// are duplicates of it, and only the most confident of them is kept, as ranked by ConfidenceOrder.
+// Equally confident duplicates resolve to the earliest, so list the results of preferred providers first.
+// Each kept result takes the position of the first result found for its place, and results without a Point are kept as they are.
+func DedupResults(results []*GeocodeResult, toleranceMeters float64) []*GeocodeResult {
+	// The first result found for each place, which duplicates are measured against, and the best of them so far.
+	firsts := []*GeocodeResult{}
+	best := []*GeocodeResult{}
+
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+
+		duplicate := false
+		if r.Point != nil {
+			for i, first := range firsts {
+				if first.Point != nil && first.Point.EqualWithin(r.Point, toleranceMeters) {
+					if moreConfident(r, best[i]) {
+						best[i] = r
+					}
+
+					duplicate = true
+					break
+				}
+			}
+		}
+
+		if !duplicate {
+			firsts = append(firsts, r)
+			best = append(best, r)
+		}
+	}
+
+	return best
+}
+
+// Returns the rank of the passed in location type in the ConfidenceOrder.
+func locationTypeRank(locationType string) int {
+	if rank, ok := locationTypeRanks[locationType]; ok {
+		return rank
+	}
+
+	return len(locationTypeRanks)
+}
@@ -0,0 +1,37 @@
+package geo
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDedupResults(t *testing.T) {
+	results := []*GeocodeResult{
+		{FormattedAddress: "Google SFO", Point: NewPoint(37.6213, -122.3790), LocationType: "APPROXIMATE"},
+		{FormattedAddress: "Google Oakland", Point: NewPoint(37.7126, -122.2197), LocationType: "ROOFTOP"},
+		nil,
+		{FormattedAddress: "MapQuest SFO", Point: NewPoint(37.6218, -122.3840), LocationType: "ROOFTOP"},
+		{FormattedAddress: "OpenCage SFO", Point: NewPoint(37.6215, -122.3815), LocationType: "ROOFTOP"},
+		{FormattedAddress: "No Point"},
+		{FormattedAddress: "OpenCage Oakland", Point: NewPoint(37.7127, -122.2198), LocationType: "ROOFTOP", PartialMatch: true},
+	}
+
+	deduped := DedupResults(results, 1000)
+
+	// MapQuest's rooftop SFO beats Google's approximate one, and is preferred to OpenCage's equally precise one.
+	expected := "[MapQuest SFO Google Oakland No Point]"
+	if res := fmt.Sprint(formattedAddresses(deduped)); res != expected {
+		t.Errorf("Mismatched results.  Expected: %s.  Actual: %s", expected, res)
+	}
+
+	// With a tighter tolerance, the SFO results are more than 100m apart, and are all kept.
+	deduped = DedupResults(results, 100)
+	expected = "[Google SFO Google Oakland MapQuest SFO OpenCage SFO No Point]"
+	if res := fmt.Sprint(formattedAddresses(deduped)); res != expected {
+		t.Errorf("Mismatched results.  Expected: %s.  Actual: %s", expected, res)
+	}
+
+	if deduped := DedupResults(nil, 100); len(deduped) != 0 {
+		t.Errorf("Expected no results.  Actual: %v", deduped)
+	}
+}
@@ -1,13 +1,131 @@
 package geo
 
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// This is the error that consumers receive when a geocoding provider
+// successfully handled a request, but found no results for it.
+var ErrZeroResults = errors.New("ZERO_RESULTS")
+
+// This is the error that consumers receive when a geocoding provider found results for a request,
+// but none of them had a location, so that there is no Point to return without making one up.
+var ErrMissingGeometry = errors.New("geocoding results have no geometry")
+
+// The version of golang-geo, as recorded in the VERSION file.
+const Version = "0.6.1"
+
+// The User-Agent header sent with every geocoding request, unless a geocoder sets its own.
+// Some providers throttle or reject requests that carry Go's default User-Agent.
+var UserAgent = "golang-geo/" + Version + " (+https://github.com/alygueye/golang-geo)"
+
+// This is the error that consumers receive when a geocoder that does not follow redirects receives one.
+// The redirect is not followed, so credentials in the request are never sent to the host it points at.
+var ErrRedirectNotFollowed = errors.New("redirect not followed")
+
+// The most bytes a geocoder reads of a response body, unless it sets its own MaxResponseBytes.
+// Legitimate responses are a few kilobytes at most, so it only guards against endpoints that misbehave.
+const DefaultMaxResponseBytes = 10 << 20
+
+// This is the error that consumers receive when a response body is larger than the geocoder's MaxResponseBytes.
+// The rest of the body is never read, so that a huge response cannot exhaust the process's memory.
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// Returns the passed in limit, or DefaultMaxResponseBytes if it is not positive.
+func maxResponseBytes(limit int64) int64 {
+	if limit > 0 {
+		return limit
+	}
+
+	return DefaultMaxResponseBytes
+}
+
+// Returns a copy of the passed in http.Client that refuses to follow redirects.
+// The passed in http.Client is left untouched, since it may be shared.
+func noRedirectClient(client *http.Client) *http.Client {
+	c := *client
+	c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return ErrRedirectNotFollowed
+	}
+
+	return &c
+}
+
+// Sets the package level User-Agent.  Safe to call while geocoders are in use.
+func SetUserAgent(newUserAgent string) {
+	writeConfig(&UserAgent, newUserAgent)
+}
+
+// Returns the passed in User-Agent, or the package level UserAgent if it is empty.
+func userAgent(ua string) string {
+	if ua != "" {
+		return ua
+	}
+
+	return readConfig(&UserAgent)
+}
+
 // This interface describes a Geocoder, which provides the ability to Geocode and Reverse Geocode geographic points of interest.
 // Geocoding should accept a string that represents a street address, and returns a pointer to a Point that most closely identifies it.
 // Reverse geocoding should accept a pointer to a Point, and return the street address that most closely represents it.
+// Both accept GeocodeOptions, which a Geocoder should ignore if it does not support them.
+//
+// Geocoders outside of this package implement the interface in the same way,
+// and can then be wrapped by every Geocoder in this package that wraps another one,
+// such as the TTLCachingGeocoder, CircuitBreakerGeocoder, and BatchGeocoder.
+// A custom Geocoder reads its options with NewGeocodeSettings,
+// and returns ErrZeroResults when it finds nothing, so that wrappers can tell that apart from a failure.
+// GeocoderFuncs adapts a pair of functions into a Geocoder.
 type Geocoder interface {
-	Geocode(query string) (*Point, error)
-	ReverseGeocode(p *Point) (string, error)
+	Geocode(query string, opts ...GeocodeOption) (*Point, error)
+	ReverseGeocode(p *Point, opts ...GeocodeOption) (string, error)
+}
+
+// This interface describes a Geocoder that can also abandon a geocoding request once the passed in context is cancelled or times out,
+// such as the GoogleGeocoder.  Wrappers that bound how long a request may take, such as a BatchGeocoder with an ItemTimeout,
+// use it when the Geocoder they wrap implements it.
+type ContextGeocoder interface {
+	Geocoder
+	GeocodeContext(ctx context.Context, query string, opts ...GeocodeOption) (*Point, error)
+}
+
+// An adapter that allows the use of ordinary functions as a Geocoder.
+// Either function may be left nil, in which case the corresponding method returns ErrGeocoderFuncMissing.
+type GeocoderFuncs struct {
+	GeocodeFunc        func(query string, opts ...GeocodeOption) (*Point, error)
+	ReverseGeocodeFunc func(p *Point, opts ...GeocodeOption) (string, error)
+}
+
+// This is the error that consumers receive when calling a GeocoderFuncs method whose function is nil.
+var ErrGeocoderFuncMissing = errors.New("geocoder function not set")
+
+// Geocodes the passed in query with the GeocodeFunc.
+func (f GeocoderFuncs) Geocode(query string, opts ...GeocodeOption) (*Point, error) {
+	if f.GeocodeFunc == nil {
+		return nil, ErrGeocoderFuncMissing
+	}
+
+	return f.GeocodeFunc(query, opts...)
+}
+
+// Reverse geocodes the passed in Point with the ReverseGeocodeFunc.
+func (f GeocoderFuncs) ReverseGeocode(p *Point, opts ...GeocodeOption) (string, error) {
+	if f.ReverseGeocodeFunc == nil {
+		return "", ErrGeocoderFuncMissing
+	}
+
+	return f.ReverseGeocodeFunc(p, opts...)
 }
 
 type AddressComponentsGeocoder interface {
 	ReverseGeocodeAddressComponents(p *Point) ([]*AddressComponent, error)
 }
+
+// This interface describes a Geocoder that can report whether its provider is available,
+// for use in readiness probes and health checks.
+// Ping should return nil if the provider is reachable and accepts the Geocoder's credentials.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
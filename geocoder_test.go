@@ -0,0 +1,118 @@
+package geo
+
+import (
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// A Geocoder that only uses the public API, as one outside of this package would.
+// The language of the latest call is guarded by a mutex, since a BatchGeocoder calls it from several goroutines.
+type thirdPartyGeocoder struct {
+	mu       sync.Mutex
+	language string
+}
+
+func (g *thirdPartyGeocoder) Geocode(query string, opts ...GeocodeOption) (*Point, error) {
+	g.mu.Lock()
+	g.language = NewGeocodeSettings(opts...).Language
+	g.mu.Unlock()
+
+	if query == "" {
+		return nil, ErrZeroResults
+	}
+
+	return NewPoint(37.619002, -122.37484), nil
+}
+
+func (g *thirdPartyGeocoder) ReverseGeocode(p *Point, opts ...GeocodeOption) (string, error) {
+	return "San Francisco International Airport", nil
+}
+
+// Ensures that a custom Geocoder can be wrapped by each of the wrapping Geocoders.
+func TestThirdPartyGeocoderWrappers(t *testing.T) {
+	custom := &thirdPartyGeocoder{}
+
+	wrappers := map[string]Geocoder{
+		"ttl":          NewTTLCachingGeocoder(custom, time.Minute),
+		"breaker":      NewCircuitBreakerGeocoder(custom, 3, time.Minute),
+		"instrumented": NewInstrumentedGeocoder(custom, "custom", &recordingCollector{}),
+		"normalizing":  NewNormalizingGeocoder(custom),
+	}
+
+	for name, g := range wrappers {
+		p, err := g.Geocode("San Francisco Airport", WithLanguage("fr"))
+		if err != nil || p == nil {
+			t.Errorf("Expected the %s wrapper to geocode with the custom geocoder, but got %v, %v", name, p, err)
+		}
+
+		if custom.language != "fr" {
+			t.Errorf("Expected the %s wrapper to pass the options through, but got %q", name, custom.language)
+		}
+		custom.language = ""
+	}
+
+	results := NewBatchGeocoder(custom, 2).GeocodeBatch([]string{"San Francisco Airport", ""})
+	if results[0].Err != nil || results[1].Err != ErrZeroResults {
+		t.Errorf("Mismatched batch results: %+v", results)
+	}
+}
+
+func TestNewGeocodeSettings(t *testing.T) {
+	sw, ne := NewPoint(1, 2), NewPoint(3, 4)
+	s := NewGeocodeSettings(
+		WithLanguage("de"),
+		WithRegion("ch"),
+		WithBounds(sw, ne),
+		WithComponent("country", "CH"),
+		WithResultType("postal_code"),
+		WithPreferredResultType("street_address"),
+	)
+
+	if s.Language != "de" || s.Region != "ch" || s.SouthWest != sw || s.NorthEast != ne {
+		t.Errorf("Mismatched settings: %+v", s)
+	}
+
+	if len(s.Components) != 1 || s.Components[0] != (GeocodeComponent{Name: "country", Value: "CH"}) {
+		t.Errorf("Mismatched components: %+v", s.Components)
+	}
+
+	if len(s.ResultTypes) != 1 || s.ResultTypes[0] != "postal_code" || len(s.PreferredResultTypes) != 1 || s.PreferredResultTypes[0] != "street_address" {
+		t.Errorf("Mismatched result types: %+v", s)
+	}
+}
+
+func TestGeocoderFuncs(t *testing.T) {
+	var g Geocoder = GeocoderFuncs{
+		GeocodeFunc: func(query string, opts ...GeocodeOption) (*Point, error) {
+			return NewPoint(1, 2), nil
+		},
+	}
+
+	p, err := g.Geocode("anything")
+	if err != nil || !p.Equal(NewPoint(1, 2)) {
+		t.Errorf("Expected the GeocodeFunc to be called, but got %v, %v", p, err)
+	}
+
+	if _, err := g.ReverseGeocode(p); err != ErrGeocoderFuncMissing {
+		t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", ErrGeocoderFuncMissing, err)
+	}
+}
+
+// Ensures that the Version constant, and so the default User-Agent, is kept in step with the VERSION file.
+func TestVersion(t *testing.T) {
+	data, err := ioutil.ReadFile("VERSION")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if expected := strings.TrimSpace(string(data)); Version != expected {
+		t.Errorf("Mismatched Version.  Expected: %s.  Actual: %s", expected, Version)
+	}
+
+	if !strings.HasPrefix(UserAgent, "golang-geo/"+Version+" ") {
+		t.Errorf("Expected the default User-Agent to carry the version, but got %s", UserAgent)
+	}
+}
@@ -0,0 +1,123 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+// The base32 alphabet used by geohashes.
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// The longest geohash that is encoded.  At this length a cell is a few centimeters across.
+const maxGeohashPrecision = 12
+
+// This is the error that consumers receive when decoding a string that is not a valid geohash.
+var ErrInvalidGeohash = errors.New("invalid geohash")
+
+// Returns the geohash of the current Point with the passed in number of characters.
+// The precision is clamped to between 1 and 12 characters.
+func (p *Point) Geohash(precision int) string {
+	if precision < 1 {
+		precision = 1
+	}
+
+	if precision > maxGeohashPrecision {
+		precision = maxGeohashPrecision
+	}
+
+	latRange := [2]float64{-90, 90}
+	lngRange := [2]float64{-180, 180}
+	lng := normalizeLongitude(p.lng)
+
+	hash := make([]byte, 0, precision)
+	even := true
+	bit, ch := 0, 0
+
+	for len(hash) < precision {
+		if even {
+			mid := (lngRange[0] + lngRange[1]) / 2
+			if lng >= mid {
+				ch = ch<<1 | 1
+				lngRange[0] = mid
+			} else {
+				ch = ch << 1
+				lngRange[1] = mid
+			}
+		} else {
+			mid := (latRange[0] + latRange[1]) / 2
+			if p.lat >= mid {
+				ch = ch<<1 | 1
+				latRange[0] = mid
+			} else {
+				ch = ch << 1
+				latRange[1] = mid
+			}
+		}
+
+		even = !even
+		bit++
+		if bit == 5 {
+			hash = append(hash, geohashAlphabet[ch])
+			bit, ch = 0, 0
+		}
+	}
+
+	return string(hash)
+}
+
+// Returns the Point at the center of the cell described by the passed in geohash,
+// or ErrInvalidGeohash if it contains characters outside of the geohash alphabet.
+func DecodeGeohash(hash string) (*Point, error) {
+	if hash == "" {
+		return nil, ErrInvalidGeohash
+	}
+
+	latRange := [2]float64{-90, 90}
+	lngRange := [2]float64{-180, 180}
+	even := true
+
+	for _, c := range strings.ToLower(hash) {
+		idx := strings.IndexRune(geohashAlphabet, c)
+		if idx < 0 {
+			return nil, ErrInvalidGeohash
+		}
+
+		for mask := 16; mask > 0; mask >>= 1 {
+			r := &latRange
+			if even {
+				r = &lngRange
+			}
+
+			mid := (r[0] + r[1]) / 2
+			if idx&mask != 0 {
+				r[0] = mid
+			} else {
+				r[1] = mid
+			}
+
+			even = !even
+		}
+	}
+
+	return NewPoint((latRange[0]+latRange[1])/2, (lngRange[0]+lngRange[1])/2), nil
+}
+
+// Returns the height and width (in degrees) of a geohash cell of the passed in precision.
+func geohashCellSize(precision int) (float64, float64) {
+	bits := 5 * precision
+	lngBits := (bits + 1) / 2
+	latBits := bits / 2
+
+	return 180 / math.Pow(2, float64(latBits)), 360 / math.Pow(2, float64(lngBits))
+}
+
+// Wraps the passed in longitude into the range [-180, 180).
+func normalizeLongitude(lng float64) float64 {
+	lng = math.Mod(lng+180, 360)
+	if lng < 0 {
+		lng += 360
+	}
+
+	return lng - 180
+}
@@ -0,0 +1,56 @@
+package geo
+
+import (
+	"testing"
+)
+
+func TestGeohash(t *testing.T) {
+	// Reference value from http://geohash.org
+	p := NewPoint(57.64911, 10.40744)
+	res := p.Geohash(11)
+
+	expected := "u4pruydqqvj"
+	if res != expected {
+		t.Errorf("Mismatched geohash.  Expected: %s.  Actual: %s", expected, res)
+	}
+
+	if p.Geohash(0) != "u" {
+		t.Errorf("Expected precision to be clamped to at least 1, but got %s", p.Geohash(0))
+	}
+
+	if len(p.Geohash(20)) != maxGeohashPrecision {
+		t.Errorf("Expected precision to be clamped to at most %d", maxGeohashPrecision)
+	}
+}
+
+func TestDecodeGeohash(t *testing.T) {
+	p, err := DecodeGeohash("u4pruydqqvj")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !p.EqualWithin(NewPoint(57.64911, 10.40744), 1) {
+		t.Errorf("Expected the decoded geohash to be within 1 meter of the original point, but got %v", p)
+	}
+
+	_, err = DecodeGeohash("u4pa")
+	if err != ErrInvalidGeohash {
+		t.Errorf("Mismatched error.  Expected: %v, Actual: %v", ErrInvalidGeohash, err)
+	}
+}
+
+func TestNormalizeLongitude(t *testing.T) {
+	cases := map[float64]float64{
+		0:    0,
+		180:  -180,
+		190:  -170,
+		-190: 170,
+		540:  -180,
+	}
+
+	for lng, expected := range cases {
+		if res := normalizeLongitude(lng); res != expected {
+			t.Errorf("Mismatched longitude for %f.  Expected: %f.  Actual: %f", lng, expected, res)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package geo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+)
+
+// The fields of a GeoJSON geometry object that the parsers read.
+type geoJSONGeometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// Parses a Point from a GeoJSON Point geometry, e.g. {"type": "Point", "coordinates": [30, 10]}.
+// Coordinates are in [lng, lat] order, and any altitude is ignored.
+// Returns a *ParseError pointing at the offending token if the input is malformed.
+func ParsePointGeoJSON(data []byte, mode ParseMode) (*Point, error) {
+	g, offset, err := parseGeoJSONGeometry(data, "Point", mode)
+	if err != nil {
+		return nil, err
+	}
+
+	position := []float64{}
+	if err := unmarshalGeoJSONCoordinates(g.Coordinates, &position, offset); err != nil {
+		return nil, err
+	}
+
+	p, err := pointFromGeoJSONPosition(position, mode, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	if mode == ParseStrict {
+		if err := validateCoordinate(p, offset); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// Parses a Polygon from a GeoJSON Polygon geometry.  Coordinates are in [lng, lat] order.
+// Any interior rings (holes) are appended to the exterior ring as additional contours,
+// in the same way as Polygon.Add.
+// Returns a *ParseError pointing at the offending token if the input is malformed.
+func ParsePolygonGeoJSON(data []byte, mode ParseMode) (*Polygon, error) {
+	g, offset, err := parseGeoJSONGeometry(data, "Polygon", mode)
+	if err != nil {
+		return nil, err
+	}
+
+	rings := [][][]float64{}
+	if err := unmarshalGeoJSONCoordinates(g.Coordinates, &rings, offset); err != nil {
+		return nil, err
+	}
+
+	if len(rings) == 0 {
+		return nil, &ParseError{Offset: offset, Msg: "polygon must have at least one ring"}
+	}
+
+	polygon := &Polygon{}
+	for _, positions := range rings {
+		ring := make([]*Point, 0, len(positions))
+		for _, position := range positions {
+			p, err := pointFromGeoJSONPosition(position, mode, offset)
+			if err != nil {
+				return nil, err
+			}
+			ring = append(ring, p)
+		}
+
+		if err := validateRing(ring, mode, offset); err != nil {
+			return nil, err
+		}
+
+		for _, p := range ring {
+			polygon.Add(p)
+		}
+	}
+
+	return polygon, nil
+}
+
+// This is the error that consumers receive when rendering a FeatureCollection that holds a nil Feature,
+// or a Feature without a Point, since every GeoJSON Point feature needs coordinates.
+var ErrFeatureMissingPoint = errors.New("feature has no point")
+
+// A Point carrying arbitrary properties, as a GeoJSON Feature does.
+type Feature struct {
+	Point      *Point
+	Properties map[string]interface{}
+}
+
+// A collection of Features that renders as a GeoJSON FeatureCollection of Point features,
+// such as to serve them to Leaflet or any other mapping library that reads GeoJSON.
+type FeatureCollection struct {
+	Features []*Feature
+}
+
+// Creates and returns a pointer to a new, empty FeatureCollection.
+func NewFeatureCollection() *FeatureCollection {
+	return &FeatureCollection{Features: []*Feature{}}
+}
+
+// Appends a Feature of the passed in Point and properties to the current FeatureCollection.
+// The properties may be nil.
+func (fc *FeatureCollection) Add(p *Point, props map[string]interface{}) {
+	fc.Features = append(fc.Features, &Feature{Point: p, Properties: props})
+}
+
+// The GeoJSON form of a FeatureCollection of Point features.
+type geoJSONFeatureCollection struct {
+	Type     string            `json:"type"`
+	Features []*geoJSONFeature `json:"features"`
+}
+
+// The GeoJSON form of a single Point feature.  The geometry is left raw when parsing, so that it can be parsed as a Point.
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   json.RawMessage        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// Renders the current FeatureCollection to a GeoJSON FeatureCollection.
+// Coordinates are in [lng, lat] order, and nil properties are rendered as an empty object.
+// Returns ErrFeatureMissingPoint if any Feature is nil or has a nil Point.
+// Implements the json.Marshaler interface.
+func (fc *FeatureCollection) MarshalJSON() ([]byte, error) {
+	collection := &geoJSONFeatureCollection{Type: "FeatureCollection", Features: make([]*geoJSONFeature, len(fc.Features))}
+	for i, f := range fc.Features {
+		if f == nil || f.Point == nil {
+			return nil, ErrFeatureMissingPoint
+		}
+
+		geometry, err := json.Marshal(&geoJSONPointGeometry{Type: "Point", Coordinates: [2]float64{f.Point.lng, f.Point.lat}})
+		if err != nil {
+			return nil, err
+		}
+
+		props := f.Properties
+		if props == nil {
+			props = map[string]interface{}{}
+		}
+
+		collection.Features[i] = &geoJSONFeature{Type: "Feature", Geometry: geometry, Properties: props}
+	}
+
+	return json.Marshal(collection)
+}
+
+// The GeoJSON form of a Point geometry, for rendering.
+type geoJSONPointGeometry struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// Decodes the current FeatureCollection from a GeoJSON FeatureCollection, as ParseFeatureCollectionGeoJSON does in ParseStrict mode.
+// Implements the json.Unmarshaler interface.
+func (fc *FeatureCollection) UnmarshalJSON(data []byte) error {
+	res, err := ParseFeatureCollectionGeoJSON(data, ParseStrict)
+	if err != nil {
+		return err
+	}
+
+	*fc = *res
+
+	return nil
+}
+
+// Parses a FeatureCollection from a GeoJSON FeatureCollection of Point features, with their properties.
+// Coordinates are in [lng, lat] order, and every feature must have a Point geometry.
+// Returns a *ParseError pointing at the offending token if the input is malformed.
+func ParseFeatureCollectionGeoJSON(data []byte, mode ParseMode) (*FeatureCollection, error) {
+	if mode != ParseStrict {
+		data = blankTrailingCommas(data)
+	}
+
+	collection := &geoJSONFeatureCollection{}
+	if err := json.Unmarshal(data, collection); err != nil {
+		return nil, jsonParseError(err, 0)
+	}
+
+	if !geoJSONTypeMatches(collection.Type, "FeatureCollection", mode) {
+		return nil, &ParseError{Offset: bytes.Index(data, []byte(collection.Type)), Token: collection.Type, Msg: "expected a FeatureCollection"}
+	}
+
+	fc := NewFeatureCollection()
+	for _, f := range collection.Features {
+		if f == nil || !geoJSONTypeMatches(f.Type, "Feature", mode) {
+			return nil, &ParseError{Offset: 0, Msg: "expected every feature to be a Feature"}
+		}
+
+		offset := bytes.Index(data, f.Geometry)
+		if f.Geometry == nil || string(f.Geometry) == "null" {
+			return nil, &ParseError{Offset: offset, Msg: "missing geometry"}
+		}
+
+		p, err := ParsePointGeoJSON(f.Geometry, mode)
+		if err != nil {
+			if parseErr, ok := err.(*ParseError); ok {
+				parseErr.Offset += offset
+			}
+
+			return nil, err
+		}
+
+		fc.Add(p, f.Properties)
+	}
+
+	return fc, nil
+}
+
+// Returns whether or not the passed in GeoJSON type is the expected one.  Lenient mode ignores case.
+func geoJSONTypeMatches(geoJSONType string, expected string, mode ParseMode) bool {
+	return geoJSONType == expected || (mode != ParseStrict && strings.EqualFold(geoJSONType, expected))
+}
+
+// Decodes a single GeoJSON geometry object of the passed in type.
+// Returns the geometry and the offset of its coordinates within the input.
+func parseGeoJSONGeometry(data []byte, geometryType string, mode ParseMode) (*geoJSONGeometry, int, error) {
+	if mode != ParseStrict {
+		data = blankTrailingCommas(data)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	g := &geoJSONGeometry{}
+	if err := dec.Decode(g); err != nil {
+		return nil, 0, jsonParseError(err, 0)
+	}
+
+	end := int(dec.InputOffset())
+	if err := dec.Decode(&json.RawMessage{}); err != io.EOF {
+		return nil, 0, &ParseError{Offset: end + len(data[end:]) - len(bytes.TrimLeft(data[end:], " \t\n\r")), Msg: "unexpected trailing input"}
+	}
+
+	if !geoJSONTypeMatches(g.Type, geometryType, mode) {
+		return nil, 0, &ParseError{Offset: bytes.Index(data, []byte(g.Type)), Token: g.Type, Msg: "expected a " + geometryType + " geometry"}
+	}
+
+	if g.Coordinates == nil {
+		return nil, 0, &ParseError{Offset: 0, Msg: "missing coordinates"}
+	}
+
+	return g, bytes.Index(data, g.Coordinates), nil
+}
+
+func unmarshalGeoJSONCoordinates(raw json.RawMessage, v interface{}, offset int) error {
+	if err := json.Unmarshal(raw, v); err != nil {
+		return jsonParseError(err, offset)
+	}
+
+	return nil
+}
+
+// Returns a Point from a GeoJSON position.  Strict mode allows at most an altitude after the lng and lat.
+func pointFromGeoJSONPosition(position []float64, mode ParseMode, offset int) (*Point, error) {
+	if len(position) < 2 || (mode == ParseStrict && len(position) > 3) {
+		return nil, &ParseError{Offset: offset, Msg: "position must have 2 or 3 elements"}
+	}
+
+	return NewPoint(position[1], position[0]), nil
+}
+
+// Converts an encoding/json error into a *ParseError at the passed in base offset.
+func jsonParseError(err error, base int) error {
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		// The offset of a syntax error is just past the offending byte.
+		return &ParseError{Offset: base + int(e.Offset) - 1, Msg: e.Error()}
+	case *json.UnmarshalTypeError:
+		return &ParseError{Offset: base + int(e.Offset), Token: e.Value, Msg: "unexpected " + e.Value}
+	default:
+		return &ParseError{Offset: base, Msg: err.Error()}
+	}
+}
+
+// Replaces any comma that is followed only by whitespace before a closing ] or } with a space.
+// Commas within strings are left alone.  Replacing rather than removing keeps error offsets accurate.
+func blankTrailingCommas(data []byte) []byte {
+	res := make([]byte, len(data))
+	copy(res, data)
+
+	inString := false
+	for i := 0; i < len(res); i++ {
+		switch c := res[i]; {
+		case inString && c == '\\':
+			i++
+		case c == '"':
+			inString = !inString
+		case !inString && c == ',':
+			j := i + 1
+			for j < len(res) && strings.IndexByte(" \t\n\r", res[j]) >= 0 {
+				j++
+			}
+
+			if j < len(res) && (res[j] == ']' || res[j] == '}') {
+				res[i] = ' '
+			}
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,208 @@
+package geo
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParsePointGeoJSON(t *testing.T) {
+	p, err := ParsePointGeoJSON([]byte(`{"type": "Point", "coordinates": [-122.37484, 37.619002]}`), ParseStrict)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if p.lat != 37.619002 || p.lng != -122.37484 {
+		t.Errorf("Mismatched point.  Expected: [37.619002, -122.37484], Actual: %v", p)
+	}
+
+	p, err = ParsePointGeoJSON([]byte(`{"type": "point", "coordinates": [30, 10, 5, 1,],}`), ParseLenient)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if p.lat != 10 || p.lng != 30 {
+		t.Errorf("Mismatched point.  Expected: [10, 30], Actual: %v", p)
+	}
+}
+
+func TestParsePolygonGeoJSON(t *testing.T) {
+	data := []byte(`{
+		"type": "Polygon",
+		"coordinates": [
+			[[35, 10], [45, 45], [15, 40], [10, 20], [35, 10]],
+			[[20, 30], [35, 35], [30, 20], [20, 30]]
+		]
+	}`)
+
+	poly, err := ParsePolygonGeoJSON(data, ParseStrict)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(poly.Points()) != 9 {
+		t.Errorf("Expected 9 points, but got %d", len(poly.Points()))
+	}
+
+	if !poly.Contains(NewPoint(15, 25)) {
+		t.Error("Expected the parsed polygon to contain [15, 25]")
+	}
+
+	if poly.Contains(NewPoint(28, 29)) {
+		t.Error("Expected the parsed polygon not to contain [28, 29], which is inside of its hole")
+	}
+}
+
+func TestParseGeoJSONErrors(t *testing.T) {
+	cases := []struct {
+		input   string
+		mode    ParseMode
+		polygon bool
+		offset  int
+	}{
+		{`{"type": "Point", "coordinates": [30, 10],}`, ParseStrict, false, 42},
+		{`{"type": "Point", "coordinates": [30]}`, ParseLenient, false, 33},
+		{`{"type": "Point", "coordinates": [30, "10"]}`, ParseLenient, false, 42},
+		{`{"type": "point", "coordinates": [30, 10]}`, ParseStrict, false, 10},
+		{`{"type": "Point", "coordinates": [30, 100]}`, ParseStrict, false, 33},
+		{`{"type": "Point"}`, ParseLenient, false, 0},
+		{`{"type": "Point", "coordinates": [30, 10]} {}`, ParseLenient, false, 43},
+		{`{"type": "Polygon", "coordinates": [[[30, 10], [40, 40], [20, 40]]]}`, ParseStrict, true, 35},
+		{`{"type": "Polygon", "coordinates": []}`, ParseLenient, true, 35},
+		{`{"type": "Polygon", "coordinates": [[[30, 10], [40, 40]]]}`, ParseLenient, true, 35},
+	}
+
+	for _, c := range cases {
+		var err error
+		if c.polygon {
+			_, err = ParsePolygonGeoJSON([]byte(c.input), c.mode)
+		} else {
+			_, err = ParsePointGeoJSON([]byte(c.input), c.mode)
+		}
+
+		parseErr, ok := err.(*ParseError)
+		if !ok {
+			t.Errorf("Expected a *ParseError for %s, but got: %v", c.input, err)
+			continue
+		}
+
+		if parseErr.Offset != c.offset {
+			t.Errorf("Mismatched error offset for %s.  Expected: %d, Actual: %v", c.input, c.offset, parseErr)
+		}
+	}
+}
+
+func TestBlankTrailingCommas(t *testing.T) {
+	res := string(blankTrailingCommas([]byte(`{"a": [1, 2, ], "b": "x,]",}`)))
+
+	expected := `{"a": [1, 2  ], "b": "x,]" }`
+	if res != expected {
+		t.Errorf("Mismatched output.  Expected: %s, Actual: %s", expected, res)
+	}
+}
+
+// Ensures that the GeoJSON parsers never panic, whatever bytes they are given.
+func FuzzParsePolygonGeoJSON(f *testing.F) {
+	f.Add([]byte(`{"type": "Polygon", "coordinates": [[[30, 10], [40, 40], [20, 40], [30, 10]]]}`))
+	f.Add([]byte(`{"type": "Point", "coordinates": [30, 10]}`))
+	f.Add([]byte(`{"type": "Polygon", "coordinates": [[[]]]}`))
+	f.Add([]byte(`"\`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ParsePolygonGeoJSON(data, ParseStrict)
+		ParsePolygonGeoJSON(data, ParseLenient)
+		ParsePointGeoJSON(data, ParseStrict)
+		ParsePointGeoJSON(data, ParseLenient)
+	})
+}
+
+func TestFeatureCollectionMarshalJSON(t *testing.T) {
+	fc := NewFeatureCollection()
+	fc.Add(NewPoint(37.619002, -122.37484), map[string]interface{}{"name": "SFO", "gates": 115})
+	fc.Add(NewPoint(40.7486, -73.9864), nil)
+
+	data, err := json.Marshal(fc)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `{"type":"FeatureCollection","features":[` +
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[-122.37484,37.619002]},"properties":{"gates":115,"name":"SFO"}},` +
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[-73.9864,40.7486]},"properties":{}}]}`
+	if string(data) != expected {
+		t.Errorf("Mismatched GeoJSON.  Expected: %s.  Actual: %s", expected, data)
+	}
+
+	data, err = json.Marshal(NewFeatureCollection())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if string(data) != `{"type":"FeatureCollection","features":[]}` {
+		t.Errorf("Mismatched GeoJSON for an empty collection.  Actual: %s", data)
+	}
+
+	// Features without a Point are rejected rather than rendered.
+	for _, f := range []*Feature{nil, {Properties: map[string]interface{}{"name": "SFO"}}} {
+		fc := &FeatureCollection{Features: []*Feature{f}}
+		if _, err := json.Marshal(fc); !errors.Is(err, ErrFeatureMissingPoint) {
+			t.Errorf("Mismatched error for a feature without a point.  Expected: %v.  Actual: %v", ErrFeatureMissingPoint, err)
+		}
+	}
+}
+
+func TestParseFeatureCollectionGeoJSON(t *testing.T) {
+	fc := NewFeatureCollection()
+	fc.Add(NewPoint(37.619002, -122.37484), map[string]interface{}{"name": "SFO"})
+	fc.Add(NewPoint(40.7486, -73.9864), nil)
+
+	data, err := json.Marshal(fc)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	parsed := &FeatureCollection{}
+	if err := json.Unmarshal(data, parsed); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(parsed.Features) != 2 {
+		t.Fatalf("Mismatched number of features.  Expected: 2.  Actual: %d", len(parsed.Features))
+	}
+
+	if !parsed.Features[0].Point.Equal(NewPoint(37.619002, -122.37484)) || parsed.Features[0].Properties["name"] != "SFO" {
+		t.Errorf("Mismatched first feature.  Actual: %v, %v", parsed.Features[0].Point, parsed.Features[0].Properties)
+	}
+
+	if !parsed.Features[1].Point.Equal(NewPoint(40.7486, -73.9864)) || len(parsed.Features[1].Properties) != 0 {
+		t.Errorf("Mismatched second feature.  Actual: %v, %v", parsed.Features[1].Point, parsed.Features[1].Properties)
+	}
+
+	lenient := []byte(`{"type": "featurecollection", "features": [{"type": "Feature", "geometry": {"type": "point", "coordinates": [30, 10],},},],}`)
+	if parsed, err := ParseFeatureCollectionGeoJSON(lenient, ParseLenient); err != nil || !parsed.Features[0].Point.Equal(NewPoint(10, 30)) {
+		t.Errorf("Expected the lenient collection to parse.  Actual: %v, %v", parsed, err)
+	}
+
+	errorCases := []struct {
+		input  string
+		offset int
+	}{
+		{`{"type": "Feature", "features": []}`, 10},
+		{`{"type": "FeatureCollection", "features": [{"type": "Feature", "geometry": null}]}`, 75},
+		{`{"type": "FeatureCollection", "features": [{"type": "Feature", "geometry": {"type": "Point", "coordinates": [30]}}]}`, 108},
+		{`{"type": "FeatureCollection", "features": [{"type": "Feature", "geometry": {"type": "Point", "coordinates": [30, 100]}}]}`, 108},
+	}
+
+	for _, c := range errorCases {
+		_, err := ParseFeatureCollectionGeoJSON([]byte(c.input), ParseStrict)
+		parseErr, ok := err.(*ParseError)
+		if !ok {
+			t.Errorf("Expected a *ParseError for %s, but got: %v", c.input, err)
+			continue
+		}
+
+		if parseErr.Offset != c.offset {
+			t.Errorf("Mismatched offset for %s.  Expected: %d.  Actual: %d (%v)", c.input, c.offset, parseErr.Offset, parseErr)
+		}
+	}
+}
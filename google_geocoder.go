@@ -1,23 +1,28 @@
 package geo
 
 import (
-	"bytes"
-	"crypto/hmac"
-	"crypto/sha1"
-	"encoding/base64"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
 )
 
+// Describes how requests to the Google Maps Geocoding Service are authenticated.
 type GoogleAuthSchema int
 
 const (
-	_ = iota
+	// The zero value.  Requests are sent without any credentials,
+	// which Google no longer accepts for most use cases.
+	// It is what a GoogleGeocoder uses if its AuthSchema is never set.
+	GoogleAuthDefault GoogleAuthSchema = iota
+
+	// Requests carry the GoogleAPIKey as the key parameter, and the GoogleChannel if one is set.
 	GoogleMapsAPIToken
+
+	// Requests carry the GoogleClientID and are signed with the GooglePrivateKey.
 	GoogleMapsForWorkAuth
 )
 
@@ -26,34 +31,365 @@ const (
 type GoogleGeocoder struct {
 	HttpClient *http.Client
 	AuthSchema GoogleAuthSchema
+
+	// Credentials scoped to this geocoder.  Any that are left empty
+	// fall back to the package level GoogleAPIKey, GoogleClientID,
+	// GooglePrivateKey, and GoogleChannel respectively,
+	// unless the geocoder was created by NewGoogleGeocoder, in which case they are left empty.
+	APIKey     string
+	ClientID   string
+	PrivateKey string
+	Channel    string
+
+	// The base URL of the Geocoding API.  Falls back to the URL set by SetGoogleGeocodeURL if empty,
+	// or to Google's own if the geocoder was created by NewGoogleGeocoder.
+	BaseURL string
+
+	// The User-Agent header sent with each request.  Falls back to the package level UserAgent if empty.
+	UserAgent string
+
+	// Whether or not to refuse redirects, so that requests stay on the configured host.
+	// By default, redirects are followed as the HttpClient's CheckRedirect decides.
+	DisableRedirects bool
+
+	// Whether or not to decode responses as they are read from the connection,
+	// rather than reading each one into memory in full first, to reduce memory use for large responses.
+	// Only applies to the methods that return typed results; Request and GeocodeRaw always return the full body.
+	StreamDecode bool
+
+	// Whether or not to keep each response body as the Raw field of the GeocodeResults made from it,
+	// to inspect what Google actually returned.  Responses are then read in full, even if StreamDecode is set.
+	// Off by default, since it retains the whole body for as long as the result is kept.
+	DebugKeepRaw bool
+
+	// The most bytes read of each response body, past which requests fail with ErrResponseTooLarge.
+	// Falls back to DefaultMaxResponseBytes if not positive.
+	MaxResponseBytes int64
+
+	// The language and region of every request, as if each call passed WithLanguage and WithRegion first,
+	// so that options passed to a call take precedence over them.  Neither is sent if empty.
+	DefaultLanguage string
+	DefaultRegion   string
+
+	// Parses each response of Geocode, GeocodeContext, GeocodeDetailed, GeocodeAll, GeocodeNearest, ReverseGeocode,
+	// ReverseGeocodeDetailed, and Ping in place of the built-in parser,
+	// for deployments that reshape Google's responses, such as behind a proxy.  See GoogleResponseParser.
+	// GeocodeRaw returns the reshaped responses as they are, without checking their status.
+	// The methods that return details only Google's own responses have, such as ReverseGeocodeComponents,
+	// return ErrResponseParserUnsupported instead.
+	// Defaults to the built-in parser of Google's own response shape if nil.
+	ResponseParser GoogleResponseParser
+
+	// Signs requests with the PrivateKey passed to WithForWorkAuth, so that it is not decoded again for every request.
+	signer *Signer
+
+	// Whether or not the geocoder was created by NewGoogleGeocoder, and so only uses its own settings,
+	// without falling back to the package level ones.
+	scoped bool
+}
+
+// Parses a response body into the Point and formatted address of the result it describes,
+// for responses that do not have the shape of Google's own.  Either may be left empty where it was not asked for,
+// such as the address of a forward geocoding response.  Return ErrZeroResults if the response describes no result.
+// Since the response is not parsed as Google's, result types are not filtered by the library,
+// and the details Google would return, such as its status and the result's viewport, are not available.
+type GoogleResponseParser func(data []byte) (*Point, string, error)
+
+// A GoogleOption configures a GoogleGeocoder created by NewGoogleGeocoder.
+type GoogleOption func(*GoogleGeocoder)
+
+// Creates and returns a pointer to a new GoogleGeocoder configured by the passed in options.
+// Unlike the package level Set functions, the options only affect the returned geocoder,
+// and the returned geocoder is not affected by the package level credentials or base URL either.
+func NewGoogleGeocoder(opts ...GoogleOption) *GoogleGeocoder {
+	g := &GoogleGeocoder{scoped: true}
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	return g
+}
+
+// Creates and returns a pointer to a new GoogleGeocoder that authenticates its requests with the passed in
+// Google Maps for Work client ID, private key, and channel, as by WithForWorkAuth, and is configured by the passed in options.
+// Returns ErrInvalidGooglePrivateKey if the private key is malformed, so that the mistake is caught before any request is made.
+func NewGoogleForWorkGeocoder(clientID string, privateKey string, channel string, opts ...GoogleOption) (*GoogleGeocoder, error) {
+	if _, err := NewSigner(privateKey); err != nil {
+		return nil, err
+	}
+
+	return NewGoogleGeocoder(append([]GoogleOption{WithForWorkAuth(clientID, privateKey, channel)}, opts...)...), nil
+}
+
+// Creates and returns a pointer to a new GoogleGeocoder
+// that authenticates its requests using the passed in schema.
+func NewGoogleGeocoderWithAuth(schema GoogleAuthSchema) *GoogleGeocoder {
+	return &GoogleGeocoder{AuthSchema: schema}
+}
+
+// Authenticates requests with the passed in API key.
+func WithAPIKey(apiKey string) GoogleOption {
+	return func(g *GoogleGeocoder) {
+		g.AuthSchema = GoogleMapsAPIToken
+		g.APIKey = apiKey
+	}
+}
+
+// Authenticates requests with the passed in Google Maps for Work client ID,
+// signing them with the passed in private key.  The channel may be left empty.
+// Requests fail with ErrInvalidGooglePrivateKey if the key is malformed;
+// create the geocoder with NewGoogleForWorkGeocoder to have it reported up front instead.
+func WithForWorkAuth(clientID string, privateKey string, channel string) GoogleOption {
+	return func(g *GoogleGeocoder) {
+		g.AuthSchema = GoogleMapsForWorkAuth
+		g.ClientID = clientID
+		g.PrivateKey = privateKey
+		g.Channel = channel
+
+		// A key that cannot be decoded is left for the first request to report.
+		g.signer, _ = NewSigner(privateKey)
+	}
+}
+
+// Sends requests using the passed in http.Client.
+func WithHTTPClient(client *http.Client) GoogleOption {
+	return func(g *GoogleGeocoder) {
+		g.HttpClient = client
+	}
+}
+
+// Sends requests to the passed in base URL rather than Google's.
+func WithBaseURL(baseURL string) GoogleOption {
+	return func(g *GoogleGeocoder) {
+		g.BaseURL = baseURL
+	}
+}
+
+// Refuses redirects, so that requests (and the credentials they carry) stay on the configured host.
+// Requests that are redirected fail with ErrRedirectNotFollowed.
+func WithoutRedirects() GoogleOption {
+	return func(g *GoogleGeocoder) {
+		g.DisableRedirects = true
+	}
+}
+
+// Decodes responses as they are read from the connection.  See StreamDecode.
+func WithStreamDecode() GoogleOption {
+	return func(g *GoogleGeocoder) {
+		g.StreamDecode = true
+	}
+}
+
+// Parses responses with the passed in parser rather than as Google's own.  See ResponseParser.
+func WithResponseParser(parser GoogleResponseParser) GoogleOption {
+	return func(g *GoogleGeocoder) {
+		g.ResponseParser = parser
+	}
+}
+
+// Keeps each response body as the Raw field of the GeocodeResults made from it.  See DebugKeepRaw.
+func WithDebugKeepRaw() GoogleOption {
+	return func(g *GoogleGeocoder) {
+		g.DebugKeepRaw = true
+	}
+}
+
+// Reads at most the passed in number of bytes of each response body.  See MaxResponseBytes.
+func WithMaxResponseBytes(limit int64) GoogleOption {
+	return func(g *GoogleGeocoder) {
+		g.MaxResponseBytes = limit
+	}
+}
+
+// Requests results in the passed in language unless a call asks for another.  See DefaultLanguage.
+func WithDefaultLanguage(language string) GoogleOption {
+	return func(g *GoogleGeocoder) {
+		g.DefaultLanguage = language
+	}
+}
+
+// Biases results towards the passed in region unless a call asks for another.  See DefaultRegion.
+func WithDefaultRegion(region string) GoogleOption {
+	return func(g *GoogleGeocoder) {
+		g.DefaultRegion = region
+	}
+}
+
+// Returns the passed in options preceded by the options of the current geocoder's DefaultLanguage and DefaultRegion,
+// so that, as later options override earlier ones, the passed in options take precedence over the defaults.
+func (g *GoogleGeocoder) withDefaults(opts []GeocodeOption) []GeocodeOption {
+	defaults := []GeocodeOption{}
+	if g.DefaultLanguage != "" {
+		defaults = append(defaults, WithLanguage(g.DefaultLanguage))
+	}
+	if g.DefaultRegion != "" {
+		defaults = append(defaults, WithRegion(g.DefaultRegion))
+	}
+
+	if len(defaults) == 0 {
+		return opts
+	}
+
+	return append(defaults, opts...)
+}
+
+// This struct contains only the status of a Geocoding Service response.
+type googleStatusResponse struct {
+	Status       string `json:"status"`
+	ErrorMessage string `json:"error_message"`
+}
+
+// Sends the passed in User-Agent header with each request.
+func WithUserAgent(ua string) GoogleOption {
+	return func(g *GoogleGeocoder) {
+		g.UserAgent = ua
+	}
 }
 
 // This struct contains selected fields from Google's Geocoding Service response
 type googleGeocodeResponse struct {
-	Results []struct {
-		FormattedAddress string `json:"formatted_address"`
-		Geometry         struct {
-			Location struct {
-				Lat float64
-				Lng float64
-			}
+	Status       string `json:"status"`
+	ErrorMessage string `json:"error_message"`
+	Results      []*googleGeocodeResult
+	PlusCode     *PlusCode `json:"plus_code"`
+
+	// The response body, if the geocoder keeps it.  See DebugKeepRaw.
+	raw json.RawMessage
+}
+
+// This struct contains selected fields from each result in a Geocoding Service response.
+// Forward and reverse geocoding responses share the same shape.
+type googleGeocodeResult struct {
+	FormattedAddress  string              `json:"formatted_address"`
+	AddressComponents []*AddressComponent `json:"address_components"`
+	Geometry          struct {
+		Location     *googleLatLng
+		LocationType string `json:"location_type"`
+		Viewport     *struct {
+			Northeast googleLatLng
+			Southwest googleLatLng
 		}
 	}
+	PlusCode     *PlusCode `json:"plus_code"`
+	PartialMatch bool      `json:"partial_match"`
+	Types        []string  `json:"types"`
+}
+
+// A coordinate as Google renders it.
+type googleLatLng struct {
+	Lat float64
+	Lng float64
+}
+
+// Returns whether or not the current result has a location.
+// Google occasionally omits it, or sends 0,0 in its place, for certain types of places,
+// and neither is a location worth returning.
+func (r *googleGeocodeResult) hasLocation() bool {
+	l := r.Geometry.Location
+	return l != nil && (l.Lat != 0 || l.Lng != 0)
 }
 
-type googleReverseGeocodeResponse struct {
-	Results []struct {
-		FormattedAddress string `json:"formatted_address"`
+type googleReverseGeocodeResponse googleGeocodeResponse
+
+// Creates and returns a pointer to a new GeocodeResult from the passed in result of the passed in response.
+// The response level plus code is used if the result lacks its own, and the Point is nil if it lacks a location.
+func newGoogleGeocodeResult(r *googleGeocodeResult, response *googleGeocodeResponse) *GeocodeResult {
+	res := &GeocodeResult{
+		FormattedAddress: r.FormattedAddress,
+		PartialMatch:     r.PartialMatch,
+		LocationType:     r.Geometry.LocationType,
+		Types:            r.Types,
+		Raw:              response.raw,
+	}
+
+	if r.hasLocation() {
+		res.Point = NewPoint(r.Geometry.Location.Lat, r.Geometry.Location.Lng)
+	}
+
+	if v := r.Geometry.Viewport; v != nil {
+		res.Viewport = NewBoundingBox(NewPoint(v.Southwest.Lat, v.Southwest.Lng), NewPoint(v.Northeast.Lat, v.Northeast.Lng))
 	}
+
+	if r.PlusCode != nil {
+		res.PlusCode = *r.PlusCode
+	} else if response.PlusCode != nil {
+		res.PlusCode = *response.PlusCode
+	}
+
+	return res
 }
 
 // This is the error that consumers receive when there
 // are no results from the geocoding request.
-var googleZeroResultsError = errors.New("ZERO_RESULTS")
+var googleZeroResultsError = ErrZeroResults
+
+// This is the error that consumers receive when Google responds with a status
+// other than OK or ZERO_RESULTS, such as OVER_QUERY_LIMIT, REQUEST_DENIED, or INVALID_REQUEST.
+type GoogleStatusError struct {
+	Status string
+
+	// The human-readable explanation Google sent along with the status, such as
+	// "The provided API key is invalid.", or an empty string if it sent none.
+	Message string
+}
+
+// Returns a description of the status Google responded with, and its explanation if there was one.
+func (e *GoogleStatusError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("google geocoder: %s: %s", e.Status, e.Message)
+	}
+
+	return fmt.Sprintf("google geocoder: %s", e.Status)
+}
+
+// Returns the error that corresponds to the passed in response status and error message,
+// or nil if the request succeeded.
+func googleStatusErr(status string, message string) error {
+	switch status {
+	case "", "OK":
+		return nil
+	case "ZERO_RESULTS":
+		return googleZeroResultsError
+	default:
+		return &GoogleStatusError{Status: status, Message: message}
+	}
+}
+
+// This is the error that consumers receive when using the GoogleMapsAPIToken
+// auth schema without having set an API key.
+var ErrGoogleAPIKeyRequired = errors.New("API key required for GoogleMapsAPIToken")
+
+// This is the error that consumers receive when using the GoogleMapsForWorkAuth
+// auth schema without having set both a client ID and a private key.
+var ErrGoogleForWorkCredentialsRequired = errors.New("client ID and private key required for GoogleMapsForWorkAuth")
+
+// This is the error that consumers receive when calling a method of a GoogleGeocoder with a ResponseParser
+// that returns details the parser does not provide, such as address components.
+var ErrResponseParserUnsupported = errors.New("not supported with a custom ResponseParser")
+
+// This is the error that consumers receive when calling GeocodePostalCode without both a postal code and a country code.
+var ErrPostalCodeQueryIncomplete = errors.New("postal code and country code are both required")
+
+// This is the error that consumers receive when using the GoogleMapsForWorkAuth
+// auth schema with a private key that is not the URL-safe base64 that Google issues.
+var ErrInvalidGooglePrivateKey = errors.New("invalid Google for-work private key: must be URL-safe base64")
+
+// Returns ErrInvalidGooglePrivateKey if the passed in private key cannot be used to sign requests, or nil if it can.
+// NewGoogleForWorkGeocoder and SetGooglePrivateKey check the key they are passed already;
+// this checks one that will be set on the PrivateKey field directly, such as when loading configuration.
+func ValidateGooglePrivateKey(privateKey string) error {
+	_, err := NewSigner(privateKey)
+	return err
+}
 
 // This contains the base URL for the Google Geocoder API.
-var googleGeocodeURL = "https://maps.googleapis.com/maps/api/geocode/json"
+var googleGeocodeURL = defaultGoogleGeocodeURL
+
+// The base URL of Google's own Geocoder API.
+const defaultGoogleGeocodeURL = "https://maps.googleapis.com/maps/api/geocode/json"
 
+// The package level Google credentials, used by any GoogleGeocoder that does not set its own.
+// Change them with SetGoogleAPIKey and friends if geocoders may be in use at the same time.
 var GoogleAPIKey = ""
 
 var GoogleClientID = ""
@@ -65,142 +401,500 @@ var GoogleChannel = ""
 //        interface and provide versioning of APIs accordingly.
 // Sets the base URL for the Google Geocoding API.
 func SetGoogleGeocodeURL(newGeocodeURL string) {
-	googleGeocodeURL = newGeocodeURL
+	writeConfig(&googleGeocodeURL, newGeocodeURL)
 }
 
 func SetGoogleAPIKey(newAPIKey string) {
-	GoogleAPIKey = newAPIKey
+	writeConfig(&GoogleAPIKey, newAPIKey)
 }
 
 func SetGoogleClientID(newGoogleClientID string) {
-	GoogleClientID = newGoogleClientID
+	writeConfig(&GoogleClientID, newGoogleClientID)
 }
 
-func SetGooglePrivateKey(newGooglePrivateKey string) {
-	GooglePrivateKey = newGooglePrivateKey
+// Sets the package level Google for-work private key, or clears it if empty.
+// Returns ErrInvalidGooglePrivateKey, and leaves the current key as it is, if the key is malformed.
+func SetGooglePrivateKey(newGooglePrivateKey string) error {
+	if newGooglePrivateKey != "" {
+		if _, err := cachedSigner(newGooglePrivateKey); err != nil {
+			return err
+		}
+	}
+
+	writeConfig(&GooglePrivateKey, newGooglePrivateKey)
+	return nil
 }
 
 func SetGoogleChannel(newGoogleChannel string) {
-	GoogleChannel = newGoogleChannel
+	writeConfig(&GoogleChannel, newGoogleChannel)
 }
 
 // Issues a request to the google geocoding service and forwards the passed in params string
 // as a URL-encoded entity.  Returns an array of byes as a result, or an error if one occurs during the process.
 // Note: Since this is an arbitrary request, you are responsible for passing in your API key if you want one.
 func (g *GoogleGeocoder) Request(params string) ([]byte, error) {
-	if g.HttpClient == nil {
-		g.HttpClient = &http.Client{}
+	return g.RequestContext(context.Background(), params)
+}
+
+// Issues a request to the google geocoding service like Request,
+// but abandons it if the passed in context is cancelled or times out first.
+func (g *GoogleGeocoder) RequestContext(ctx context.Context, params string) ([]byte, error) {
+	resp, err := g.send(ctx, params)
+	if err != nil {
+		return nil, err
 	}
+	defer resp.Body.Close()
+
+	data, dataReadErr := readAll(resp.Body, maxResponseBytes(g.MaxResponseBytes))
+
+	if dataReadErr != nil {
+		return nil, dataReadErr
+	}
+
+	return data, nil
+}
 
+// Sends a request with the passed in params and returns the response, whose body the caller must close.
+func (g *GoogleGeocoder) send(ctx context.Context, params string) (*http.Response, error) {
+	// The geocoder may be shared by many goroutines, such as those of a BatchGeocoder, so it is left as it is.
 	client := g.HttpClient
+	if client == nil {
+		client = http.DefaultClient
+	}
 
-	fullUrl := fmt.Sprintf("%s?%s", googleGeocodeURL, params)
+	if g.DisableRedirects {
+		client = noRedirectClient(client)
+	}
+
+	fullUrl := fmt.Sprintf("%s?%s", g.baseURL(), params)
 
 	// TODO Potentially refactor out from MapQuestGeocoder as well
-	req, _ := http.NewRequest("GET", fullUrl, nil)
-	resp, requestErr := client.Do(req)
+	req, err := http.NewRequestWithContext(ctx, "GET", fullUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent(g.UserAgent))
+
+	return client.Do(req)
+}
 
-	if requestErr != nil {
-		return nil, requestErr
+// Geocodes the passed in query string and returns a pointer to a new Point struct.
+// Supports the WithLanguage, WithRegion, WithBounds, WithComponent, WithResultType, WithPreferredResultType, and WithExtraParams options.
+// WithLocationBias and WithSessionToken are sent along, but the Geocoding API ignores them.
+// Returns an error if the underlying request cannot complete.
+func (g *GoogleGeocoder) Geocode(address string, opts ...GeocodeOption) (*Point, error) {
+	res, err := g.GeocodeDetailed(address, opts...)
+	if err != nil {
+		return nil, err
 	}
 
-	data, dataReadErr := ioutil.ReadAll(resp.Body)
+	return res.Point, nil
+}
 
-	if dataReadErr != nil {
-		return nil, dataReadErr
+// Geocodes the passed in query string like Geocode, but abandons the request if the passed in context
+// is cancelled or times out first, in which case the error wraps the context's error.
+func (g *GoogleGeocoder) GeocodeContext(ctx context.Context, address string, opts ...GeocodeOption) (*Point, error) {
+	res, err := g.geocodeDetailed(ctx, address, opts)
+	if err != nil {
+		return nil, err
 	}
 
-	return data, nil
+	return res.Point, nil
 }
 
-// Geocodes the passed in query string and returns a pointer to a new Point struct.
+// Geocodes the passed in postal code within the passed in country, such as "28001" and "ES",
+// since postal codes are not unique across countries.  The country is a two-letter ISO 3166-1 code.
+// Both are sent as component filters, so only a match for the postal code in that country is returned.
+// Supports the same options as Geocode.
+// Returns ErrPostalCodeQueryIncomplete, without making a request, if either is empty,
+// or an error if the underlying request cannot complete.
+func (g *GoogleGeocoder) GeocodePostalCode(code string, countryCode string, opts ...GeocodeOption) (*Point, error) {
+	code, countryCode = strings.TrimSpace(code), strings.TrimSpace(countryCode)
+	if code == "" || countryCode == "" {
+		return nil, ErrPostalCodeQueryIncomplete
+	}
+
+	// The options are copied first, so that the caller's slice is not appended to.
+	opts = append(append([]GeocodeOption{}, opts...), WithComponent("postal_code", code), WithComponent("country", countryCode))
+	return g.Geocode(code, opts...)
+}
+
+// Geocodes the passed in query string, biasing results towards the viewport
+// described by the passed in south-west and north-east corners, and returns a pointer to a new Point struct.
+// Results outside of the viewport may still be returned, but nearby ones are preferred.
 // Returns an error if the underlying request cannot complete.
-func (g *GoogleGeocoder) Geocode(address string) (*Point, error) {
-	params := googleGeocodeQueryStr(address)
+func (g *GoogleGeocoder) GeocodeWithinBounds(address string, sw *Point, ne *Point) (*Point, error) {
+	return g.Geocode(address, WithBounds(sw, ne))
+}
 
-	queryStr, err := g.googleFormattedRequestStr(params)
+// Geocodes the passed in query string and returns a pointer to a new GeocodeResult
+// holding the first match along with the details Google returned for it.
+// Supports the same options as Geocode.
+// Matches without a location are skipped in favor of the next one.
+// Returns an error if the underlying request cannot complete, or ErrMissingGeometry if no match has a location.
+func (g *GoogleGeocoder) GeocodeDetailed(address string, opts ...GeocodeOption) (*GeocodeResult, error) {
+	return g.geocodeDetailed(context.Background(), address, opts)
+}
+
+// Geocodes the passed in query string like GeocodeDetailed, but abandons the request if the passed in context is cancelled or times out first.
+func (g *GoogleGeocoder) geocodeDetailed(ctx context.Context, address string, opts []GeocodeOption) (*GeocodeResult, error) {
+	opts = g.withDefaults(opts)
+	if g.ResponseParser != nil {
+		res, err := g.parseCustomResponse(ctx, googleGeocodeParams(address, opts))
+		if err == nil && res.Point == nil {
+			return nil, ErrMissingGeometry
+		}
+
+		return res, err
+	}
+
+	res := &googleGeocodeResponse{}
+	err := g.authenticatedDecode(ctx, googleGeocodeParams(address, opts), res)
 	if err != nil {
 		return nil, err
 	}
 
-	data, err := g.Request(queryStr)
+	// Google does not filter forward geocoding results by type, so it is done here.
+	results, err := filterGoogleResults(res.Results, newGeocodeOptions(opts))
 	if err != nil {
 		return nil, err
 	}
 
+	return newGoogleGeocodeResult(preferredGoogleResult(results, newGeocodeOptions(opts)), res), nil
+}
+
+// Geocodes the passed in query string and returns every match, along with the details Google returned for each.
+// Results are in Google's order, unless WithSortByDistance or WithSortByConfidence asks for another.
+// Supports the same options as Geocode, though WithPreferredResultType has no effect, since no result is singled out.
+// Results without a location are left out.
+// Returns an error if the underlying request cannot complete, ErrZeroResults if nothing matched,
+// or ErrMissingGeometry if nothing that matched had a location.
+func (g *GoogleGeocoder) GeocodeAll(address string, opts ...GeocodeOption) ([]*GeocodeResult, error) {
+	opts = g.withDefaults(opts)
+	if g.ResponseParser != nil {
+		// The parser describes a single result, so there is nothing to sort.
+		res, err := g.parseCustomResponse(context.Background(), googleGeocodeParams(address, opts))
+		if err != nil {
+			return nil, err
+		}
+
+		if res.Point == nil {
+			return nil, ErrMissingGeometry
+		}
+
+		return []*GeocodeResult{res}, nil
+	}
+
 	res := &googleGeocodeResponse{}
-	json.Unmarshal(data, res)
+	err := g.authenticatedDecode(context.Background(), googleGeocodeParams(address, opts), res)
+	if err != nil {
+		return nil, err
+	}
+
+	o := newGeocodeOptions(opts)
+	filtered, err := filterGoogleResults(res.Results, o)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*GeocodeResult, len(filtered))
+	for i, r := range filtered {
+		results[i] = newGoogleGeocodeResult(r, res)
+	}
+
+	sortGeocodeResults(results, o)
+
+	return results, nil
+}
+
+// Geocodes the passed in query string and returns the match nearest to the passed in Point by great-circle distance,
+// such as the user's rough location, which disambiguates addresses more reliably than biasing with WithBounds or WithRegion.
+// Matches at the same distance resolve to the first in Google's order, as does a nil Point.
+// Supports the same options as GeocodeAll, though the results are always ranked by their distance from the Point.
+// Returns an error if the underlying request cannot complete, or ErrZeroResults if nothing matched.
+func (g *GoogleGeocoder) GeocodeNearest(address string, near *Point, opts ...GeocodeOption) (*GeocodeResult, error) {
+	// The options are copied first, so that the caller's slice is not appended to.
+	results, err := g.GeocodeAll(address, append(append([]GeocodeOption{}, opts...), WithSortByDistance(near))...)
+	if err != nil {
+		return nil, err
+	}
+
+	return results[0], nil
+}
+
+// Returns the first of the passed in results of a type preferred by the passed in options,
+// or the first result if there is no preference or no result matches it.
+func preferredGoogleResult(results []*googleGeocodeResult, o *geocodeOptions) *googleGeocodeResult {
+	for _, r := range results {
+		if hasAnyType(r.Types, o.preferred) {
+			return r
+		}
+	}
+
+	return results[0]
+}
+
+// Returns the passed in results that match the types requested by the passed in options, and that have a location.
+// Returns googleZeroResultsError if none match, or ErrMissingGeometry if the only ones that match lack a location.
+func filterGoogleResults(results []*googleGeocodeResult, o *geocodeOptions) ([]*googleGeocodeResult, error) {
+	filtered := []*googleGeocodeResult{}
+	missing := 0
+	for _, r := range results {
+		if !o.matchesTypes(r.Types) {
+			continue
+		}
+
+		if !r.hasLocation() {
+			missing++
+			continue
+		}
+
+		filtered = append(filtered, r)
+	}
+
+	if len(filtered) == 0 {
+		if missing > 0 {
+			return nil, ErrMissingGeometry
+		}
 
-	if len(res.Results) == 0 {
 		return nil, googleZeroResultsError
 	}
 
-	lat := res.Results[0].Geometry.Location.Lat
-	lng := res.Results[0].Geometry.Location.Lng
+	return filtered, nil
+}
+
+// Geocodes the passed in query string and returns Google's full response, untyped,
+// so that fields the typed methods do not expose can still be extracted.
+// Supports the same options as Geocode, and is authenticated and signed the same way,
+// though results are returned as Google sent them, without being filtered by WithResultType.
+// Returns an error if the underlying request cannot complete or if Google responds with a status other than OK.
+func (g *GoogleGeocoder) GeocodeRaw(address string, opts ...GeocodeOption) (json.RawMessage, error) {
+	data, err := g.authenticatedRequest(context.Background(), googleGeocodeParams(address, g.withDefaults(opts)))
+	if err != nil {
+		return nil, err
+	}
+
+	return json.RawMessage(data), nil
+}
+
+// The address that Ping geocodes.  Any address Google can always resolve would do.
+const googlePingQuery = "1600 Amphitheatre Parkway, Mountain View, CA"
+
+// Geocodes a known-good address to check that the Geocoding Service is reachable
+// and accepts the geocoder's credentials.  Returns nil if it does.
+// Otherwise, the error distinguishes the cause: network failures are returned as reported by the HttpClient,
+// missing credentials as ErrGoogleAPIKeyRequired or ErrGoogleForWorkCredentialsRequired,
+// a malformed private key as ErrInvalidGooglePrivateKey, and rejected credentials as a *GoogleStatusError with the REQUEST_DENIED status.
+func (g *GoogleGeocoder) Ping(ctx context.Context) error {
+	var err error
+	if g.ResponseParser != nil {
+		_, err = g.parseCustomResponse(ctx, googleGeocodeQueryStr(googlePingQuery))
+	} else {
+		_, err = g.authenticatedRequest(ctx, googleGeocodeQueryStr(googlePingQuery))
+	}
+
+	if err == googleZeroResultsError {
+		// The service handled the request, which is all that matters here.
+		return nil
+	}
+
+	return err
+}
+
+// Issues an authenticated request with the passed in params and returns the response body,
+// or returns an error if the request cannot complete or if Google's response reports a failed status.
+func (g *GoogleGeocoder) authenticatedRequest(ctx context.Context, params string) ([]byte, error) {
+	queryStr, err := g.googleFormattedRequestStr(params)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := g.RequestContext(ctx, queryStr)
+	if err != nil {
+		return nil, err
+	}
+
+	if g.ResponseParser != nil {
+		// A reshaped response need not carry Google's status.
+		return data, nil
+	}
+
+	status := &googleStatusResponse{}
+	err = json.Unmarshal(data, status)
+	if err != nil {
+		return nil, err
+	}
 
-	point := &Point{
-		lat: lat,
-		lng: lng,
+	err = googleStatusErr(status.Status, status.ErrorMessage)
+	if err != nil {
+		return nil, err
 	}
 
-	return point, nil
+	return data, nil
 }
 
+// Issues an authenticated request with the passed in params and decodes the response into the passed in struct,
+// reading it in full first unless StreamDecode is set, and keeping the body if DebugKeepRaw is set.
+// Returns an error if the request cannot complete or if Google's response reports a failed status.
+func (g *GoogleGeocoder) authenticatedDecode(ctx context.Context, params string, res *googleGeocodeResponse) error {
+	if g.ResponseParser != nil {
+		// The response does not have Google's shape, so it could only be misread.
+		return ErrResponseParserUnsupported
+	}
+
+	if !g.StreamDecode || g.DebugKeepRaw {
+		data, err := g.authenticatedRequest(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		if g.DebugKeepRaw {
+			res.raw = json.RawMessage(data)
+		}
+
+		return json.Unmarshal(data, res)
+	}
+
+	queryStr, err := g.googleFormattedRequestStr(params)
+	if err != nil {
+		return err
+	}
+
+	resp, err := g.send(ctx, queryStr)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	err = json.NewDecoder(newLimitedReader(resp.Body, maxResponseBytes(g.MaxResponseBytes))).Decode(res)
+	if err != nil {
+		return err
+	}
+
+	return googleStatusErr(res.Status, res.ErrorMessage)
+}
+
+// Builds the full query string for the passed in params, authenticated according to the AuthSchema.
+// Returns an error before any request is made if the credentials the AuthSchema requires are missing.
 func (g *GoogleGeocoder) googleFormattedRequestStr(params string) (string, error) {
 	query := fmt.Sprintf("%s&%s", "sensor=false", params)
 
 	switch g.AuthSchema {
 	case GoogleMapsAPIToken:
-		return buildGoogleMapsClientSideQuery(query)
+		apiKey := g.apiKey()
+		if apiKey == "" {
+			return "", ErrGoogleAPIKeyRequired
+		}
+
+		return buildGoogleMapsClientSideQuery(query, apiKey, g.channel())
 	case GoogleMapsForWorkAuth:
-		return buildGoogleMapsForWorkQuery(query)
+		clientID, privateKey := g.clientID(), g.privateKey()
+		if clientID == "" || privateKey == "" {
+			return "", ErrGoogleForWorkCredentialsRequired
+		}
+
+		signer, err := g.forWorkSigner(privateKey)
+		if err != nil {
+			return "", err
+		}
+
+		return buildGoogleMapsForWorkQuery(query, g.baseURL(), clientID, signer, g.channel())
 	default:
 		return buildDefaultGoogleMapsQuery(query)
 	}
 }
 
-func buildGoogleMapsClientSideQuery(query string) (string, error) {
-	queryBuffer := bytes.NewBufferString(query)
+func (g *GoogleGeocoder) baseURL() string {
+	if g.BaseURL != "" {
+		return g.BaseURL
+	}
 
-	_, err := queryBuffer.WriteString(fmt.Sprintf("&key=%s", GoogleAPIKey))
-	if err != nil {
-		return "", err
+	if g.scoped {
+		return defaultGoogleGeocodeURL
 	}
 
-	return queryBuffer.String(), nil
+	return readConfig(&googleGeocodeURL)
 }
 
-func buildGoogleMapsForWorkQuery(query string) (string, error) {
-	queryBuffer := bytes.NewBufferString(query)
+// Returns the passed in setting of the current geocoder, or the passed in package level one if it is empty,
+// unless the geocoder only uses its own settings.
+func (g *GoogleGeocoder) setting(value string, global *string) string {
+	if value != "" || g.scoped {
+		return value
+	}
 
-	if GoogleChannel != "" {
-		_, err := queryBuffer.WriteString(fmt.Sprintf("&channel=%s", GoogleChannel))
+	return readConfig(global)
+}
+
+func (g *GoogleGeocoder) apiKey() string {
+	return g.setting(g.APIKey, &GoogleAPIKey)
+}
+
+func (g *GoogleGeocoder) clientID() string {
+	return g.setting(g.ClientID, &GoogleClientID)
+}
+
+func (g *GoogleGeocoder) privateKey() string {
+	return g.setting(g.PrivateKey, &GooglePrivateKey)
+}
+
+// Returns the Signer made for the passed in private key by WithForWorkAuth, or the shared one made for it
+// if the key has changed since, such as when it is set on the PrivateKey field directly or falls back to the package level GooglePrivateKey.
+func (g *GoogleGeocoder) forWorkSigner(privateKey string) (*Signer, error) {
+	if g.signer != nil && g.signer.privateKey == privateKey {
+		return g.signer, nil
+	}
+
+	return cachedSigner(privateKey)
+}
+
+func (g *GoogleGeocoder) channel() string {
+	return g.setting(g.Channel, &GoogleChannel)
+}
+
+func buildGoogleMapsClientSideQuery(query string, apiKey string, channel string) (string, error) {
+	queryBuffer := getBuffer()
+	defer putBuffer(queryBuffer)
+	queryBuffer.WriteString(query)
+
+	if channel != "" {
+		_, err := fmt.Fprintf(queryBuffer, "&channel=%s", url.QueryEscape(channel))
 		if err != nil {
 			return "", err
 		}
 	}
 
-	_, err := queryBuffer.WriteString(fmt.Sprintf("&client=%s", GoogleClientID))
+	_, err := fmt.Fprintf(queryBuffer, "&key=%s", apiKey)
 	if err != nil {
 		return "", err
 	}
 
-	u, err := url.Parse(fmt.Sprintf("%s?%s", googleGeocodeURL, queryBuffer.String()))
+	return queryBuffer.String(), nil
+}
+
+func buildGoogleMapsForWorkQuery(query string, baseURL string, clientID string, signer *Signer, channel string) (string, error) {
+	queryBuffer := getBuffer()
+	defer putBuffer(queryBuffer)
+	queryBuffer.WriteString(query)
+
+	if channel != "" {
+		_, err := fmt.Fprintf(queryBuffer, "&channel=%s", url.QueryEscape(channel))
+		if err != nil {
+			return "", err
+		}
+	}
+
+	_, err := fmt.Fprintf(queryBuffer, "&client=%s", clientID)
 	if err != nil {
 		return "", err
 	}
 
-	requestUri := u.RequestURI()
-
-	decodedKey, err := base64.URLEncoding.DecodeString(GooglePrivateKey)
+	u, err := url.Parse(fmt.Sprintf("%s?%s", baseURL, queryBuffer.String()))
 	if err != nil {
 		return "", err
 	}
 
-	mac := hmac.New(sha1.New, decodedKey)
-	_, err = mac.Write([]byte(requestUri))
-
-	encodedSignature := base64.URLEncoding.EncodeToString(mac.Sum(nil))
+	encodedSignature := signer.Sign(u.RequestURI())
 
 	return fmt.Sprintf("%s&signature=%s", queryBuffer.String(), encodedSignature), nil
 }
@@ -215,74 +909,215 @@ func googleGeocodeQueryStr(address string) string {
 	return fmt.Sprintf("address=%s", url_safe_query)
 }
 
-// Reverse geocodes the pointer to a Point struct and returns the first address that matches
-// or returns an error if the underlying request cannot complete.
-func (g *GoogleGeocoder) ReverseGeocode(p *Point) (string, error) {
-	params := googleReverseGeocodeQueryStr(p)
+// Returns the parameters of a forward geocoding request for the passed in address and options.
+// Every option is composed into the one parameter string, so that, for example,
+// bounds and region are both sent (and both signed) rather than one replacing the other.
+func googleGeocodeParams(address string, opts []GeocodeOption) string {
+	params := googleGeocodeQueryStr(address)
 
-	queryStr, err := g.googleFormattedRequestStr(params)
-	if err != nil {
-		return "", err
+	optsStr := googleGeocodeOptionsQueryStr(newGeocodeOptions(opts))
+	if optsStr != "" {
+		params = fmt.Sprintf("%s&%s", params, optsStr)
 	}
 
-	data, err := g.Request(queryStr)
-	if err != nil {
-		return "", err
+	return params
+}
+
+func googleBoundsQueryStr(sw *Point, ne *Point) string {
+	bounds := fmt.Sprintf("%f,%f|%f,%f", sw.lat, sw.lng, ne.lat, ne.lng)
+
+	return fmt.Sprintf("bounds=%s", url.QueryEscape(bounds))
+}
+
+// Translates the passed in options into Google's forward geocoding parameters.
+func googleGeocodeOptionsQueryStr(o *geocodeOptions) string {
+	params := []string{}
+
+	if o.language != "" {
+		params = append(params, fmt.Sprintf("language=%s", url.QueryEscape(o.language)))
 	}
 
-	res := &googleReverseGeocodeResponse{}
-	err = json.Unmarshal(data, res)
-	if err != nil {
-		return "", err
+	if o.region != "" {
+		params = append(params, fmt.Sprintf("region=%s", url.QueryEscape(o.region)))
 	}
 
-	if len(res.Results) == 0 {
-		return "", googleZeroResultsError
+	if o.hasBounds() {
+		params = append(params, googleBoundsQueryStr(o.sw, o.ne))
 	}
 
-	return res.Results[0].FormattedAddress, err
+	if len(o.components) > 0 {
+		params = append(params, fmt.Sprintf("components=%s", url.QueryEscape(o.componentsStr())))
+	}
+
+	if bias := o.locationBiasStr(); bias != "" {
+		params = append(params, fmt.Sprintf("locationbias=%s", url.QueryEscape(bias)))
+	}
+
+	if o.session != "" {
+		params = append(params, fmt.Sprintf("sessiontoken=%s", url.QueryEscape(o.session)))
+	}
+
+	if len(o.extra) > 0 {
+		params = append(params, o.extra.Encode())
+	}
+
+	return strings.Join(params, "&")
 }
 
-func googleReverseGeocodeQueryStr(p *Point) string {
-	return fmt.Sprintf("latlng=%f,%f", p.lat, p.lng)
+// Translates the passed in options into Google's reverse geocoding parameters.
+func googleReverseGeocodeOptionsQueryStr(o *geocodeOptions) string {
+	params := []string{}
+
+	if o.language != "" {
+		params = append(params, fmt.Sprintf("language=%s", url.QueryEscape(o.language)))
+	}
+
+	if len(o.types) > 0 {
+		params = append(params, fmt.Sprintf("result_type=%s", url.QueryEscape(strings.Join(o.types, "|"))))
+	}
+
+	if o.session != "" {
+		params = append(params, fmt.Sprintf("sessiontoken=%s", url.QueryEscape(o.session)))
+	}
+
+	if len(o.extra) > 0 {
+		params = append(params, o.extra.Encode())
+	}
+
+	return strings.Join(params, "&")
 }
 
 // Reverse geocodes the pointer to a Point struct and returns the first address that matches
 // or returns an error if the underlying request cannot complete.
-func (g *GoogleGeocoder) ReverseGeocodeAddressComponents(p *Point) ([]*AddressComponent, error) {
-	params := googleReverseGeocodeQueryStr(p)
+// Supports the WithLanguage, WithResultType, WithPreferredResultType, and WithExtraParams options.
+func (g *GoogleGeocoder) ReverseGeocode(p *Point, opts ...GeocodeOption) (string, error) {
+	if g.ResponseParser != nil {
+		res, err := g.parseCustomResponse(context.Background(), googleReverseGeocodeParams(p, g.withDefaults(opts)))
+		if err != nil {
+			return "", err
+		}
+
+		return res.FormattedAddress, nil
+	}
 
+	res, _, err := g.reverseGeocode(p, opts)
+	if err != nil {
+		return "", err
+	}
+
+	return res.FormattedAddress, nil
+}
+
+// Issues an authenticated request with the passed in params and parses the response with the ResponseParser,
+// returning a GeocodeResult holding the Point and formatted address it found.
+// Returns an error if the request cannot complete or if the ResponseParser fails.
+func (g *GoogleGeocoder) parseCustomResponse(ctx context.Context, params string) (*GeocodeResult, error) {
 	queryStr, err := g.googleFormattedRequestStr(params)
 	if err != nil {
 		return nil, err
 	}
 
-	data, err := g.Request(queryStr)
+	data, err := g.RequestContext(ctx, queryStr)
 	if err != nil {
 		return nil, err
 	}
 
-	res := &googleReverseGeocodeAddressComponentResponse{}
-	err = json.Unmarshal(data, res)
+	p, address, err := g.ResponseParser(data)
 	if err != nil {
 		return nil, err
 	}
 
+	res := &GeocodeResult{Point: p, FormattedAddress: address}
+	if g.DebugKeepRaw {
+		res.Raw = json.RawMessage(data)
+	}
+
+	return res, nil
+}
+
+// Issues a reverse geocoding request for the passed in Point and returns the first result, or the first of the
+// preferred type if one was requested, along with the whole response.
+// Returns googleZeroResultsError if the response contains no results,
+// or a *GoogleStatusError if Google reports any other failed status.
+func (g *GoogleGeocoder) reverseGeocode(p *Point, opts []GeocodeOption) (*googleGeocodeResult, *googleGeocodeResponse, error) {
+	opts = g.withDefaults(opts)
+	params := googleReverseGeocodeParams(p, opts)
+
+	res := &googleReverseGeocodeResponse{}
+	err := g.authenticatedDecode(context.Background(), params, (*googleGeocodeResponse)(res))
+	if err != nil {
+		return nil, nil, err
+	}
+
 	if len(res.Results) == 0 {
-		return nil, googleZeroResultsError
+		return nil, nil, googleZeroResultsError
+	}
+
+	return preferredGoogleResult(res.Results, newGeocodeOptions(opts)), (*googleGeocodeResponse)(res), nil
+}
+
+// Returns the parameters of a reverse geocoding request for the passed in Point and options.
+func googleReverseGeocodeParams(p *Point, opts []GeocodeOption) string {
+	params := googleReverseGeocodeQueryStr(p)
+
+	optsStr := googleReverseGeocodeOptionsQueryStr(newGeocodeOptions(opts))
+	if optsStr != "" {
+		params = fmt.Sprintf("%s&%s", params, optsStr)
 	}
 
-	return res.Results[0].AddressComponents, err
+	return params
 }
 
-type googleReverseGeocodeAddressComponentResponse struct {
-	Results []struct {
-		AddressComponents []*AddressComponent `json:"address_components"`
+// Reverse geocodes the pointer to a Point struct and returns a pointer to a new GeocodeResult
+// holding the first match along with the details Google returned for it,
+// or returns an error if the underlying request cannot complete.
+// Supports the WithLanguage, WithResultType, WithPreferredResultType, and WithExtraParams options.
+func (g *GoogleGeocoder) ReverseGeocodeDetailed(p *Point, opts ...GeocodeOption) (*GeocodeResult, error) {
+	if g.ResponseParser != nil {
+		return g.parseCustomResponse(context.Background(), googleReverseGeocodeParams(p, g.withDefaults(opts)))
 	}
+
+	res, response, err := g.reverseGeocode(p, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return newGoogleGeocodeResult(res, response), nil
+}
+
+func googleReverseGeocodeQueryStr(p *Point) string {
+	return fmt.Sprintf("latlng=%f,%f", p.lat, p.lng)
+}
+
+// Reverse geocodes the pointer to a Point struct and returns the first address that matches
+// or returns an error if the underlying request cannot complete.
+func (g *GoogleGeocoder) ReverseGeocodeAddressComponents(p *Point) ([]*AddressComponent, error) {
+	res, _, err := g.reverseGeocode(p, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.AddressComponents, nil
+}
+
+// Reverse geocodes the pointer to a Point struct and returns the first matching address
+// broken out into its components, or returns an error if the underlying request cannot complete.
+// Supports the WithLanguage, WithResultType, WithPreferredResultType, and WithExtraParams options.
+func (g *GoogleGeocoder) ReverseGeocodeComponents(p *Point, opts ...GeocodeOption) (*Address, error) {
+	res, _, err := g.reverseGeocode(p, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return newAddress(res.FormattedAddress, res.AddressComponents), nil
 }
 
-type AddressComponent struct {
-	LongName  string   `json:"long_name"`
-	ShortName string   `json:"short_name"`
-	Types     []string `json:"types"`
+// Reverse geocodes the pointer to a Point struct and returns the first matching address broken out into its components,
+// with the names of each component, such as the locality and administrative area, in the passed in language, e.g. "de" or "ja".
+// Google falls back to its default language, usually the local one, for names it has no translation of,
+// and for languages it does not support at all, rather than failing the request.
+// Supports the same options as ReverseGeocodeComponents, though the language always takes precedence over WithLanguage.
+func (g *GoogleGeocoder) ReverseGeocodeLocalized(p *Point, lang string, opts ...GeocodeOption) (*Address, error) {
+	// The options are copied first, so that the caller's slice is not appended to.
+	return g.ReverseGeocodeComponents(p, append(append([]GeocodeOption{}, opts...), WithLanguage(lang))...)
 }
@@ -1,10 +1,19 @@
 package geo
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"path"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -30,9 +39,40 @@ func TestSetGoogleClientID(t *testing.T) {
 }
 
 func TestSetGooglePrivateKey(t *testing.T) {
-	SetGooglePrivateKey("foo")
-	if GooglePrivateKey != "foo" {
-		t.Errorf("Mismatched value for GooglePrivateKey.  Expected: 'foo', Actual: %s", GoogleAPIKey)
+	if err := SetGooglePrivateKey(signerTestKey); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if GooglePrivateKey != signerTestKey {
+		t.Errorf("Mismatched value for GooglePrivateKey.  Expected: %s, Actual: %s", signerTestKey, GooglePrivateKey)
+	}
+
+	// A malformed key is reported when it is set, and not set.
+	if err := SetGooglePrivateKey("foo"); err != ErrInvalidGooglePrivateKey {
+		t.Errorf("Mismatched error.  Expected: %v, Actual: %v", ErrInvalidGooglePrivateKey, err)
+	}
+	if GooglePrivateKey != signerTestKey {
+		t.Errorf("Mismatched value for GooglePrivateKey.  Expected: %s, Actual: %s", signerTestKey, GooglePrivateKey)
+	}
+
+	if err := SetGooglePrivateKey(""); err != nil || GooglePrivateKey != "" {
+		t.Errorf("Expected the key to be cleared.  Actual: %q, %v", GooglePrivateKey, err)
+	}
+}
+
+func TestNewGoogleForWorkGeocoder(t *testing.T) {
+	g, err := NewGoogleForWorkGeocoder("clientID", signerTestKey, "chan", WithBaseURL("http://localhost/geocode"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if g.AuthSchema != GoogleMapsForWorkAuth || g.ClientID != "clientID" || g.Channel != "chan" || g.BaseURL != "http://localhost/geocode" || g.signer == nil {
+		t.Errorf("Mismatched GoogleGeocoder configuration: %+v", g)
+	}
+
+	for _, key := range []string{"not base64!", ""} {
+		if _, err := NewGoogleForWorkGeocoder("clientID", key, ""); err != ErrInvalidGooglePrivateKey {
+			t.Errorf("Mismatched error for %q.  Expected: %v, Actual: %v", key, ErrInvalidGooglePrivateKey, err)
+		}
 	}
 }
 
@@ -65,9 +105,10 @@ func TestGoogleReverseGeocodeQueryStr(t *testing.T) {
 }
 
 func TestGoogleFormattedRequestStr(t *testing.T) {
-	// Empty API Key and Client ID
+	// Empty API Key, Client ID, and Channel
 	SetGoogleAPIKey("")
 	SetGoogleClientID("")
+	SetGoogleChannel("")
 	params := "latlng=123.450000,56.780000"
 
 	g := &GoogleGeocoder{}
@@ -145,3 +186,1250 @@ func GetMockResponse(s string) ([]byte, error) {
 
 	return data, nil
 }
+
+// Ensures that the zero value of a GoogleGeocoder uses the explicit default auth schema.
+func TestGoogleAuthDefault(t *testing.T) {
+	g := &GoogleGeocoder{}
+	if g.AuthSchema != GoogleAuthDefault {
+		t.Errorf("Mismatched value for AuthSchema.  Expected: %v, Actual: %v", GoogleAuthDefault, g.AuthSchema)
+	}
+}
+
+func TestNewGoogleGeocoderWithAuth(t *testing.T) {
+	g := NewGoogleGeocoderWithAuth(GoogleMapsAPIToken)
+	if g.AuthSchema != GoogleMapsAPIToken {
+		t.Errorf("Mismatched value for AuthSchema.  Expected: %v, Actual: %v", GoogleMapsAPIToken, g.AuthSchema)
+	}
+}
+
+// Ensures that missing credentials are reported before a request is built for each auth schema.
+func TestGoogleFormattedRequestStrRequiresCredentials(t *testing.T) {
+	SetGoogleAPIKey("")
+	SetGoogleClientID("")
+	SetGooglePrivateKey("")
+	params := "address=New+York"
+
+	g := &GoogleGeocoder{AuthSchema: GoogleAuthDefault}
+	_, err := g.googleFormattedRequestStr(params)
+	if err != nil {
+		t.Errorf("Did not expect the default auth schema to require credentials, but got: %v", err)
+	}
+
+	g.AuthSchema = GoogleMapsAPIToken
+	_, err = g.googleFormattedRequestStr(params)
+	if err != ErrGoogleAPIKeyRequired {
+		t.Errorf("Mismatched error.  Expected: %v, Actual: %v", ErrGoogleAPIKeyRequired, err)
+	}
+
+	g.AuthSchema = GoogleMapsForWorkAuth
+	SetGoogleClientID("clientID")
+	_, err = g.googleFormattedRequestStr(params)
+	if err != ErrGoogleForWorkCredentialsRequired {
+		t.Errorf("Mismatched error.  Expected: %v, Actual: %v", ErrGoogleForWorkCredentialsRequired, err)
+	}
+
+	SetGoogleClientID("")
+	SetGooglePrivateKey("vNIXE0xscrmjlyV-12Nj_BvUPaw=")
+	_, err = g.googleFormattedRequestStr(params)
+	if err != ErrGoogleForWorkCredentialsRequired {
+		t.Errorf("Mismatched error.  Expected: %v, Actual: %v", ErrGoogleForWorkCredentialsRequired, err)
+	}
+
+	SetGooglePrivateKey("")
+}
+
+func TestGoogleBoundsQueryStr(t *testing.T) {
+	sw := &Point{lat: 34.172684, lng: -118.604794}
+	ne := &Point{lat: 34.236144, lng: -118.500938}
+	res := googleBoundsQueryStr(sw, ne)
+
+	expected := "bounds=34.172684%2C-118.604794%7C34.236144%2C-118.500938"
+	if res != expected {
+		t.Errorf("Mismatched query string.  Expected: %s.  Actual: %s", expected, res)
+	}
+}
+
+// Ensures that the bounds parameter is sent along with the address.
+func TestGoogleGeocodeWithinBounds(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		data, _ := GetMockResponse("test/data/google_geocode_success.json")
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	defer SetGoogleGeocodeURL(googleGeocodeURL)
+	SetGoogleGeocodeURL(server.URL)
+
+	g := &GoogleGeocoder{}
+	sw := NewPoint(37.6, -122.4)
+	ne := NewPoint(37.7, -122.3)
+	p, err := g.GeocodeWithinBounds("San Francisco Airport", sw, ne)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if p == nil {
+		t.Fatal("Expected a point, but got nil")
+	}
+
+	if query.Get("bounds") != "37.600000,-122.400000|37.700000,-122.300000" {
+		t.Errorf("Mismatched bounds parameter.  Actual: %s", query.Get("bounds"))
+	}
+
+	if query.Get("address") != "San Francisco Airport" {
+		t.Errorf("Mismatched address parameter.  Actual: %s", query.Get("address"))
+	}
+}
+
+// Ensures that a GoogleGeocoder created with options is configured without touching package globals.
+// Ensures that geocoders created by NewGoogleGeocoder do not fall back to the package level settings,
+// while those created directly still do.
+func TestNewGoogleGeocoderIgnoresGlobals(t *testing.T) {
+	defer SetGoogleAPIKey("")
+	defer SetGoogleChannel("")
+	defer SetGoogleGeocodeURL(googleGeocodeURL)
+	SetGoogleAPIKey("global-key")
+	SetGoogleChannel("global-channel")
+	SetGoogleGeocodeURL("http://localhost/global")
+
+	g := NewGoogleGeocoder(WithForWorkAuth("clientID", signerTestKey, ""))
+	res, err := g.googleFormattedRequestStr("address=New+York")
+	if err != nil {
+		t.Fatalf("Error creating query string: %v", err)
+	}
+
+	if strings.Contains(res, "channel=") || strings.Contains(res, "key=") {
+		t.Errorf("Expected no package level credentials in the query string.  Actual: %s", res)
+	}
+
+	if g.baseURL() != defaultGoogleGeocodeURL {
+		t.Errorf("Mismatched base URL.  Expected: %s.  Actual: %s", defaultGoogleGeocodeURL, g.baseURL())
+	}
+
+	if _, err := NewGoogleGeocoder(WithAPIKey("")).googleFormattedRequestStr("address=New+York"); err != ErrGoogleAPIKeyRequired {
+		t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", ErrGoogleAPIKeyRequired, err)
+	}
+
+	legacy := &GoogleGeocoder{AuthSchema: GoogleMapsAPIToken}
+	if legacy.apiKey() != "global-key" || legacy.channel() != "global-channel" || legacy.baseURL() != "http://localhost/global" {
+		t.Errorf("Expected a GoogleGeocoder created directly to fall back to the package level settings")
+	}
+}
+
+func TestNewGoogleGeocoder(t *testing.T) {
+	SetGoogleAPIKey("")
+	SetGoogleChannel("")
+	client := &http.Client{}
+
+	g := NewGoogleGeocoder(WithAPIKey("instance-key"), WithHTTPClient(client), WithBaseURL("http://localhost/geocode"))
+	if g.AuthSchema != GoogleMapsAPIToken || g.APIKey != "instance-key" || g.HttpClient != client || g.BaseURL != "http://localhost/geocode" {
+		t.Errorf("Mismatched GoogleGeocoder configuration: %+v", g)
+	}
+
+	if GoogleAPIKey != "" {
+		t.Errorf("Expected NewGoogleGeocoder not to modify GoogleAPIKey, but it was: %s", GoogleAPIKey)
+	}
+
+	res, err := g.googleFormattedRequestStr("address=New+York")
+	if err != nil {
+		t.Errorf("Error creating query string: %v", err)
+	}
+
+	expected := "sensor=false&address=New+York&key=instance-key"
+	if res != expected {
+		t.Errorf("Mismatched query string.  Expected: %s.  Actual: %s", expected, res)
+	}
+
+	g = NewGoogleGeocoder(WithForWorkAuth("clientID", "vNIXE0xscrmjlyV-12Nj_BvUPaw=", ""), WithBaseURL("foo"))
+	res, err = g.googleFormattedRequestStr("address=New+York")
+	if err != nil {
+		t.Errorf("Error creating query string: %v", err)
+	}
+
+	expected = "sensor=false&address=New+York&client=clientID&signature=N5nLIw-ytshbH2swgE9pzmZaIjU="
+	if res != expected {
+		t.Errorf("Mismatched query string.  Expected: %s.  Actual: %s", expected, res)
+	}
+	// Extra parameters are signed along with the rest of the request.
+	params := googleGeocodeParams("New York", []GeocodeOption{WithExtraParams(url.Values{"newparam": {"a,b"}})})
+	res, err = g.googleFormattedRequestStr(params)
+	if err != nil {
+		t.Errorf("Error creating query string: %v", err)
+	}
+
+	expected = "sensor=false&address=New+York&newparam=a%2Cb&client=clientID&signature=suWv-zPxt62oGi6l8JWHYEK9L30="
+	if res != expected {
+		t.Errorf("Mismatched query string.  Expected: %s.  Actual: %s", expected, res)
+	}
+}
+
+// Ensures that requests are sent to the geocoder's own base URL.
+func TestGoogleGeocoderBaseURL(t *testing.T) {
+	requested := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+		data, _ := GetMockResponse("test/data/google_geocode_success.json")
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	g := NewGoogleGeocoder(WithBaseURL(server.URL))
+	_, err := g.Geocode("San Francisco Airport")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if !requested {
+		t.Error("Expected the request to be sent to the configured base URL")
+	}
+}
+
+// Starts a server that responds to every request with the contents of the passed in file.
+func mockGoogleServer(filename string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := GetMockResponse(filename)
+		w.Write(data)
+	}))
+}
+
+func TestGoogleReverseGeocodeComponents(t *testing.T) {
+	server := mockGoogleServer("test/data/google_reverse_geocode_success.json")
+	defer server.Close()
+
+	g := NewGoogleGeocoder(WithBaseURL(server.URL))
+	a, err := g.ReverseGeocodeComponents(NewPoint(40.714224, -73.961452))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if a.FormattedAddress != "285 Bedford Avenue, Brooklyn, NY 11211, USA" {
+		t.Errorf("Mismatched formatted address.  Actual: %s", a.FormattedAddress)
+	}
+
+	if a.AdministrativeArea.LongName != "New York" || a.AdministrativeArea.ShortName != "NY" {
+		t.Errorf("Mismatched administrative area.  Actual: %+v", a.AdministrativeArea)
+	}
+
+	if a.Route.ShortName != "Bedford Ave" || a.Locality.LongName != "New York" || a.PostalCode.LongName != "11211" || a.Country.ShortName != "US" {
+		t.Errorf("Mismatched address components: %+v", a)
+	}
+
+	if len(a.Components) != 9 {
+		t.Errorf("Expected all 9 components to be kept, but got %d", len(a.Components))
+	}
+}
+
+func TestGoogleReverseGeocodeLocalized(t *testing.T) {
+	var language string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		language = r.URL.Query().Get("language")
+		w.Write([]byte(`{
+	"results": [{
+		"formatted_address": "Marienplatz, 80331 München, Deutschland",
+		"address_components": [
+			{"long_name": "München", "short_name": "München", "types": ["locality", "political"]},
+			{"long_name": "Bayern", "short_name": "BY", "types": ["administrative_area_level_1", "political"]},
+			{"long_name": "Deutschland", "short_name": "DE", "types": ["country", "political"]}
+		]
+	}],
+	"status": "OK"
+}`))
+	}))
+	defer server.Close()
+
+	// Options with spare capacity are not appended to in place.
+	opts := make([]GeocodeOption, 1, 4)
+	opts[0] = WithLanguage("en")
+
+	g := NewGoogleGeocoder(WithBaseURL(server.URL))
+	a, err := g.ReverseGeocodeLocalized(NewPoint(48.137, 11.575), "de", opts...)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if opts[:2][1] != nil {
+		t.Error("Expected the caller's options not to be appended to")
+	}
+
+	if language != "de" {
+		t.Errorf("Mismatched language.  Expected: de.  Actual: %s", language)
+	}
+
+	if a.Locality.LongName != "München" || a.AdministrativeArea.LongName != "Bayern" || a.Country.LongName != "Deutschland" {
+		t.Errorf("Mismatched localized components: %+v", a)
+	}
+}
+
+// Ensures that the geocoder's default language and region are sent unless a call passes its own.
+func TestGoogleDefaultOptions(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		data, _ := GetMockResponse("test/data/google_geocode_success.json")
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	g := NewGoogleGeocoder(WithBaseURL(server.URL), WithDefaultLanguage("de"), WithDefaultRegion("ch"))
+	cases := []struct {
+		opts     []GeocodeOption
+		language string
+		region   string
+	}{
+		{nil, "de", "ch"},
+		{[]GeocodeOption{WithLanguage("fr")}, "fr", "ch"},
+		{[]GeocodeOption{WithLanguage("fr"), WithRegion("be")}, "fr", "be"},
+	}
+
+	for _, c := range cases {
+		if _, err := g.Geocode("Bern", c.opts...); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if query.Get("language") != c.language || query.Get("region") != c.region {
+			t.Errorf("Mismatched language and region.  Expected: %s, %s.  Actual: %s, %s", c.language, c.region, query.Get("language"), query.Get("region"))
+		}
+	}
+
+	g.ReverseGeocode(NewPoint(46.948, 7.447))
+	if query.Get("language") != "de" {
+		t.Errorf("Mismatched reverse geocoding language.  Expected: de.  Actual: %s", query.Get("language"))
+	}
+
+	// Without defaults, neither parameter is sent.
+	g = NewGoogleGeocoder(WithBaseURL(server.URL))
+	if _, err := g.Geocode("Bern"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, ok := query["language"]; ok {
+		t.Errorf("Expected no language to be sent.  Actual: %s", query.Get("language"))
+	}
+	if _, ok := query["region"]; ok {
+		t.Errorf("Expected no region to be sent.  Actual: %s", query.Get("region"))
+	}
+}
+
+// Starts a server that responds to every request with the passed in body.
+func mockGoogleServerBody(body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+}
+
+func TestGoogleGeocodeDetailed(t *testing.T) {
+	server := mockGoogleServer("test/data/google_geocode_success.json")
+	defer server.Close()
+
+	g := NewGoogleGeocoder(WithBaseURL(server.URL))
+	res, err := g.GeocodeDetailed("San Francisco Airport")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if res.FormattedAddress != "San Francisco Airport (SFO), South Airport Boulevard, San Francisco, CA 94128, USA" {
+		t.Errorf("Mismatched formatted address.  Actual: %s", res.FormattedAddress)
+	}
+
+	if res.Point == nil || res.Point.lat == 0 || res.Point.lng == 0 {
+		t.Errorf("Expected a location, but got %v", res.Point)
+	}
+
+	if res.Viewport == nil || !res.Viewport.SouthWest().Equal(NewPoint(37.604291, -122.4015428)) || !res.Viewport.NorthEast().Equal(NewPoint(37.6397017, -122.3549197)) {
+		t.Errorf("Mismatched viewport: %+v", res.Viewport)
+	}
+}
+
+// Ensures that the types of each result are parsed, for forward and reverse geocoding alike.
+func TestGoogleResultTypes(t *testing.T) {
+	server := mockGoogleServer("test/data/google_geocode_success.json")
+	defer server.Close()
+
+	res, err := NewGoogleGeocoder(WithBaseURL(server.URL)).GeocodeDetailed("San Francisco Airport")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if types := fmt.Sprint(res.Types); types != "[airport transit_station establishment]" {
+		t.Errorf("Mismatched types.  Expected: [airport transit_station establishment].  Actual: %s", types)
+	}
+
+	reverseServer := mockGoogleServer("test/data/google_reverse_geocode_success.json")
+	defer reverseServer.Close()
+
+	res, err = NewGoogleGeocoder(WithBaseURL(reverseServer.URL)).ReverseGeocodeDetailed(NewPoint(37.775, -122.4183333))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if types := fmt.Sprint(res.Types); types != "[street_address]" {
+		t.Errorf("Mismatched types.  Expected: [street_address].  Actual: %s", types)
+	}
+}
+
+// A response with several matches for "Springfield", in Google's order.
+const googleSpringfieldsBody = `{
+	"results": [
+		{"formatted_address": "Springfield, IL, USA", "geometry": {"location": {"lat": 39.7817, "lng": -89.6501}, "location_type": "APPROXIMATE"}},
+		{"formatted_address": "Springfield, MA, USA", "geometry": {"location": {"lat": 42.1015, "lng": -72.5898}, "location_type": "APPROXIMATE"}},
+		{"formatted_address": "Springfield Ave, Summit, NJ, USA", "geometry": {"location": {"lat": 40.7157, "lng": -74.3646}, "location_type": "GEOMETRIC_CENTER"}, "partial_match": true},
+		{"formatted_address": "Springfield, MO, USA", "geometry": {"location": {"lat": 37.2090, "lng": -93.2923}, "location_type": "APPROXIMATE"}}
+	],
+	"status": "OK"
+}`
+
+// Returns the formatted addresses of the passed in results.
+func formattedAddresses(results []*GeocodeResult) []string {
+	addresses := make([]string, len(results))
+	for i, r := range results {
+		addresses[i] = r.FormattedAddress
+	}
+
+	return addresses
+}
+
+func TestGoogleGeocodeAll(t *testing.T) {
+	server := mockGoogleServerBody(googleSpringfieldsBody)
+	defer server.Close()
+
+	g := NewGoogleGeocoder(WithBaseURL(server.URL))
+	results, err := g.GeocodeAll("Springfield")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "[Springfield, IL, USA Springfield, MA, USA Springfield Ave, Summit, NJ, USA Springfield, MO, USA]"
+	if res := fmt.Sprint(formattedAddresses(results)); res != expected {
+		t.Errorf("Mismatched results in provider order.  Expected: %s.  Actual: %s", expected, res)
+	}
+
+	if results[2].LocationType != "GEOMETRIC_CENTER" || !results[2].PartialMatch {
+		t.Errorf("Mismatched location type or partial match.  Actual: %s, %t", results[2].LocationType, results[2].PartialMatch)
+	}
+
+	// Boston is nearest Springfield, MA, then Summit, NJ.
+	results, err = g.GeocodeAll("Springfield", WithSortByDistance(NewPoint(42.3601, -71.0589)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected = "[Springfield, MA, USA Springfield Ave, Summit, NJ, USA Springfield, IL, USA Springfield, MO, USA]"
+	if res := fmt.Sprint(formattedAddresses(results)); res != expected {
+		t.Errorf("Mismatched results by distance.  Expected: %s.  Actual: %s", expected, res)
+	}
+
+	// The partial match sinks to the bottom, and the equally ranked full matches keep Google's order.
+	results, err = g.GeocodeAll("Springfield", WithSortByConfidence())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected = "[Springfield, IL, USA Springfield, MA, USA Springfield, MO, USA Springfield Ave, Summit, NJ, USA]"
+	if res := fmt.Sprint(formattedAddresses(results)); res != expected {
+		t.Errorf("Mismatched results by confidence.  Expected: %s.  Actual: %s", expected, res)
+	}
+
+	zeroServer := mockGoogleServer("test/data/google_geocode_zero_results.json")
+	defer zeroServer.Close()
+
+	if _, err := NewGoogleGeocoder(WithBaseURL(zeroServer.URL)).GeocodeAll("Nowhere"); err != ErrZeroResults {
+		t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", ErrZeroResults, err)
+	}
+}
+
+func TestGoogleGeocodeNearest(t *testing.T) {
+	server := mockGoogleServerBody(googleSpringfieldsBody)
+	defer server.Close()
+
+	g := NewGoogleGeocoder(WithBaseURL(server.URL))
+	cases := []struct {
+		near     *Point
+		expected string
+	}{
+		// Kansas City is nearest Springfield, MO, though Google lists it last.
+		{NewPoint(39.0997, -94.5786), "Springfield, MO, USA"},
+		{NewPoint(40.7128, -74.0060), "Springfield Ave, Summit, NJ, USA"},
+		{nil, "Springfield, IL, USA"},
+	}
+
+	for _, c := range cases {
+		res, err := g.GeocodeNearest("Springfield", c.near, WithSortByConfidence())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if res.FormattedAddress != c.expected {
+			t.Errorf("Mismatched result near %v.  Expected: %s.  Actual: %s", c.near, c.expected, res.FormattedAddress)
+		}
+	}
+
+	// Options with spare capacity are not appended to in place.
+	base := make([]GeocodeOption, 1, 4)
+	base[0] = WithLanguage("en")
+	if _, err := g.GeocodeNearest("Springfield", NewPoint(39.0997, -94.5786), base...); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if base[:2][1] != nil {
+		t.Error("Expected the caller's options not to be appended to")
+	}
+
+	// Equidistant results resolve to Google's order.
+	ties := mockGoogleServerBody(`{
+	"results": [
+		{"formatted_address": "First", "geometry": {"location": {"lat": 10, "lng": 10}}},
+		{"formatted_address": "Second", "geometry": {"location": {"lat": 10, "lng": 10}}}
+	],
+	"status": "OK"
+}`)
+	defer ties.Close()
+
+	res, err := NewGoogleGeocoder(WithBaseURL(ties.URL)).GeocodeNearest("Tie", NewPoint(0, 0))
+	if err != nil || res.FormattedAddress != "First" {
+		t.Errorf("Mismatched result for a tie.  Expected: First.  Actual: %v, %v", res, err)
+	}
+
+	zeroServer := mockGoogleServer("test/data/google_geocode_zero_results.json")
+	defer zeroServer.Close()
+
+	if _, err := NewGoogleGeocoder(WithBaseURL(zeroServer.URL)).GeocodeNearest("Nowhere", NewPoint(0, 0)); err != ErrZeroResults {
+		t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", ErrZeroResults, err)
+	}
+}
+
+func TestGoogleGeocodePostalCode(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Write([]byte(`{"results": [{"formatted_address": "28001 Madrid, Spain", "geometry": {"location": {"lat": 40.4251, "lng": -3.6834}}}], "status": "OK"}`))
+	}))
+	defer server.Close()
+
+	// Options with spare capacity are not appended to in place.
+	opts := make([]GeocodeOption, 1, 4)
+	opts[0] = WithLanguage("es")
+
+	g := NewGoogleGeocoder(WithBaseURL(server.URL))
+	p, err := g.GeocodePostalCode(" 28001 ", "ES", opts...)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if opts[:2][1] != nil {
+		t.Error("Expected the caller's options not to be appended to")
+	}
+
+	if !p.Equal(NewPoint(40.4251, -3.6834)) {
+		t.Errorf("Mismatched point.  Expected: %v.  Actual: %v", NewPoint(40.4251, -3.6834), p)
+	}
+
+	if query.Get("address") != "28001" || query.Get("components") != "postal_code:28001|country:ES" {
+		t.Errorf("Mismatched address or components.  Actual: %s, %s", query.Get("address"), query.Get("components"))
+	}
+
+	for _, args := range [][2]string{{"", "ES"}, {"28001", " "}} {
+		query = nil
+		if _, err := g.GeocodePostalCode(args[0], args[1]); err != ErrPostalCodeQueryIncomplete {
+			t.Errorf("Mismatched error for %q.  Expected: %v.  Actual: %v", args, ErrPostalCodeQueryIncomplete, err)
+		}
+
+		if query != nil {
+			t.Errorf("Expected no request to be made for %q", args)
+		}
+	}
+}
+
+// Ensures that a custom parser can read responses that a proxy has reshaped.
+func TestGoogleResponseParser(t *testing.T) {
+	server := mockGoogleServerBody(`{"match": {"coords": [37.619002, -122.37484], "label": "San Francisco International Airport"}}`)
+	defer server.Close()
+
+	parser := func(data []byte) (*Point, string, error) {
+		res := struct {
+			Match *struct {
+				Coords []float64
+				Label  string
+			}
+		}{}
+
+		if err := json.Unmarshal(data, &res); err != nil {
+			return nil, "", err
+		}
+
+		if res.Match == nil || len(res.Match.Coords) != 2 {
+			return nil, "", ErrZeroResults
+		}
+
+		return NewPoint(res.Match.Coords[0], res.Match.Coords[1]), res.Match.Label, nil
+	}
+
+	g := NewGoogleGeocoder(WithBaseURL(server.URL), WithResponseParser(parser))
+	p, err := g.Geocode("SFO")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !p.Equal(NewPoint(37.619002, -122.37484)) {
+		t.Errorf("Mismatched point.  Expected: %v.  Actual: %v", NewPoint(37.619002, -122.37484), p)
+	}
+
+	address, err := g.ReverseGeocode(p)
+	if err != nil || address != "San Francisco International Airport" {
+		t.Errorf("Mismatched address.  Expected: San Francisco International Airport.  Actual: %s, %v", address, err)
+	}
+
+	all, err := g.GeocodeAll("SFO")
+	if err != nil || len(all) != 1 || !all[0].Point.Equal(p) {
+		t.Errorf("Expected GeocodeAll to return the parsed result.  Actual: %v, %v", all, err)
+	}
+
+	if nearest, err := g.GeocodeNearest("SFO", NewPoint(0, 0)); err != nil || !nearest.Point.Equal(p) {
+		t.Errorf("Expected GeocodeNearest to return the parsed result.  Actual: %v, %v", nearest, err)
+	}
+
+	if res, err := g.ReverseGeocodeDetailed(p); err != nil || res.FormattedAddress != "San Francisco International Airport" {
+		t.Errorf("Expected ReverseGeocodeDetailed to return the parsed address.  Actual: %v, %v", res, err)
+	}
+
+	if err := g.Ping(context.Background()); err != nil {
+		t.Errorf("Unexpected error pinging through the parser: %v", err)
+	}
+
+	if raw, err := g.GeocodeRaw("SFO"); err != nil || !strings.Contains(string(raw), "coords") {
+		t.Errorf("Expected GeocodeRaw to return the reshaped body as it is.  Actual: %s, %v", raw, err)
+	}
+
+	// Address components are only in Google's own responses, so they are not guessed at.
+	if _, err := g.ReverseGeocodeComponents(p); err != ErrResponseParserUnsupported {
+		t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", ErrResponseParserUnsupported, err)
+	}
+
+	if _, err := g.ReverseGeocodeAddressComponents(p); err != ErrResponseParserUnsupported {
+		t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", ErrResponseParserUnsupported, err)
+	}
+
+	empty := mockGoogleServerBody(`{}`)
+	defer empty.Close()
+
+	g.BaseURL = empty.URL
+	if _, err := g.Geocode("Nowhere"); err != ErrZeroResults {
+		t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", ErrZeroResults, err)
+	}
+
+	// A parser that finds no Point for a forward geocoding request does not yield one.
+	g.ResponseParser = func(data []byte) (*Point, string, error) {
+		return nil, "Somewhere", nil
+	}
+	if _, err := g.Geocode("Somewhere"); err != ErrMissingGeometry {
+		t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", ErrMissingGeometry, err)
+	}
+}
+
+// Ensures that results without a location are skipped, rather than geocoding to 0,0.
+func TestGoogleGeocodeMissingGeometry(t *testing.T) {
+	server := mockGoogleServerBody(`{
+	"results": [
+		{"formatted_address": "No Geometry", "geometry": {}},
+		{"formatted_address": "Null Island", "geometry": {"location": {"lat": 0, "lng": 0}}},
+		{"formatted_address": "Springfield, IL, USA", "geometry": {"location": {"lat": 39.7817, "lng": -89.6501}}}
+	],
+	"status": "OK"
+}`)
+	defer server.Close()
+
+	g := NewGoogleGeocoder(WithBaseURL(server.URL))
+	p, err := g.Geocode("Springfield")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !p.Equal(NewPoint(39.7817, -89.6501)) {
+		t.Errorf("Mismatched point.  Expected: %v.  Actual: %v", NewPoint(39.7817, -89.6501), p)
+	}
+
+	results, err := g.GeocodeAll("Springfield")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if res := fmt.Sprint(formattedAddresses(results)); res != "[Springfield, IL, USA]" {
+		t.Errorf("Mismatched results.  Expected: [Springfield, IL, USA].  Actual: %s", res)
+	}
+
+	missing := mockGoogleServerBody(`{"results": [{"formatted_address": "No Geometry"}], "status": "OK"}`)
+	defer missing.Close()
+
+	if p, err := NewGoogleGeocoder(WithBaseURL(missing.URL)).Geocode("Nowhere"); err != ErrMissingGeometry {
+		t.Errorf("Mismatched error.  Expected: %v.  Actual: %v, %v", ErrMissingGeometry, p, err)
+	}
+}
+
+// Ensures that plus codes are parsed from each result, falling back to the response level plus code.
+func TestGoogleGeocodeDetailedPlusCode(t *testing.T) {
+	server := mockGoogleServerBody(`{
+		"results": [{
+			"formatted_address": "1600 Amphitheatre Pkwy, Mountain View, CA 94043, USA",
+			"geometry": {"location": {"lat": 37.4224764, "lng": -122.0842499}},
+			"plus_code": {"compound_code": "CWC8+W5 Mountain View, California, United States", "global_code": "849VCWC8+W5"}
+		}],
+		"status": "OK"
+	}`)
+	defer server.Close()
+
+	g := NewGoogleGeocoder(WithBaseURL(server.URL))
+	res, err := g.GeocodeDetailed("1600 Amphitheatre Parkway")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if res.PlusCode.GlobalCode != "849VCWC8+W5" || res.PlusCode.CompoundCode != "CWC8+W5 Mountain View, California, United States" {
+		t.Errorf("Mismatched plus code: %+v", res.PlusCode)
+	}
+
+	server = mockGoogleServerBody(`{
+		"plus_code": {"compound_code": "P27Q+MC New York, NY, USA", "global_code": "87G8P27Q+MC"},
+		"results": [{
+			"formatted_address": "277 Bedford Ave, Brooklyn, NY 11211, USA",
+			"geometry": {"location": {"lat": 40.7142205, "lng": -73.9612903}}
+		}],
+		"status": "OK"
+	}`)
+	defer server.Close()
+
+	g = NewGoogleGeocoder(WithBaseURL(server.URL))
+	res, err = g.ReverseGeocodeDetailed(NewPoint(40.714224, -73.961452))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if res.PlusCode.GlobalCode != "87G8P27Q+MC" {
+		t.Errorf("Expected the response level plus code to be used, but got: %+v", res.PlusCode)
+	}
+
+	if res.FormattedAddress != "277 Bedford Ave, Brooklyn, NY 11211, USA" {
+		t.Errorf("Mismatched formatted address.  Actual: %s", res.FormattedAddress)
+	}
+}
+
+// Ensures that every option is composed into one query and one signature.
+func TestGoogleGeocodeParamsComposition(t *testing.T) {
+	SetGoogleChannel("")
+	g := NewGoogleGeocoder(
+		WithForWorkAuth("clientID", "vNIXE0xscrmjlyV-12Nj_BvUPaw=", ""),
+		WithBaseURL("https://maps.googleapis.com/maps/api/geocode/json"),
+	)
+
+	bounds := WithBounds(NewPoint(39.8, -4.1), NewPoint(39.9, -3.9))
+	boundsStr := "bounds=39.800000%2C-4.100000%7C39.900000%2C-3.900000"
+
+	cases := []struct {
+		name     string
+		opts     []GeocodeOption
+		expected string
+	}{
+		{"none", nil,
+			"sensor=false&address=Toledo&client=clientID&signature=Jgst4BYTzrj-4o2mF7usy6QSndE="},
+		{"region", []GeocodeOption{WithRegion("es")},
+			"sensor=false&address=Toledo&region=es&client=clientID&signature=Vd0nfDcWpYJDNnISNPgt2idv5pA="},
+		{"bounds", []GeocodeOption{bounds},
+			"sensor=false&address=Toledo&" + boundsStr + "&client=clientID&signature=Rs4O3x3_62AnGCovEzivJxdEDYc="},
+		{"language", []GeocodeOption{WithLanguage("es")},
+			"sensor=false&address=Toledo&language=es&client=clientID&signature=rPwOmK2pEtAvdDK2aJAKHlIoWa0="},
+		{"region and bounds", []GeocodeOption{bounds, WithRegion("es")},
+			"sensor=false&address=Toledo&region=es&" + boundsStr + "&client=clientID&signature=OWOu7amkAXP-1uUnlJR3sbJ8iP8="},
+		{"language, region, and bounds", []GeocodeOption{WithRegion("es"), bounds, WithLanguage("es")},
+			"sensor=false&address=Toledo&language=es&region=es&" + boundsStr + "&client=clientID&signature=KMxREuHzU22tdGY8nFMhuKbVQq8="},
+		{"session token", []GeocodeOption{WithSessionToken("abc-123+x/y")},
+			"sensor=false&address=Toledo&sessiontoken=abc-123%2Bx%2Fy&client=clientID&signature=bY4uH-Wu12e22n2TV2v5yE4tYtU="},
+		{"language and location bias", []GeocodeOption{WithLocationBias(NewPoint(39.86, -4.02), 2000), WithLanguage("es")},
+			"sensor=false&address=Toledo&language=es&locationbias=circle%3A2000%4039.860000%2C-4.020000&client=clientID&signature=V9BygXDsH96xmjHiwM5-GP6Wpy0="},
+	}
+
+	for _, c := range cases {
+		res, err := g.googleFormattedRequestStr(googleGeocodeParams("Toledo", c.opts))
+		if err != nil {
+			t.Errorf("Error creating query string for %s: %v", c.name, err)
+		}
+
+		if res != c.expected {
+			t.Errorf("Mismatched query string for %s.  Expected: %s.  Actual: %s", c.name, c.expected, res)
+		}
+	}
+}
+
+func TestGoogleGeocodeRaw(t *testing.T) {
+	server := mockGoogleServer("test/data/google_geocode_success.json")
+	defer server.Close()
+
+	g := NewGoogleGeocoder(WithBaseURL(server.URL))
+	data, err := g.GeocodeRaw("San Francisco Airport")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Extract a field that the typed results do not model.
+	res := struct {
+		Results []struct {
+			Types []string
+		}
+	}{}
+	err = json.Unmarshal(data, &res)
+	if err != nil {
+		t.Fatalf("Expected the raw response to be valid JSON, but got: %v", err)
+	}
+
+	if len(res.Results) == 0 || len(res.Results[0].Types) == 0 {
+		t.Errorf("Expected the raw response to include the result types, but got: %s", data)
+	}
+
+	// Credentials are still validated before any request is made.
+	g = NewGoogleGeocoder(WithBaseURL(server.URL), WithAPIKey(""))
+	SetGoogleAPIKey("")
+	_, err = g.GeocodeRaw("San Francisco Airport")
+	if err != ErrGoogleAPIKeyRequired {
+		t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", ErrGoogleAPIKeyRequired, err)
+	}
+}
+
+// Ensures that failed statuses are surfaced by the raw and typed methods alike.
+func TestGoogleStatusErrors(t *testing.T) {
+	server := mockGoogleServer("test/data/google_geocode_zero_results.json")
+	defer server.Close()
+
+	g := NewGoogleGeocoder(WithBaseURL(server.URL))
+	_, err := g.GeocodeRaw("Nowhere")
+	if err != ErrZeroResults {
+		t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", ErrZeroResults, err)
+	}
+
+	server = mockGoogleServerBody(`{"results": [], "status": "OVER_QUERY_LIMIT"}`)
+	defer server.Close()
+
+	g = NewGoogleGeocoder(WithBaseURL(server.URL))
+	_, err = g.GeocodeRaw("San Francisco Airport")
+	if statusErr, ok := err.(*GoogleStatusError); !ok || statusErr.Status != "OVER_QUERY_LIMIT" {
+		t.Errorf("Expected a GoogleStatusError for OVER_QUERY_LIMIT, but got: %v", err)
+	}
+
+	_, err = g.Geocode("San Francisco Airport")
+	if _, ok := err.(*GoogleStatusError); !ok {
+		t.Errorf("Expected Geocode to return a GoogleStatusError, but got: %v", err)
+	}
+
+	_, err = g.ReverseGeocode(NewPoint(40.714224, -73.961452))
+	if _, ok := err.(*GoogleStatusError); !ok {
+		t.Errorf("Expected ReverseGeocode to return a GoogleStatusError, but got: %v", err)
+	}
+}
+
+// Ensures that Google's explanation of a failed status is kept on the error.
+func TestGoogleStatusErrorMessage(t *testing.T) {
+	server := mockGoogleServerBody(`{"error_message": "The provided API key is invalid.", "results": [], "status": "REQUEST_DENIED"}`)
+	defer server.Close()
+
+	for _, g := range []*GoogleGeocoder{
+		NewGoogleGeocoder(WithBaseURL(server.URL)),
+		NewGoogleGeocoder(WithBaseURL(server.URL), WithStreamDecode()),
+	} {
+		_, err := g.Geocode("San Francisco Airport")
+		statusErr, ok := err.(*GoogleStatusError)
+		if !ok || statusErr.Status != "REQUEST_DENIED" || statusErr.Message != "The provided API key is invalid." {
+			t.Fatalf("Expected a GoogleStatusError with the error message, but got: %#v", err)
+		}
+
+		expected := "google geocoder: REQUEST_DENIED: The provided API key is invalid."
+		if err.Error() != expected {
+			t.Errorf("Mismatched error.  Expected: %s.  Actual: %s", expected, err.Error())
+		}
+	}
+
+	if res := (&GoogleStatusError{Status: "OVER_QUERY_LIMIT"}).Error(); res != "google geocoder: OVER_QUERY_LIMIT" {
+		t.Errorf("Mismatched error.  Expected: google geocoder: OVER_QUERY_LIMIT.  Actual: %s", res)
+	}
+}
+
+var _ Pinger = &GoogleGeocoder{}
+
+func TestGooglePing(t *testing.T) {
+	server := mockGoogleServer("test/data/google_geocode_success.json")
+	g := NewGoogleGeocoder(WithBaseURL(server.URL))
+	if err := g.Ping(context.Background()); err != nil {
+		t.Errorf("Expected a reachable service to be healthy, but got: %v", err)
+	}
+
+	// Finding nothing still means that the service is up.
+	zeroServer := mockGoogleServer("test/data/google_geocode_zero_results.json")
+	defer zeroServer.Close()
+	if err := NewGoogleGeocoder(WithBaseURL(zeroServer.URL)).Ping(context.Background()); err != nil {
+		t.Errorf("Expected ZERO_RESULTS to be healthy, but got: %v", err)
+	}
+
+	// Auth failures
+	deniedServer := mockGoogleServerBody(`{"results": [], "status": "REQUEST_DENIED"}`)
+	defer deniedServer.Close()
+	err := NewGoogleGeocoder(WithBaseURL(deniedServer.URL)).Ping(context.Background())
+	if statusErr, ok := err.(*GoogleStatusError); !ok || statusErr.Status != "REQUEST_DENIED" {
+		t.Errorf("Expected a REQUEST_DENIED GoogleStatusError, but got: %v", err)
+	}
+
+	SetGoogleAPIKey("")
+	g = NewGoogleGeocoder(WithBaseURL(server.URL), WithAPIKey(""))
+	if err := g.Ping(context.Background()); err != ErrGoogleAPIKeyRequired {
+		t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", ErrGoogleAPIKeyRequired, err)
+	}
+
+	// Network failures
+	server.Close()
+	err = NewGoogleGeocoder(WithBaseURL(server.URL)).Ping(context.Background())
+	if _, ok := err.(*url.Error); !ok {
+		t.Errorf("Expected a network error from an unreachable service, but got: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = NewGoogleGeocoder(WithBaseURL(zeroServer.URL)).Ping(ctx)
+	if err == nil {
+		t.Error("Expected a cancelled context to fail the ping")
+	}
+}
+
+// Returns a server that records the User-Agent of the last request it received.
+func userAgentServer(ua *string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*ua = r.Header.Get("User-Agent")
+		w.Write([]byte(`{"results": [], "status": "ZERO_RESULTS"}`))
+	}))
+}
+
+func TestGoogleUserAgent(t *testing.T) {
+	var ua string
+	server := userAgentServer(&ua)
+	defer server.Close()
+
+	NewGoogleGeocoder(WithBaseURL(server.URL)).Geocode("San Francisco Airport")
+	if ua != UserAgent {
+		t.Errorf("Mismatched User-Agent.  Expected: %s.  Actual: %s", UserAgent, ua)
+	}
+
+	NewGoogleGeocoder(WithBaseURL(server.URL), WithUserAgent("my-service/1.0")).Geocode("San Francisco Airport")
+	if ua != "my-service/1.0" {
+		t.Errorf("Mismatched User-Agent.  Expected: %s.  Actual: %s", "my-service/1.0", ua)
+	}
+}
+
+func TestGoogleGeocodeDetailedPartialMatch(t *testing.T) {
+	server := mockGoogleServerBody(`{
+		"results": [{
+			"formatted_address": "Main St, Springfield, IL, USA",
+			"geometry": {"location": {"lat": 39.8017, "lng": -89.6436}},
+			"partial_match": true
+		}],
+		"status": "OK"
+	}`)
+	defer server.Close()
+
+	res, err := NewGoogleGeocoder(WithBaseURL(server.URL)).GeocodeDetailed("123 Main Stret, Springfeld")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !res.PartialMatch {
+		t.Error("Expected partial_match to be parsed into the result")
+	}
+
+	exactServer := mockGoogleServer("test/data/google_geocode_success.json")
+	defer exactServer.Close()
+
+	res, err = NewGoogleGeocoder(WithBaseURL(exactServer.URL)).GeocodeDetailed("San Francisco Airport")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if res.PartialMatch {
+		t.Error("Expected PartialMatch to default to false when it is absent")
+	}
+}
+
+// Ensures that forward geocoding results are filtered by type on the client.
+func TestGoogleGeocodeDetailedResultType(t *testing.T) {
+	server := mockGoogleServerBody(`{
+		"results": [
+			{"formatted_address": "Springfield, IL, USA", "geometry": {"location": {"lat": 39.7817, "lng": -89.6501}}, "types": ["locality", "political"]},
+			{"formatted_address": "Springfield, IL 62701, USA", "geometry": {"location": {"lat": 39.8017, "lng": -89.6436}}, "types": ["postal_code"]}
+		],
+		"status": "OK"
+	}`)
+	defer server.Close()
+
+	g := NewGoogleGeocoder(WithBaseURL(server.URL))
+	res, err := g.GeocodeDetailed("Springfield", WithResultType("postal_code"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if res.FormattedAddress != "Springfield, IL 62701, USA" || len(res.Types) != 1 || res.Types[0] != "postal_code" {
+		t.Errorf("Expected the postal code result, but got: %+v", res)
+	}
+
+	_, err = g.GeocodeDetailed("Springfield", WithResultType("airport"))
+	if err != ErrZeroResults {
+		t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", ErrZeroResults, err)
+	}
+}
+
+func TestGoogleWithoutRedirects(t *testing.T) {
+	target := mockGoogleServer("test/data/google_geocode_success.json")
+	defer target.Close()
+
+	redirector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL+"?"+r.URL.RawQuery, http.StatusFound)
+	}))
+	defer redirector.Close()
+
+	// Redirects are followed by default.
+	if _, err := NewGoogleGeocoder(WithBaseURL(redirector.URL)).Geocode("San Francisco Airport"); err != nil {
+		t.Errorf("Expected the redirect to be followed, but got: %v", err)
+	}
+
+	client := &http.Client{}
+	g := NewGoogleGeocoder(WithBaseURL(redirector.URL), WithHTTPClient(client), WithoutRedirects())
+	_, err := g.Geocode("San Francisco Airport")
+	if !errors.Is(err, ErrRedirectNotFollowed) {
+		t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", ErrRedirectNotFollowed, err)
+	}
+
+	if client.CheckRedirect != nil {
+		t.Error("Expected the passed in http.Client not to be modified")
+	}
+}
+
+// Ensures that reverse geocoding prefers results of the requested type, and falls back to the first result.
+func TestGoogleReverseGeocodePreferredResultType(t *testing.T) {
+	server := mockGoogleServerBody(`{
+		"results": [
+			{"formatted_address": "Blue Bottle Coffee, 66 Mint St, San Francisco, CA", "geometry": {"location": {"lat": 37.7824, "lng": -122.4077}}, "types": ["establishment", "point_of_interest"]},
+			{"formatted_address": "66 Mint St, San Francisco, CA 94103, USA", "geometry": {"location": {"lat": 37.7824, "lng": -122.4077}}, "types": ["street_address"]}
+		],
+		"status": "OK"
+	}`)
+	defer server.Close()
+
+	g := NewGoogleGeocoder(WithBaseURL(server.URL))
+	p := NewPoint(37.7824, -122.4077)
+
+	address, err := g.ReverseGeocode(p, WithPreferredResultType("street_address", "premise"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if address != "66 Mint St, San Francisco, CA 94103, USA" {
+		t.Errorf("Expected the street address to be preferred, but got: %s", address)
+	}
+
+	address, _ = g.ReverseGeocode(p, WithPreferredResultType("postal_code"))
+	if address != "Blue Bottle Coffee, 66 Mint St, San Francisco, CA" {
+		t.Errorf("Expected the first result when none is of the preferred type, but got: %s", address)
+	}
+
+	res, _ := g.GeocodeDetailed("66 Mint St", WithPreferredResultType("street_address"))
+	if res == nil || res.Types[0] != "street_address" {
+		t.Errorf("Expected forward geocoding to honor the preference too, but got: %+v", res)
+	}
+}
+
+// Ensures that the channel is sent, escaped, with API key authentication too.
+func TestGoogleAPITokenChannel(t *testing.T) {
+	SetGoogleChannel("")
+	g := NewGoogleGeocoder(WithAPIKey("foo"))
+
+	res, err := g.googleFormattedRequestStr("address=New+York")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "sensor=false&address=New+York&key=foo"
+	if res != expected {
+		t.Errorf("Mismatched query string.  Expected: %s.  Actual: %s", expected, res)
+	}
+
+	g.Channel = "web app&beta"
+	res, err = g.googleFormattedRequestStr("address=New+York")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected = "sensor=false&address=New+York&channel=web+app%26beta&key=foo"
+	if res != expected {
+		t.Errorf("Mismatched query string.  Expected: %s.  Actual: %s", expected, res)
+	}
+}
+
+// A RoundTripper used in tests that counts the response bodies that are closed.
+type closeTrackingTransport struct {
+	mu       sync.Mutex
+	requests int
+	closes   int
+}
+
+func (t *closeTrackingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	t.requests++
+	t.mu.Unlock()
+
+	resp.Body = &closeTrackingBody{ReadCloser: resp.Body, transport: t}
+	return resp, nil
+}
+
+type closeTrackingBody struct {
+	io.ReadCloser
+	transport *closeTrackingTransport
+}
+
+func (b *closeTrackingBody) Close() error {
+	b.transport.mu.Lock()
+	b.transport.closes++
+	b.transport.mu.Unlock()
+
+	return b.ReadCloser.Close()
+}
+
+// Ensures that streamed responses decode the same as buffered ones, and that their bodies are closed.
+func TestGoogleMaxResponseBytes(t *testing.T) {
+	server := mockGoogleServer("test/data/google_geocode_success.json")
+	defer server.Close()
+
+	for _, g := range []*GoogleGeocoder{
+		NewGoogleGeocoder(WithBaseURL(server.URL), WithMaxResponseBytes(64)),
+		NewGoogleGeocoder(WithBaseURL(server.URL), WithMaxResponseBytes(64), WithStreamDecode()),
+	} {
+		if _, err := g.Geocode("San Francisco Airport"); err != ErrResponseTooLarge {
+			t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", ErrResponseTooLarge, err)
+		}
+	}
+
+	// The default limit leaves legitimate responses alone.
+	if _, err := NewGoogleGeocoder(WithBaseURL(server.URL)).Geocode("San Francisco Airport"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestGoogleStreamDecode(t *testing.T) {
+	server := mockGoogleServer("test/data/google_geocode_success.json")
+	defer server.Close()
+
+	transport := &closeTrackingTransport{}
+	client := &http.Client{Transport: transport}
+
+	buffered, err := NewGoogleGeocoder(WithBaseURL(server.URL), WithHTTPClient(client)).GeocodeDetailed("San Francisco Airport")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	g := NewGoogleGeocoder(WithBaseURL(server.URL), WithHTTPClient(client), WithStreamDecode())
+	streamed, err := g.GeocodeDetailed("San Francisco Airport")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !streamed.Point.Equal(buffered.Point) || streamed.FormattedAddress != buffered.FormattedAddress {
+		t.Errorf("Mismatched streamed result.  Expected: %+v.  Actual: %+v", buffered, streamed)
+	}
+
+	reverseServer := mockGoogleServer("test/data/google_reverse_geocode_success.json")
+	defer reverseServer.Close()
+
+	g.BaseURL = reverseServer.URL
+	address, err := g.ReverseGeocode(NewPoint(40.714224, -73.961452))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if address != "285 Bedford Avenue, Brooklyn, NY 11211, USA" {
+		t.Errorf("Mismatched address.  Expected: 285 Bedford Avenue, Brooklyn, NY 11211, USA.  Actual: %s", address)
+	}
+
+	statusServer := mockGoogleServerBody(`{"results": [], "status": "OVER_QUERY_LIMIT"}`)
+	defer statusServer.Close()
+
+	g.BaseURL = statusServer.URL
+	_, err = g.Geocode("San Francisco Airport")
+	if statusErr, ok := err.(*GoogleStatusError); !ok || statusErr.Status != "OVER_QUERY_LIMIT" {
+		t.Errorf("Expected a GoogleStatusError for OVER_QUERY_LIMIT, but got: %v", err)
+	}
+
+	malformedServer := mockGoogleServerBody(`{"results": [`)
+	defer malformedServer.Close()
+
+	g.BaseURL = malformedServer.URL
+	if _, err = g.Geocode("San Francisco Airport"); err == nil {
+		t.Error("Expected an error decoding a truncated response")
+	}
+
+	if transport.requests != 5 || transport.closes != transport.requests {
+		t.Errorf("Expected every response body to be closed.  Requests: %d.  Closes: %d", transport.requests, transport.closes)
+	}
+}
+
+// Ensures that response bodies are only kept when asked for, for both forward and reverse geocoding.
+func TestGoogleDebugKeepRaw(t *testing.T) {
+	server := mockGoogleServer("test/data/google_geocode_success.json")
+	defer server.Close()
+
+	res, err := NewGoogleGeocoder(WithBaseURL(server.URL)).GeocodeDetailed("San Francisco Airport")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if res.Raw != nil {
+		t.Errorf("Expected no raw response by default.  Actual: %s", res.Raw)
+	}
+
+	expected, _ := GetMockResponse("test/data/google_geocode_success.json")
+
+	// Keeping the body takes precedence over streaming it.
+	g := NewGoogleGeocoder(WithBaseURL(server.URL), WithDebugKeepRaw(), WithStreamDecode())
+	res, err = g.GeocodeDetailed("San Francisco Airport")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if string(res.Raw) != string(expected) {
+		t.Errorf("Mismatched raw response.  Expected: %s.  Actual: %s", expected, res.Raw)
+	}
+
+	reverseServer := mockGoogleServer("test/data/google_reverse_geocode_success.json")
+	defer reverseServer.Close()
+
+	g.BaseURL = reverseServer.URL
+	res, err = g.ReverseGeocodeDetailed(NewPoint(40.714224, -73.961452))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected, _ = GetMockResponse("test/data/google_reverse_geocode_success.json")
+	if string(res.Raw) != string(expected) {
+		t.Errorf("Mismatched raw response.  Expected: %s.  Actual: %s", expected, res.Raw)
+	}
+}
+
+func BenchmarkGoogleGeocode(b *testing.B) {
+	server := mockGoogleServer("test/data/google_geocode_success.json")
+	defer server.Close()
+
+	g := NewGoogleGeocoder(WithBaseURL(server.URL), WithAPIKey("foo"))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := g.Geocode("San Francisco Airport"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
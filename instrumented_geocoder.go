@@ -0,0 +1,96 @@
+package geo
+
+import (
+	"errors"
+	"time"
+)
+
+// Describes how a geocoding request concluded.
+type GeocodeOutcome int
+
+const (
+	// The provider returned a result.
+	OutcomeSuccess GeocodeOutcome = iota
+
+	// The provider handled the request, but found nothing (ErrZeroResults).
+	OutcomeZeroResults
+
+	// The request failed for any other reason.
+	OutcomeError
+)
+
+// Returns a label suitable for use as a metric dimension.
+func (o GeocodeOutcome) String() string {
+	switch o {
+	case OutcomeSuccess:
+		return "success"
+	case OutcomeZeroResults:
+		return "zero_results"
+	default:
+		return "error"
+	}
+}
+
+// This interface describes a sink for geocoding metrics,
+// such as a set of Prometheus counters and histograms.
+// The operation is either "geocode" or "reverse_geocode".
+type MetricsCollector interface {
+	ObserveGeocode(provider string, operation string, outcome GeocodeOutcome, duration time.Duration)
+}
+
+// A Geocoder that wraps another Geocoder and reports the duration and outcome
+// of every request to a MetricsCollector.  If no Collector is set,
+// requests are passed straight through without being timed.
+type InstrumentedGeocoder struct {
+	// The Geocoder being instrumented.
+	Geocoder Geocoder
+
+	// The provider name reported with each observation, e.g. "google".
+	Provider string
+
+	// Receives an observation for each request.
+	Collector MetricsCollector
+}
+
+// Creates and returns a pointer to a new InstrumentedGeocoder
+// that reports requests to the passed in collector under the passed in provider name.
+func NewInstrumentedGeocoder(g Geocoder, provider string, collector MetricsCollector) *InstrumentedGeocoder {
+	return &InstrumentedGeocoder{Geocoder: g, Provider: provider, Collector: collector}
+}
+
+// Geocodes the passed in query with the wrapped Geocoder and records the request.
+func (i *InstrumentedGeocoder) Geocode(query string, opts ...GeocodeOption) (*Point, error) {
+	if i.Collector == nil {
+		return i.Geocoder.Geocode(query, opts...)
+	}
+
+	start := time.Now()
+	p, err := i.Geocoder.Geocode(query, opts...)
+	i.Collector.ObserveGeocode(i.Provider, "geocode", outcomeFromError(err), time.Since(start))
+
+	return p, err
+}
+
+// Reverse geocodes the passed in Point with the wrapped Geocoder and records the request.
+func (i *InstrumentedGeocoder) ReverseGeocode(p *Point, opts ...GeocodeOption) (string, error) {
+	if i.Collector == nil {
+		return i.Geocoder.ReverseGeocode(p, opts...)
+	}
+
+	start := time.Now()
+	address, err := i.Geocoder.ReverseGeocode(p, opts...)
+	i.Collector.ObserveGeocode(i.Provider, "reverse_geocode", outcomeFromError(err), time.Since(start))
+
+	return address, err
+}
+
+func outcomeFromError(err error) GeocodeOutcome {
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case errors.Is(err, ErrZeroResults):
+		return OutcomeZeroResults
+	default:
+		return OutcomeError
+	}
+}
@@ -0,0 +1,66 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type observation struct {
+	provider  string
+	operation string
+	outcome   GeocodeOutcome
+}
+
+// A MetricsCollector used in tests that keeps every observation.
+type recordingCollector struct {
+	observations []observation
+}
+
+func (r *recordingCollector) ObserveGeocode(provider string, operation string, outcome GeocodeOutcome, duration time.Duration) {
+	r.observations = append(r.observations, observation{provider, operation, outcome})
+}
+
+// Ensures that each request is reported with the correct provider, operation, and outcome.
+func TestInstrumentedGeocoderOutcomes(t *testing.T) {
+	g := &countingGeocoder{}
+	collector := &recordingCollector{}
+	i := NewInstrumentedGeocoder(g, "google", collector)
+
+	i.Geocode("foo")
+	g.err = ErrZeroResults
+	i.Geocode("foo")
+	g.err = fmt.Errorf("geocoding foo: %w", ErrZeroResults)
+	i.Geocode("foo")
+	g.err = errors.New("boom")
+	i.ReverseGeocode(NewPoint(0, 0))
+
+	expected := []observation{
+		{"google", "geocode", OutcomeSuccess},
+		{"google", "geocode", OutcomeZeroResults},
+		{"google", "geocode", OutcomeZeroResults},
+		{"google", "reverse_geocode", OutcomeError},
+	}
+
+	if len(collector.observations) != len(expected) {
+		t.Fatalf("Mismatched observations.  Expected: %v, Actual: %v", expected, collector.observations)
+	}
+
+	for n := range expected {
+		if collector.observations[n] != expected[n] {
+			t.Errorf("Mismatched observation.  Expected: %v, Actual: %v", expected[n], collector.observations[n])
+		}
+	}
+}
+
+// Ensures that an InstrumentedGeocoder without a collector still delegates.
+func TestInstrumentedGeocoderWithoutCollector(t *testing.T) {
+	g := &countingGeocoder{}
+	i := &InstrumentedGeocoder{Geocoder: g}
+
+	p, err := i.Geocode("foo")
+	if err != nil || p == nil {
+		t.Errorf("Expected a result from the wrapped Geocoder, but got %v, %v", p, err)
+	}
+}
@@ -1,11 +1,8 @@
 package geo
 
 import (
-	"bytes"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -13,7 +10,18 @@ import (
 
 // This struct contains all the funcitonality
 // of interacting with the MapQuest Geocoding Service
-type MapQuestGeocoder struct{}
+type MapQuestGeocoder struct {
+	// The User-Agent header sent with each request.  Falls back to the package level UserAgent if empty.
+	UserAgent string
+
+	// Whether or not to refuse redirects, so that requests stay on the configured host.
+	// Requests that are redirected fail with ErrRedirectNotFollowed.
+	DisableRedirects bool
+
+	// The most bytes read of each response body, past which requests fail with ErrResponseTooLarge.
+	// Falls back to DefaultMaxResponseBytes if not positive.
+	MaxResponseBytes int64
+}
 
 type mapQuestGeocodeResponse struct {
 	BoundingBox []string `json:"boundingbox"`
@@ -34,7 +42,7 @@ type mapQuestReverseGeocodeResponse struct {
 
 // This is the error that consumers receive when there
 // are no results from the geocoding request.
-var mapquestZeroResultsError = errors.New("ZERO_RESULTS")
+var mapquestZeroResultsError = ErrZeroResults
 
 var MapquestAPIKey = ""
 
@@ -42,7 +50,7 @@ var MapquestAPIKey = ""
 var mapquestGeocodeURL = "http://open.mapquestapi.com/nominatim/v1"
 
 func SetMapquestAPIKey(newAPIKey string) {
-	MapquestAPIKey = newAPIKey
+	writeConfig(&MapquestAPIKey, newAPIKey)
 }
 
 // Note:  In the next major revision (1.0.0), it is planned
@@ -50,7 +58,7 @@ func SetMapquestAPIKey(newAPIKey string) {
 //        interface and provide versioning of APIs accordingly.
 // Sets the base URL for the MapQuest Geocoding API.
 func SetMapquestGeocodeURL(newGeocodeURL string) {
-	mapquestGeocodeURL = newGeocodeURL
+	writeConfig(&mapquestGeocodeURL, newGeocodeURL)
 }
 
 // Issues a request to the open mapquest api geocoding services using the passed in url query.
@@ -58,19 +66,24 @@ func SetMapquestGeocodeURL(newGeocodeURL string) {
 // Note: Since this is an arbitrary request, you are responsible for passing in your API key if you want one.
 func (g *MapQuestGeocoder) Request(url string) ([]byte, error) {
 	client := &http.Client{}
-	fullUrl := fmt.Sprintf("%s/%s", mapquestGeocodeURL, url)
+	if g.DisableRedirects {
+		client = noRedirectClient(client)
+	}
+	fullUrl := fmt.Sprintf("%s/%s", readConfig(&mapquestGeocodeURL), url)
 
 	// TODO Refactor into an api driver of some sort
 	//      It seems odd that golang-geo should be responsible of versioning of APIs, etc.
 	req, _ := http.NewRequest("GET", fullUrl, nil)
+	req.Header.Set("User-Agent", userAgent(g.UserAgent))
 	resp, requestErr := client.Do(req)
 
 	if requestErr != nil {
 		return nil, requestErr
 	}
+	defer resp.Body.Close()
 
 	// TODO figure out a better typing for response
-	data, dataReadErr := ioutil.ReadAll(resp.Body)
+	data, dataReadErr := readAll(resp.Body, maxResponseBytes(g.MaxResponseBytes))
 
 	if dataReadErr != nil {
 		return nil, dataReadErr
@@ -81,13 +94,33 @@ func (g *MapQuestGeocoder) Request(url string) ([]byte, error) {
 
 // Returns the first point returned by MapQuest's geocoding service or an error
 // if one occurs during the geocoding request.
-func (g *MapQuestGeocoder) Geocode(address string) (*Point, error) {
+// Supports the WithLanguage and WithExtraParams options.
+func (g *MapQuestGeocoder) Geocode(address string, opts ...GeocodeOption) (*Point, error) {
+	res, err := g.GeocodeDetailed(address, opts...)
+	if err == mapquestZeroResultsError {
+		return &Point{}, err
+	}
 
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Point, nil
+}
+
+// Returns a pointer to a new GeocodeResult holding the first match returned by MapQuest's geocoding service,
+// along with its bounding box as the Viewport, and the ReportedPrecision of its coordinates,
+// which MapQuest reports as text with as many decimals as it has confidence in.
+// Supports the same options as Geocode.
+// Returns an error if one occurs during the geocoding request, or if the coordinates cannot be parsed.
+func (g *MapQuestGeocoder) GeocodeDetailed(address string, opts ...GeocodeOption) (*GeocodeResult, error) {
 	queryStr, err := mapquestGeocodeQueryStr(address)
 	if err != nil {
 		return nil, err
 	}
 
+	queryStr = queryStr + mapquestOptionsQueryStr(newGeocodeOptions(opts))
+
 	data, err := g.Request(queryStr)
 	if err != nil {
 		return nil, err
@@ -97,38 +130,64 @@ func (g *MapQuestGeocoder) Geocode(address string) (*Point, error) {
 	json.Unmarshal(data, &res)
 
 	if len(res) == 0 {
-		return &Point{}, mapquestZeroResultsError
+		return nil, mapquestZeroResultsError
 	}
 
-	lat, err := strconv.ParseFloat(res[0].Lat, 64)
+	return newMapQuestGeocodeResult(res[0])
+}
+
+// Creates and returns a pointer to a new GeocodeResult from the passed in match,
+// or returns an error if its coordinates cannot be parsed.
+// The bounding box is left out if it is missing or malformed, since the match is still usable without it.
+func newMapQuestGeocodeResult(r *mapQuestGeocodeResponse) (*GeocodeResult, error) {
+	lat, err := strconv.ParseFloat(r.Lat, 64)
 	if err != nil {
 		return nil, err
 	}
 
-	lng, err := strconv.ParseFloat(res[0].Lng, 64)
+	lng, err := strconv.ParseFloat(r.Lng, 64)
 	if err != nil {
 		return nil, err
 	}
 
-	p := &Point{
-		lat: lat,
-		lng: lng,
+	res := &GeocodeResult{
+		Point:            &Point{lat: lat, lng: lng},
+		FormattedAddress: r.DisplayName,
+		ReportedPrecision: &ReportedPrecision{
+			LatDecimals: reportedDecimals(r.Lat),
+			LngDecimals: reportedDecimals(r.Lng),
+		},
+	}
+
+	// Nominatim orders the bounding box as south, north, west, east.
+	if len(r.BoundingBox) == 4 {
+		bounds := make([]float64, 4)
+		for i, v := range r.BoundingBox {
+			bounds[i], err = strconv.ParseFloat(v, 64)
+			if err != nil {
+				return res, nil
+			}
+		}
+
+		res.Viewport = NewBoundingBox(NewPoint(bounds[0], bounds[2]), NewPoint(bounds[1], bounds[3]))
 	}
 
-	return p, nil
+	return res, nil
 }
 
 func mapquestGeocodeQueryStr(address string) (string, error) {
 	url_safe_query := url.QueryEscape(address)
 
-	var queryBuf = bytes.NewBufferString("search.php?")
-	_, err := queryBuf.WriteString(fmt.Sprintf("q=%s", url_safe_query))
+	queryBuf := getBuffer()
+	defer putBuffer(queryBuf)
+	queryBuf.WriteString("search.php?")
+	_, err := fmt.Fprintf(queryBuf, "q=%s", url_safe_query)
 	if err != nil {
 		return "", err
 	}
 
-	if MapquestAPIKey != "" {
-		_, err := queryBuf.WriteString(fmt.Sprintf("&key=%s", MapquestAPIKey))
+	if apiKey := readConfig(&MapquestAPIKey); apiKey != "" {
+		_, err := fmt.Fprintf(queryBuf, "&key=%s", apiKey)
 		if err != nil {
 			return "", err
 		}
@@ -144,12 +203,15 @@ func mapquestGeocodeQueryStr(address string) (string, error) {
 
 // Returns the first most available address that corresponds to the passed in point.
 // It may also return an error if one occurs during execution.
-func (g *MapQuestGeocoder) ReverseGeocode(p *Point) (string, error) {
+// Supports the WithLanguage and WithExtraParams options.
+func (g *MapQuestGeocoder) ReverseGeocode(p *Point, opts ...GeocodeOption) (string, error) {
 	queryStr, err := mapquestReverseGeocodeQueryStr(p)
 	if err != nil {
 		return "", err
 	}
 
+	queryStr = queryStr + mapquestOptionsQueryStr(newGeocodeOptions(opts))
+
 	data, err := g.Request(queryStr)
 	if err != nil {
 		return "", err
@@ -172,15 +234,17 @@ func (g *MapQuestGeocoder) ReverseGeocode(p *Point) (string, error) {
 }
 
 func mapquestReverseGeocodeQueryStr(p *Point) (string, error) {
-	var queryBuf = bytes.NewBufferString("reverse.php?")
+	queryBuf := getBuffer()
+	defer putBuffer(queryBuf)
+	queryBuf.WriteString("reverse.php?")
 
-	_, err := queryBuf.WriteString(fmt.Sprintf("lat=%f&lng=%f", p.lat, p.lng))
+	_, err := fmt.Fprintf(queryBuf, "lat=%f&lng=%f", p.lat, p.lng)
 	if err != nil {
 		return "", err
 	}
 
-	if MapquestAPIKey != "" {
-		_, err := queryBuf.WriteString(fmt.Sprintf("&key=%s", MapquestAPIKey))
+	if apiKey := readConfig(&MapquestAPIKey); apiKey != "" {
+		_, err := fmt.Fprintf(queryBuf, "&key=%s", apiKey)
 		if err != nil {
 			return "", err
 		}
@@ -193,3 +257,18 @@ func mapquestReverseGeocodeQueryStr(p *Point) (string, error) {
 
 	return queryBuf.String(), err
 }
+
+// Translates the passed in options into MapQuest's parameters.
+// Returns an empty string if none of the options are supported.
+func mapquestOptionsQueryStr(o *geocodeOptions) string {
+	params := ""
+	if o.language != "" {
+		params = fmt.Sprintf("&accept-language=%s", url.QueryEscape(o.language))
+	}
+
+	if len(o.extra) > 0 {
+		params = fmt.Sprintf("%s&%s", params, o.extra.Encode())
+	}
+
+	return params
+}
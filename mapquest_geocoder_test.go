@@ -2,7 +2,11 @@ package geo
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -97,3 +101,83 @@ func TestMapQuestGeocodeFromRequest(t *testing.T) {
 		t.Error(fmt.Sprintf("Expected: [37.62181845, -122.383992092462], Got: [%s, %s]", res[0].Lat, res[0].Lng))
 	}
 }
+
+// Ensures that the bounding box and the precision of the coordinates, as MapQuest reported them, are kept.
+func TestMapQuestGeocodeDetailed(t *testing.T) {
+	server := mockGoogleServer("test/data/mapquest_geocode_success.json")
+	defer server.Close()
+
+	oldURL := mapquestGeocodeURL
+	SetMapquestGeocodeURL(server.URL)
+	defer SetMapquestGeocodeURL(oldURL)
+
+	g := &MapQuestGeocoder{}
+	res, err := g.GeocodeDetailed("San Francisco Airport")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !res.Point.Equal(NewPoint(37.62181845, -122.383992092462)) {
+		t.Errorf("Mismatched point.  Expected: %v.  Actual: %v", NewPoint(37.62181845, -122.383992092462), res.Point)
+	}
+
+	if res.ReportedPrecision == nil || res.ReportedPrecision.LatDecimals != 8 || res.ReportedPrecision.LngDecimals != 12 {
+		t.Errorf("Mismatched precision.  Expected: 8 and 12 decimals.  Actual: %+v", res.ReportedPrecision)
+	}
+
+	if res.Viewport == nil || !res.Viewport.SouthWest().Equal(NewPoint(37.6044343, -122.4026019)) || !res.Viewport.NorthEast().Equal(NewPoint(37.6392026, -122.3549542)) {
+		t.Errorf("Mismatched viewport: %+v", res.Viewport)
+	}
+
+	if !strings.HasPrefix(res.FormattedAddress, "San Francisco International Airport") {
+		t.Errorf("Mismatched formatted address.  Actual: %s", res.FormattedAddress)
+	}
+}
+
+func TestReportedDecimals(t *testing.T) {
+	cases := map[string]int{"52.5170365": 7, "52": 0, "-0.10": 2, "1.25e-3": 2, "": 0}
+	for number, expected := range cases {
+		if decimals := reportedDecimals(number); decimals != expected {
+			t.Errorf("Mismatched decimals of %q.  Expected: %d.  Actual: %d", number, expected, decimals)
+		}
+	}
+}
+
+func TestMapQuestUserAgent(t *testing.T) {
+	var ua string
+	server := userAgentServer(&ua)
+	defer server.Close()
+
+	oldURL := mapquestGeocodeURL
+	SetMapquestGeocodeURL(server.URL)
+	defer SetMapquestGeocodeURL(oldURL)
+
+	g := &MapQuestGeocoder{}
+	g.Geocode("San Francisco Airport")
+	if ua != UserAgent {
+		t.Errorf("Mismatched User-Agent.  Expected: %s.  Actual: %s", UserAgent, ua)
+	}
+
+	g.UserAgent = "my-service/1.0"
+	g.Geocode("San Francisco Airport")
+	if ua != "my-service/1.0" {
+		t.Errorf("Mismatched User-Agent.  Expected: %s.  Actual: %s", "my-service/1.0", ua)
+	}
+}
+
+func TestMapQuestDisableRedirects(t *testing.T) {
+	redirector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://example.invalid/", http.StatusFound)
+	}))
+	defer redirector.Close()
+
+	oldURL := mapquestGeocodeURL
+	SetMapquestGeocodeURL(redirector.URL)
+	defer SetMapquestGeocodeURL(oldURL)
+
+	g := &MapQuestGeocoder{DisableRedirects: true}
+	_, err := g.Geocode("San Francisco Airport")
+	if !errors.Is(err, ErrRedirectNotFollowed) {
+		t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", ErrRedirectNotFollowed, err)
+	}
+}
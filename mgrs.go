@@ -0,0 +1,138 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// This is the error that consumers receive when a string cannot be parsed as an MGRS grid reference.
+var ErrInvalidMGRS = errors.New("invalid MGRS grid reference")
+
+// The latitude band letters, from 80°S northwards in 8° bands.  The last band, X, is 12° tall.
+const mgrsBandLetters = "CDEFGHJKLMNPQRSTUVWX"
+
+// The letters of the 100km squares, which skip I and O.
+// Columns cycle through three sets of eight letters, and rows through twenty letters.
+const (
+	mgrsColumnLetters = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+	mgrsRowLetters    = "ABCDEFGHJKLMNPQRSTUV"
+)
+
+// Matches a grid reference once spaces are removed and it is upper cased:
+// the zone, the latitude band, the 100km square, and the digits of the easting and northing within it.
+var mgrsPattern = regexp.MustCompile(`^(\d{1,2})([C-HJ-NP-X])([A-HJ-NP-Z])([A-HJ-NP-V])(\d*)$`)
+
+// Renders the current Point as a Military Grid Reference System grid reference, such as 18TWL8556911343.
+// The passed in precision is the number of digits given for each of the easting and northing,
+// from 0 (the 100km square alone) to 5 (a 1m square), and is clamped to that range.
+// As is the convention, the digits are truncated rather than rounded, so the reference names the square that contains the point.
+// Returns an empty string for points beyond the 80°S to 84°N range of UTM, which MGRS covers with a polar grid instead.
+func (p *Point) ToMGRS(precision int) string {
+	if p.lat < -80 || p.lat > 84 {
+		return ""
+	}
+
+	if precision < 0 {
+		precision = 0
+	} else if precision > 5 {
+		precision = 5
+	}
+
+	zone, _, easting, northing := p.ToUTM()
+
+	band := int(math.Floor((p.lat + 80) / 8))
+	if band > len(mgrsBandLetters)-1 {
+		// 84°N itself belongs to the last band.
+		band = len(mgrsBandLetters) - 1
+	}
+
+	column := int(math.Floor(easting / 100000))
+	row := int(math.Floor(northing/100000)) % 20
+
+	columnLetter := mgrsColumnLetters[((zone-1)%3)*8+column-1]
+	rowLetter := mgrsRowLetters[(row+mgrsRowOffset(zone))%20]
+
+	divisor := math.Pow(10, float64(5-precision))
+	e := int(math.Floor(math.Mod(easting, 100000) / divisor))
+	n := int(math.Floor(math.Mod(northing, 100000) / divisor))
+
+	ref := fmt.Sprintf("%d%c%c%c", zone, mgrsBandLetters[band], columnLetter, rowLetter)
+	if precision == 0 {
+		return ref
+	}
+
+	return fmt.Sprintf("%s%0*d%0*d", ref, precision, e, precision, n)
+}
+
+// Parses the passed in Military Grid Reference System grid reference, such as 18TWL8556911343 or 18T WL 85569 11343,
+// into the Point at the south-west corner of the square it names.
+// The easting and northing may each have from 0 to 5 digits, but must have the same number.
+// Returns ErrInvalidMGRS if the reference cannot be parsed.
+func ParseMGRS(s string) (*Point, error) {
+	matches := mgrsPattern.FindStringSubmatch(strings.ToUpper(strings.Join(strings.Fields(s), "")))
+	if matches == nil {
+		return nil, ErrInvalidMGRS
+	}
+
+	zone, _ := strconv.Atoi(matches[1])
+	if zone < 1 || zone > 60 {
+		return nil, ErrInvalidMGRS
+	}
+
+	digits := matches[5]
+	if len(digits)%2 != 0 || len(digits) > 10 {
+		return nil, ErrInvalidMGRS
+	}
+
+	band := strings.Index(mgrsBandLetters, matches[2])
+
+	// Each zone only uses the eight column letters of its set.
+	column := strings.Index(mgrsColumnLetters, matches[3]) - ((zone-1)%3)*8 + 1
+	if column < 1 || column > 8 {
+		return nil, ErrInvalidMGRS
+	}
+
+	row := (strings.Index(mgrsRowLetters, matches[4]) - mgrsRowOffset(zone) + 20) % 20
+
+	easting := float64(column) * 100000
+	northing := float64(row) * 100000
+
+	if precision := len(digits) / 2; precision > 0 {
+		multiplier := math.Pow(10, float64(5-precision))
+		e, _ := strconv.Atoi(digits[:precision])
+		n, _ := strconv.Atoi(digits[precision:])
+
+		easting += float64(e) * multiplier
+		northing += float64(n) * multiplier
+	}
+
+	hemisphere := byte('N')
+	if band < strings.Index(mgrsBandLetters, "N") {
+		hemisphere = 'S'
+	}
+
+	// The row letters repeat every 2,000km, so find the repetition that falls within the latitude band.
+	// The band's southern edge is taken at the central meridian, less a margin for the curvature of the parallel
+	// across the zone, which is far smaller than the repetition.
+	bandSouth := NewPoint(float64(band)*8-80, utmCentralMeridian(zone))
+	_, _, _, bandNorthing := bandSouth.ToUTM()
+
+	for northing < bandNorthing-100000 {
+		northing += 2000000
+	}
+
+	return FromUTM(zone, hemisphere, easting, northing), nil
+}
+
+// Returns the offset of the row letters of the passed in zone: the letters of even zones start five rows later.
+func mgrsRowOffset(zone int) int {
+	if zone%2 == 0 {
+		return 5
+	}
+
+	return 0
+}
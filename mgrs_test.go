@@ -0,0 +1,124 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestToMGRS(t *testing.T) {
+	cases := []struct {
+		p         *Point
+		precision int
+		expected  string
+	}{
+		{NewPoint(40.7486, -73.9864), 5, "18TWL8556911343"},
+		{NewPoint(40.7486, -73.9864), 3, "18TWL855113"},
+		{NewPoint(40.7486, -73.9864), 0, "18TWL"},
+		{NewPoint(40.7486, -73.9864), 9, "18TWL8556911343"},
+		{NewPoint(40.7486, -73.9864), -1, "18TWL"},
+		{NewPoint(-33.8688, 151.2093), 5, "56HLH3436850948"},
+		{NewPoint(51.5072, -0.1276), 4, "30UXC99331014"},
+		{NewPoint(60.3913, 5.3221), 2, "32VKN9700"},
+		{NewPoint(78, 8), 1, "31XFG16"},
+		{NewPoint(0, -177), 5, "1NEA0000000000"},
+		{NewPoint(0, 0), 5, "31NAA6602100000"},
+	}
+
+	for _, c := range cases {
+		if res := c.p.ToMGRS(c.precision); res != c.expected {
+			t.Errorf("Mismatched MGRS for %v at precision %d.  Expected: %s.  Actual: %s", c.p, c.precision, c.expected, res)
+		}
+	}
+
+	if res := NewPoint(84.5, 0).ToMGRS(5); res != "" {
+		t.Errorf("Expected no MGRS reference beyond 84°N.  Actual: %s", res)
+	}
+
+	if res := NewPoint(-80.5, 0).ToMGRS(5); res != "" {
+		t.Errorf("Expected no MGRS reference beyond 80°S.  Actual: %s", res)
+	}
+}
+
+func TestParseMGRS(t *testing.T) {
+	cases := []struct {
+		ref      string
+		expected *Point
+	}{
+		{"31NAA6602100000", NewPoint(0, 0)},
+		{"18TWL8556911343", NewPoint(40.7486, -73.9864)},
+		{"18T WL 85569 11343", NewPoint(40.7486, -73.9864)},
+		{"18twl8556911343", NewPoint(40.7486, -73.9864)},
+		{"56HLH3436850948", NewPoint(-33.8688, 151.2093)},
+		{"30UXC9933010142", NewPoint(51.5072, -0.1276)},
+		{"32VKN9735300648", NewPoint(60.3913, 5.3221)},
+		{"31XFG1591463320", NewPoint(78, 8)},
+		{"1NEA0000000000", NewPoint(0, -177)},
+	}
+
+	for _, c := range cases {
+		p, err := ParseMGRS(c.ref)
+		if err != nil {
+			t.Errorf("Unexpected error parsing %s: %v", c.ref, err)
+			continue
+		}
+
+		// The references are truncated to the meter, so the corner of the square is within a couple of meters.
+		if d := p.GreatCircleDistance(c.expected) * 1000; d > 2 {
+			t.Errorf("Mismatched point for %s.  Expected: %v.  Actual: %v, %f meters away", c.ref, c.expected, p, d)
+		}
+	}
+}
+
+// Ensures that each precision names a square around the point, whose south-west corner is parsed back.
+func TestMGRSRoundTrip(t *testing.T) {
+	points := []*Point{
+		NewPoint(40.7486, -73.9864),
+		NewPoint(-33.8688, 151.2093),
+		NewPoint(-79.5, -100.3),
+		NewPoint(83.5, 20.1),
+		NewPoint(-8.1, 45.2),
+		NewPoint(7.9, -45.2),
+		NewPoint(63.5, 10.5),
+	}
+
+	for _, p := range points {
+		for precision := 0; precision <= 5; precision++ {
+			ref := p.ToMGRS(precision)
+			corner, err := ParseMGRS(ref)
+			if err != nil {
+				t.Errorf("Unexpected error parsing %s: %v", ref, err)
+				continue
+			}
+
+			// The corner of the square is no further from the point than the square's diagonal.
+			size := math.Pow(10, float64(5-precision))
+			if d := corner.GreatCircleDistance(p) * 1000; d > size*math.Sqrt2+1 {
+				t.Errorf("Expected the corner of %s to be within %f meters of %v.  Actual: %f", ref, size*math.Sqrt2, p, d)
+			}
+		}
+	}
+}
+
+func TestParseMGRSInvalid(t *testing.T) {
+	refs := []string{
+		"",
+		"18",
+		"18T",
+		"18TW",
+		"0TWL",
+		"61TWL",
+		"18AWL",
+		"18TIL",
+		"18TWW",
+		"18TWL855691134",
+		"18TWL855691134312",
+		"18TAL8556911343",
+		"18TWL85569-11343",
+	}
+
+	for _, ref := range refs {
+		if p, err := ParseMGRS(ref); err != ErrInvalidMGRS {
+			t.Errorf("Expected ErrInvalidMGRS parsing %q.  Actual: %v, %v", ref, p, err)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// A MultiPolygon is a set of Polygons that together describe a single region, such as a service area made up of islands.
+// Its polygons are indexed when it is created, so that Contains only tests the few near a point.
+type MultiPolygon struct {
+	polygons []*Polygon
+	index    *PolygonIndex
+}
+
+// Creates and returns a new pointer to a MultiPolygon composed of the passed in polygons.
+// As with a PolygonIndex, points added to the polygons afterwards are not taken into account by Contains.
+func NewMultiPolygon(polygons []*Polygon) *MultiPolygon {
+	return &MultiPolygon{polygons: polygons, index: NewPolygonIndex(polygons)}
+}
+
+// Returns the polygons of the current MultiPolygon.
+func (m *MultiPolygon) Polygons() []*Polygon {
+	return m.polygons
+}
+
+// Returns whether or not any of the polygons of the current MultiPolygon contains the passed in Point.
+func (m *MultiPolygon) Contains(point *Point) bool {
+	return m.index.Contains(point)
+}
+
+// The number of grid cells along the longer side of the polygons' combined bounding box that Union rasterizes them into.
+const DefaultUnionResolution = 512
+
+// This is the error that consumers receive when none of the polygons passed to Union have at least 3 points.
+var ErrNoPolygons = errors.New("no polygons with at least 3 points")
+
+// This is the error that consumers receive when passing a resolution of less than 1 to UnionWithResolution.
+var ErrInvalidResolution = errors.New("resolution must be at least 1")
+
+// Merges the passed in polygons, such as per-store delivery zones, into the outlines of the area they cover together.
+// Polygons that overlap or share an edge are merged into one, and disjoint ones are kept apart.
+// See UnionWithResolution for how it is computed, and how accurate the result is.
+func Union(polys []*Polygon) (*MultiPolygon, error) {
+	return UnionWithResolution(polys, DefaultUnionResolution)
+}
+
+// Merges the passed in polygons like Union, rasterized into the passed in number of grid cells
+// along the longer side of their combined bounding box.
+//
+// This is a raster union rather than an exact polygon clipping: latitude and longitude are treated as planar coordinates,
+// as Polygon.Contains does, and a grid cell is covered if its center lies inside any of the polygons.
+// The outline of each connected group of covered cells is then traced and simplified to its corners.
+// The outlines are therefore staircases that stay within a cell's diagonal of the true boundary,
+// so accuracy improves with the resolution, while time and memory grow with its square.
+// At the default resolution, the cells of a union 50km across are about 100m wide.
+// Features narrower than a cell may vanish, polygons less than a cell apart may be merged,
+// and cells touching only at a corner are kept apart.  Holes enclosed by the union are filled in.
+//
+// Returns ErrNoPolygons if none of the polygons have at least 3 points, or ErrInvalidResolution if the resolution is less than 1.
+func UnionWithResolution(polys []*Polygon, resolution int) (*MultiPolygon, error) {
+	if resolution < 1 {
+		return nil, ErrInvalidResolution
+	}
+
+	valid := []*Polygon{}
+	for _, poly := range polys {
+		if poly != nil && len(poly.points) >= 3 {
+			valid = append(valid, poly)
+		}
+	}
+
+	if len(valid) == 0 {
+		return nil, ErrNoPolygons
+	}
+
+	grid := newUnionGrid(valid, resolution)
+	for _, poly := range valid {
+		grid.fill(poly)
+	}
+
+	return NewMultiPolygon(grid.outlines()), nil
+}
+
+// A grid of cells covering the combined bounding box of the polygons being merged by Union.
+type unionGrid struct {
+	minLat float64
+	minLng float64
+	cell   float64
+	rows   int
+	cols   int
+	filled []bool
+}
+
+// Creates and returns a pointer to a new, empty unionGrid covering the passed in polygons.
+func newUnionGrid(polys []*Polygon, resolution int) *unionGrid {
+	minLat, maxLat := math.Inf(1), math.Inf(-1)
+	minLng, maxLng := math.Inf(1), math.Inf(-1)
+	for _, poly := range polys {
+		box := poly.BoundingBox()
+		minLat, maxLat = math.Min(minLat, box.sw.lat), math.Max(maxLat, box.ne.lat)
+		minLng, maxLng = math.Min(minLng, box.sw.lng), math.Max(maxLng, box.ne.lng)
+	}
+
+	cell := math.Max(maxLat-minLat, maxLng-minLng) / float64(resolution)
+	if cell == 0 {
+		cell = 1
+	}
+
+	g := &unionGrid{
+		minLat: minLat,
+		minLng: minLng,
+		cell:   cell,
+		rows:   int(math.Max(1, math.Ceil((maxLat-minLat)/cell))),
+		cols:   int(math.Max(1, math.Ceil((maxLng-minLng)/cell))),
+	}
+	g.filled = make([]bool, g.rows*g.cols)
+
+	return g
+}
+
+// Returns whether or not the cell in the passed in row and column is covered.  Cells outside of the grid are not.
+func (g *unionGrid) isFilled(row int, col int) bool {
+	if row < 0 || row >= g.rows || col < 0 || col >= g.cols {
+		return false
+	}
+
+	return g.filled[row*g.cols+col]
+}
+
+// Covers every cell whose center lies inside the passed in Polygon, by the even-odd rule,
+// scanning along each row's center for the longitudes at which the edges of the Polygon cross it.
+func (g *unionGrid) fill(poly *Polygon) {
+	crossings := []float64{}
+	for row := 0; row < g.rows; row++ {
+		lat := g.minLat + (float64(row)+0.5)*g.cell
+
+		crossings = crossings[:0]
+		for i := range poly.points {
+			a, b := poly.points[i], poly.points[(i+1)%len(poly.points)]
+			if (a.lat > lat) != (b.lat > lat) {
+				crossings = append(crossings, a.lng+(lat-a.lat)*(b.lng-a.lng)/(b.lat-a.lat))
+			}
+		}
+		sort.Float64s(crossings)
+
+		for i := 0; i+1 < len(crossings); i += 2 {
+			// The columns whose centers lie between this pair of crossings.
+			start := int(math.Max(0, math.Ceil((crossings[i]-g.minLng)/g.cell-0.5)))
+			end := int(math.Min(float64(g.cols), math.Ceil((crossings[i+1]-g.minLng)/g.cell-0.5)))
+			for col := start; col < end; col++ {
+				g.filled[row*g.cols+col] = true
+			}
+		}
+	}
+}
+
+// A corner of a grid cell, by its column and row.
+type unionVertex struct {
+	x int
+	y int
+}
+
+// A boundary edge of the covered cells, directed so that the covered cell lies on its left.
+type unionEdge struct {
+	from unionVertex
+	to   unionVertex
+	used bool
+}
+
+// Returns the direction of the current edge, as a step along each axis.
+func (e *unionEdge) direction() (int, int) {
+	return e.to.x - e.from.x, e.to.y - e.from.y
+}
+
+// Returns the outlines of each connected group of covered cells as Polygons, counterclockwise,
+// with only the corners of each outline kept.  The outlines of holes are left out, so holes are filled in.
+func (g *unionGrid) outlines() []*Polygon {
+	// Edges are collected in a fixed order, so that outlines are traced deterministically.
+	edges := []*unionEdge{}
+	leaving := map[unionVertex][]*unionEdge{}
+	addEdge := func(x1, y1, x2, y2 int) {
+		e := &unionEdge{from: unionVertex{x1, y1}, to: unionVertex{x2, y2}}
+		edges = append(edges, e)
+		leaving[e.from] = append(leaving[e.from], e)
+	}
+
+	for row := 0; row < g.rows; row++ {
+		for col := 0; col < g.cols; col++ {
+			if !g.isFilled(row, col) {
+				continue
+			}
+
+			if !g.isFilled(row-1, col) {
+				addEdge(col, row, col+1, row)
+			}
+			if !g.isFilled(row, col+1) {
+				addEdge(col+1, row, col+1, row+1)
+			}
+			if !g.isFilled(row+1, col) {
+				addEdge(col+1, row+1, col, row+1)
+			}
+			if !g.isFilled(row, col-1) {
+				addEdge(col, row+1, col, row)
+			}
+		}
+	}
+
+	polygons := []*Polygon{}
+	for _, e := range edges {
+		if e.used {
+			continue
+		}
+
+		ring := traceUnionRing(leaving, e)
+		if unionRingArea(ring) <= 0 {
+			// The clockwise outline of a hole.
+			continue
+		}
+
+		points := make([]*Point, len(ring))
+		for i, v := range ring {
+			points[i] = NewPoint(g.minLat+float64(v.y)*g.cell, g.minLng+float64(v.x)*g.cell)
+		}
+		polygons = append(polygons, NewPolygon(points))
+	}
+
+	return polygons
+}
+
+// Follows the boundary edges from the passed in edge until they lead back to it, marking each one as used,
+// and returns the corners of the ring they form.
+// Where two edges leave a vertex, the one that turns left is followed, which keeps cells that only touch at a corner apart.
+// Every edge thus has exactly one successor, so the edges always lead back to the first one.
+func traceUnionRing(leaving map[unionVertex][]*unionEdge, first *unionEdge) []unionVertex {
+	ring := []*unionEdge{}
+	for e := first; !e.used; {
+		e.used = true
+		ring = append(ring, e)
+
+		dx, dy := e.direction()
+		next := leaving[e.to][0]
+		for _, candidate := range leaving[e.to] {
+			if cdx, cdy := candidate.direction(); cdx == -dy && cdy == dx {
+				next = candidate
+			}
+		}
+		e = next
+	}
+
+	// Only keep the vertices at which the outline turns.
+	vertices := []unionVertex{}
+	for i, e := range ring {
+		dx, dy := e.direction()
+		pdx, pdy := ring[(i+len(ring)-1)%len(ring)].direction()
+		if dx != pdx || dy != pdy {
+			vertices = append(vertices, e.from)
+		}
+	}
+
+	return vertices
+}
+
+// Returns twice the signed area of the passed in ring, which is positive if it runs counterclockwise.
+func unionRingArea(ring []unionVertex) int {
+	area := 0
+	for i, v := range ring {
+		next := ring[(i+1)%len(ring)]
+		area += v.x*next.y - next.x*v.y
+	}
+
+	return area
+}
@@ -0,0 +1,153 @@
+package geo
+
+import (
+	"testing"
+)
+
+// Returns a Polygon of the square with the passed in south-west corner and side (in degrees).
+func squarePolygon(lat float64, lng float64, side float64) *Polygon {
+	return NewPolygon([]*Point{
+		NewPoint(lat, lng),
+		NewPoint(lat, lng+side),
+		NewPoint(lat+side, lng+side),
+		NewPoint(lat+side, lng),
+	})
+}
+
+func TestMultiPolygonContains(t *testing.T) {
+	m := NewMultiPolygon([]*Polygon{squarePolygon(0, 0, 1), squarePolygon(5, 5, 1)})
+
+	if !m.Contains(NewPoint(0.5, 0.5)) || !m.Contains(NewPoint(5.5, 5.5)) {
+		t.Error("Expected the MultiPolygon to contain points in each of its polygons")
+	}
+
+	if m.Contains(NewPoint(3, 3)) {
+		t.Error("Expected the MultiPolygon not to contain a point between its polygons")
+	}
+
+	if len(m.Polygons()) != 2 {
+		t.Errorf("Mismatched number of polygons.  Expected: 2.  Actual: %d", len(m.Polygons()))
+	}
+}
+
+func TestUnion(t *testing.T) {
+	cases := []struct {
+		name     string
+		polys    []*Polygon
+		expected int
+	}{
+		{"overlapping", []*Polygon{squarePolygon(0, 0, 2), squarePolygon(1, 1, 2)}, 1},
+		{"sharing an edge", []*Polygon{squarePolygon(0, 0, 1), squarePolygon(0, 1, 1)}, 1},
+		{"disjoint", []*Polygon{squarePolygon(0, 0, 1), squarePolygon(0, 3, 1)}, 2},
+		{"nested", []*Polygon{squarePolygon(0, 0, 4), squarePolygon(1, 1, 1)}, 1},
+	}
+
+	for _, c := range cases {
+		union, err := Union(c.polys)
+		if err != nil {
+			t.Fatalf("Unexpected error for %s polygons: %v", c.name, err)
+		}
+
+		if len(union.Polygons()) != c.expected {
+			t.Errorf("Mismatched number of polygons for %s polygons.  Expected: %d.  Actual: %d", c.name, c.expected, len(union.Polygons()))
+		}
+
+		// Points well inside of any polygon are inside of the union, and points well outside of all of them are not.
+		for lat := -0.95; lat < 5; lat += 0.1 {
+			for lng := -0.95; lng < 5; lng += 0.1 {
+				p := NewPoint(lat, lng)
+				inside, nearEdge := false, false
+				for _, poly := range c.polys {
+					inside = inside || poly.Contains(p)
+					_, d := poly.NearestBoundaryPoint(p)
+					nearEdge = nearEdge || d < 10
+				}
+
+				if !nearEdge && union.Contains(p) != inside {
+					t.Errorf("Mismatched containment of %v for %s polygons.  Expected: %t.  Actual: %t", p, c.name, inside, union.Contains(p))
+				}
+			}
+		}
+	}
+}
+
+// Ensures that outlines aligned with the grid are traced exactly, with only their corners kept.
+func TestUnionOutline(t *testing.T) {
+	union, err := UnionWithResolution([]*Polygon{squarePolygon(0, 0, 1), squarePolygon(0, 1, 1)}, 4)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []*Point{NewPoint(0, 0), NewPoint(0, 2), NewPoint(1, 2), NewPoint(1, 0)}
+	points := union.Polygons()[0].Points()
+	if len(points) != len(expected) {
+		t.Fatalf("Mismatched outline.  Expected: %v.  Actual: %v", expected, points)
+	}
+
+	for i := range expected {
+		if !points[i].Equal(expected[i]) {
+			t.Errorf("Mismatched corner %d.  Expected: %v.  Actual: %v", i, expected[i], points[i])
+		}
+	}
+}
+
+// Ensures that a ring of zones is merged into a single outline, with the hole it encloses filled in.
+func TestUnionFillsHoles(t *testing.T) {
+	union, err := Union([]*Polygon{
+		NewPolygon([]*Point{NewPoint(0, 0), NewPoint(0, 5), NewPoint(1, 5), NewPoint(1, 0)}),
+		NewPolygon([]*Point{NewPoint(4, 0), NewPoint(4, 5), NewPoint(5, 5), NewPoint(5, 0)}),
+		NewPolygon([]*Point{NewPoint(0, 0), NewPoint(0, 1), NewPoint(5, 1), NewPoint(5, 0)}),
+		NewPolygon([]*Point{NewPoint(0, 4), NewPoint(0, 5), NewPoint(5, 5), NewPoint(5, 4)}),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(union.Polygons()) != 1 {
+		t.Errorf("Mismatched number of polygons.  Expected: 1.  Actual: %d", len(union.Polygons()))
+	}
+
+	if !union.Contains(NewPoint(2.5, 2.5)) {
+		t.Error("Expected the hole in the middle of the ring to be filled in")
+	}
+}
+
+// Ensures that cells touching only at a corner are not merged.
+func TestUnionCornerTouching(t *testing.T) {
+	union, err := UnionWithResolution([]*Polygon{squarePolygon(0, 0, 1), squarePolygon(1, 1, 1)}, 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(union.Polygons()) != 2 {
+		t.Errorf("Mismatched number of polygons.  Expected: 2.  Actual: %d", len(union.Polygons()))
+	}
+}
+
+func TestUnionErrors(t *testing.T) {
+	if _, err := Union(nil); err != ErrNoPolygons {
+		t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", ErrNoPolygons, err)
+	}
+
+	if _, err := Union([]*Polygon{nil, NewPolygon([]*Point{NewPoint(0, 0), NewPoint(1, 1)})}); err != ErrNoPolygons {
+		t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", ErrNoPolygons, err)
+	}
+
+	if _, err := UnionWithResolution([]*Polygon{squarePolygon(0, 0, 1)}, 0); err != ErrInvalidResolution {
+		t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", ErrInvalidResolution, err)
+	}
+}
+
+func BenchmarkUnion(b *testing.B) {
+	zones := []*Polygon{}
+	for i := 0; i < 10; i++ {
+		zones = append(zones, CirclePolygon(NewPoint(40.7, -74+0.05*float64(i)), 5000, 64))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Union(zones); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
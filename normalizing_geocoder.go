@@ -0,0 +1,83 @@
+package geo
+
+import (
+	"strings"
+)
+
+// The abbreviations applied by NormalizeAddress, keyed by the word they replace.
+var addressAbbreviations = map[string]string{
+	"street":    "st",
+	"avenue":    "ave",
+	"road":      "rd",
+	"boulevard": "blvd",
+	"drive":     "dr",
+	"lane":      "ln",
+	"court":     "ct",
+	"place":     "pl",
+	"square":    "sq",
+	"highway":   "hwy",
+	"parkway":   "pkwy",
+	"suite":     "ste",
+	"apartment": "apt",
+	"north":     "n",
+	"south":     "s",
+	"east":      "e",
+	"west":      "w",
+}
+
+// Normalizes an (English) street address by lower casing it, collapsing whitespace,
+// removing periods from the end of words, and abbreviating common street types and directions.
+// For example, both "123 Main Street" and "123  main St." become "123 main st".
+func NormalizeAddress(address string) string {
+	words := strings.Fields(strings.ToLower(address))
+
+	for i, word := range words {
+		// Keep any trailing comma, since it separates parts of the address.
+		suffix := ""
+		if strings.HasSuffix(word, ",") {
+			word, suffix = strings.TrimSuffix(word, ","), ","
+		}
+
+		word = strings.TrimRight(word, ".")
+		if abbreviation, ok := addressAbbreviations[word]; ok {
+			word = abbreviation
+		}
+
+		words[i] = word + suffix
+	}
+
+	return strings.Join(words, " ")
+}
+
+// A Geocoder that normalizes queries before passing them on to another Geocoder,
+// so that trivially different spellings of an address are sent (and cached) identically.
+// Only the query is changed; results are returned from the wrapped Geocoder untouched.
+type NormalizingGeocoder struct {
+	// The Geocoder that receives the normalized queries.
+	Geocoder Geocoder
+
+	// Normalizes each query.  Defaults to NormalizeAddress,
+	// but may be replaced by rules that suit another locale.
+	Normalize func(string) string
+}
+
+// Creates and returns a pointer to a new NormalizingGeocoder
+// that normalizes queries with NormalizeAddress.
+func NewNormalizingGeocoder(g Geocoder) *NormalizingGeocoder {
+	return &NormalizingGeocoder{Geocoder: g, Normalize: NormalizeAddress}
+}
+
+// Geocodes the normalized form of the passed in query with the wrapped Geocoder.
+func (n *NormalizingGeocoder) Geocode(query string, opts ...GeocodeOption) (*Point, error) {
+	normalize := n.Normalize
+	if normalize == nil {
+		normalize = NormalizeAddress
+	}
+
+	return n.Geocoder.Geocode(normalize(query), opts...)
+}
+
+// Reverse geocodes the passed in Point with the wrapped Geocoder.
+func (n *NormalizingGeocoder) ReverseGeocode(p *Point, opts ...GeocodeOption) (string, error) {
+	return n.Geocoder.ReverseGeocode(p, opts...)
+}
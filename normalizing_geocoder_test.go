@@ -0,0 +1,58 @@
+package geo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeAddress(t *testing.T) {
+	cases := map[string]string{
+		"123 Main St.":                     "123 main st",
+		"123   main street":                "123 main st",
+		"1600 Pennsylvania Avenue, NW":     "1600 pennsylvania ave, nw",
+		"  42 North Shore Blvd.,  Suite 5": "42 n shore blvd, ste 5",
+	}
+
+	for address, expected := range cases {
+		if res := NormalizeAddress(address); res != expected {
+			t.Errorf("Mismatched normalized address for %q.  Expected: %q.  Actual: %q", address, expected, res)
+		}
+	}
+}
+
+// A Geocoder used in tests that remembers the last query it received.
+type queryRecordingGeocoder struct {
+	countingGeocoder
+	query string
+}
+
+func (g *queryRecordingGeocoder) Geocode(query string, opts ...GeocodeOption) (*Point, error) {
+	g.query = query
+	return g.countingGeocoder.Geocode(query, opts...)
+}
+
+func TestNormalizingGeocoder(t *testing.T) {
+	g := &queryRecordingGeocoder{}
+	n := NewNormalizingGeocoder(g)
+
+	p, err := n.Geocode("123 Main Street")
+	if err != nil || p == nil {
+		t.Fatalf("Expected a result from the wrapped Geocoder, but got %v, %v", p, err)
+	}
+
+	if g.query != "123 main st" {
+		t.Errorf("Expected the query to be normalized before it was sent, but got %q", g.query)
+	}
+
+	// A custom normalizer replaces the default rules.
+	n.Normalize = strings.ToUpper
+	n.Geocode("calle mayor")
+	if g.query != "CALLE MAYOR" {
+		t.Errorf("Expected the custom normalizer to be used, but got %q", g.query)
+	}
+
+	address, _ := n.ReverseGeocode(NewPoint(0, 0))
+	if address != "San Francisco International Airport" {
+		t.Errorf("Expected the wrapped Geocoder's address to be returned untouched, but got %q", address)
+	}
+}
@@ -1,18 +1,26 @@
 package geo
 
 import (
-	"bytes"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 	"net/url"
 )
 
 // This struct contains all the funcitonality
 // of interacting with the OpenCage Geocoding Service
-type OpenCageGeocoder struct{}
+type OpenCageGeocoder struct {
+	// The User-Agent header sent with each request.  Falls back to the package level UserAgent if empty.
+	UserAgent string
+
+	// Whether or not to refuse redirects, so that requests stay on the configured host.
+	// Requests that are redirected fail with ErrRedirectNotFollowed.
+	DisableRedirects bool
+
+	// The most bytes read of each response body, past which requests fail with ErrResponseTooLarge.
+	// Falls back to DefaultMaxResponseBytes if not positive.
+	MaxResponseBytes int64
+}
 
 // This struct contains selected fields from OpenCage's Geocoding Service response
 type opencageGeocodeResponse struct {
@@ -33,7 +41,7 @@ type opencageReverseGeocodeResponse struct {
 
 // This is the error that consumers receive when there
 // are no results from the geocoding request.
-var opencageZeroResultsError = errors.New("ZERO_RESULTS")
+var opencageZeroResultsError = ErrZeroResults
 
 // This contains the base URL for the Mapquest Geocoder API.
 var opencageGeocodeURL = "http://api.opencagedata.com/geocode/v1/json"
@@ -45,11 +53,11 @@ var OpenCageAPIKey = ""
 //        interface and provide versioning of APIs accordingly.
 // Sets the base URL for the OpenCage Geocoding API.
 func SetOpenCageGeocodeURL(newGeocodeURL string) {
-	opencageGeocodeURL = newGeocodeURL
+	writeConfig(&opencageGeocodeURL, newGeocodeURL)
 }
 
 func SetOpenCageAPIKey(newAPIKey string) {
-	OpenCageAPIKey = newAPIKey
+	writeConfig(&OpenCageAPIKey, newAPIKey)
 }
 
 // Issues a request to the open OpenCage API geocoding services using the passed in url query.
@@ -57,20 +65,25 @@ func SetOpenCageAPIKey(newAPIKey string) {
 // Note: Since this is an arbitrary request, you are responsible for passing in your API key if you want one.
 func (g *OpenCageGeocoder) Request(url string) ([]byte, error) {
 	client := &http.Client{}
-	fullUrl := fmt.Sprintf("%s/%s", opencageGeocodeURL, url)
+	if g.DisableRedirects {
+		client = noRedirectClient(client)
+	}
+	fullUrl := fmt.Sprintf("%s/%s", readConfig(&opencageGeocodeURL), url)
 
 	// TODO Refactor into an api driver of some sort
 	//      It seems odd that golang-geo should be responsible of versioning of APIs, etc.
 	req, _ := http.NewRequest("GET", fullUrl, nil)
+	req.Header.Set("User-Agent", userAgent(g.UserAgent))
 	resp, requestErr := client.Do(req)
 
 	if requestErr != nil {
 		return nil, requestErr
 
 	}
+	defer resp.Body.Close()
 
 	// TODO figure out a better typing for response
-	data, dataReadErr := ioutil.ReadAll(resp.Body)
+	data, dataReadErr := readAll(resp.Body, maxResponseBytes(g.MaxResponseBytes))
 
 	if dataReadErr != nil {
 		return nil, dataReadErr
@@ -81,13 +94,16 @@ func (g *OpenCageGeocoder) Request(url string) ([]byte, error) {
 
 // Returns the first point returned by OpenCage's geocoding service or an error
 // if one occurs during the geocoding request.
-func (g *OpenCageGeocoder) Geocode(address string) (*Point, error) {
+// Supports the WithLanguage and WithExtraParams options.
+func (g *OpenCageGeocoder) Geocode(address string, opts ...GeocodeOption) (*Point, error) {
 
 	queryStr, err := opencageGeocodeQueryStr(address)
 	if err != nil {
 		return nil, err
 	}
 
+	queryStr = queryStr + opencageOptionsQueryStr(newGeocodeOptions(opts))
+
 	data, err := g.Request(queryStr)
 	if err != nil {
 		return nil, err
@@ -114,14 +130,16 @@ func (g *OpenCageGeocoder) Geocode(address string) (*Point, error) {
 func opencageGeocodeQueryStr(address string) (string, error) {
 	url_safe_query := url.QueryEscape(address)
 
-	var queryStr = bytes.NewBufferString("?")
-	_, err := queryStr.WriteString(fmt.Sprintf("q=%s", url_safe_query))
+	queryStr := getBuffer()
+	defer putBuffer(queryStr)
+	queryStr.WriteString("?")
+	_, err := fmt.Fprintf(queryStr, "q=%s", url_safe_query)
 	if err != nil {
 		return "", err
 	}
 
-	if OpenCageAPIKey != "" {
-		_, err := queryStr.WriteString(fmt.Sprintf("&key=%s", OpenCageAPIKey))
+	if apiKey := readConfig(&OpenCageAPIKey); apiKey != "" {
+		_, err := fmt.Fprintf(queryStr, "&key=%s", apiKey)
 		if err != nil {
 			return "", err
 		}
@@ -137,12 +155,15 @@ func opencageGeocodeQueryStr(address string) (string, error) {
 
 // Returns the first most available address that corresponds to the passed in point.
 // It may also return an error if one occurs during execution.
-func (g *OpenCageGeocoder) ReverseGeocode(p *Point) (string, error) {
+// Supports the WithLanguage and WithExtraParams options.
+func (g *OpenCageGeocoder) ReverseGeocode(p *Point, opts ...GeocodeOption) (string, error) {
 	queryStr, err := opencageReverseGeocodeQueryStr(p)
 	if err != nil {
 		return "", err
 	}
 
+	queryStr = queryStr + opencageOptionsQueryStr(newGeocodeOptions(opts))
+
 	data, err := g.Request(queryStr)
 	if err != nil {
 		return "", err
@@ -162,14 +183,16 @@ func (g *OpenCageGeocoder) ReverseGeocode(p *Point) (string, error) {
 }
 
 func opencageReverseGeocodeQueryStr(p *Point) (string, error) {
-	var queryStr = bytes.NewBufferString("?")
-	_, err := queryStr.WriteString(fmt.Sprintf("q=%f,%f", p.lat, p.lng))
+	queryStr := getBuffer()
+	defer putBuffer(queryStr)
+	queryStr.WriteString("?")
+	_, err := fmt.Fprintf(queryStr, "q=%f,%f", p.lat, p.lng)
 	if err != nil {
 		return "", err
 	}
 
-	if OpenCageAPIKey != "" {
-		_, err := queryStr.WriteString(fmt.Sprintf("&key=%s", OpenCageAPIKey))
+	if apiKey := readConfig(&OpenCageAPIKey); apiKey != "" {
+		_, err := fmt.Fprintf(queryStr, "&key=%s", apiKey)
 		if err != nil {
 			return "", err
 		}
@@ -182,3 +205,18 @@ func opencageReverseGeocodeQueryStr(p *Point) (string, error) {
 
 	return queryStr.String(), err
 }
+
+// Translates the passed in options into OpenCage's parameters.
+// Returns an empty string if none of the options are supported.
+func opencageOptionsQueryStr(o *geocodeOptions) string {
+	params := ""
+	if o.language != "" {
+		params = fmt.Sprintf("&language=%s", url.QueryEscape(o.language))
+	}
+
+	if len(o.extra) > 0 {
+		params = fmt.Sprintf("%s&%s", params, o.extra.Encode())
+	}
+
+	return params
+}
@@ -72,3 +72,25 @@ func TestOpencageReverseGeocoderQueryStr(t *testing.T) {
 		t.Errorf(fmt.Sprintf("Mismatched query string.  Expected: %s.  Actual: %s", expected, res))
 	}
 }
+
+func TestOpenCageUserAgent(t *testing.T) {
+	var ua string
+	server := userAgentServer(&ua)
+	defer server.Close()
+
+	oldURL := opencageGeocodeURL
+	SetOpenCageGeocodeURL(server.URL)
+	defer SetOpenCageGeocodeURL(oldURL)
+
+	g := &OpenCageGeocoder{}
+	g.Geocode("San Francisco Airport")
+	if ua != UserAgent {
+		t.Errorf("Mismatched User-Agent.  Expected: %s.  Actual: %s", UserAgent, ua)
+	}
+
+	g.UserAgent = "my-service/1.0"
+	g.Geocode("San Francisco Airport")
+	if ua != "my-service/1.0" {
+		t.Errorf("Mismatched User-Agent.  Expected: %s.  Actual: %s", "my-service/1.0", ua)
+	}
+}
@@ -0,0 +1,67 @@
+package geo
+
+import (
+	"fmt"
+)
+
+// Describes how forgiving the WKT and GeoJSON parsers are of malformed input.
+type ParseMode int
+
+const (
+	// Tolerates trailing commas, unclosed rings, and keyword case differences.
+	ParseLenient ParseMode = iota
+
+	// Rejects anything that is not well formed, including trailing commas,
+	// unclosed or degenerate rings, and coordinates outside of the valid lat/lng ranges.
+	ParseStrict
+)
+
+// This is the error that consumers receive when the WKT or GeoJSON parsers reject their input.
+// Offset is the byte offset of the offending token within the input, and Token is its text when known.
+type ParseError struct {
+	Offset int
+	Token  string
+	Msg    string
+}
+
+func (e *ParseError) Error() string {
+	if e.Token == "" {
+		return fmt.Sprintf("%s at offset %d", e.Msg, e.Offset)
+	}
+
+	return fmt.Sprintf("%s at offset %d near %q", e.Msg, e.Offset, e.Token)
+}
+
+// Returns an error if the passed in ring is not acceptable in the passed in mode.
+// Every ring must have at least three points.  Strict mode also requires
+// the ring to be closed and every coordinate to be within range.
+func validateRing(ring []*Point, mode ParseMode, offset int) error {
+	if len(ring) < 3 {
+		return &ParseError{Offset: offset, Msg: "ring must have at least 3 points"}
+	}
+
+	if mode != ParseStrict {
+		return nil
+	}
+
+	if len(ring) < 4 || !ring[0].Equal(ring[len(ring)-1]) {
+		return &ParseError{Offset: offset, Msg: "ring must be closed with at least 4 points"}
+	}
+
+	for _, p := range ring {
+		if err := validateCoordinate(p, offset); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Returns an error if the passed in Point is outside of the valid lat/lng ranges.
+func validateCoordinate(p *Point, offset int) error {
+	if p.lat < -90 || p.lat > 90 || p.lng < -180 || p.lng > 180 {
+		return &ParseError{Offset: offset, Token: fmt.Sprintf("%v %v", p.lng, p.lat), Msg: "coordinate out of range"}
+	}
+
+	return nil
+}
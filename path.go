@@ -0,0 +1,136 @@
+package geo
+
+import (
+	"math"
+)
+
+// Returns a copy of the passed in path with intermediate points inserted along the great circle
+// between consecutive points, so that no segment is longer than the passed in maximum (in meters).
+// The original points are all kept, and each segment is split into equal parts.
+// This lets long great circle edges be drawn as curves on a flat map.
+// If the maximum is not positive, the path is returned unchanged.
+func DensifyPath(points []*Point, maxSegmentMeters float64) []*Point {
+	res := make([]*Point, 0, len(points))
+	if len(points) == 0 {
+		return res
+	}
+
+	if maxSegmentMeters <= 0 {
+		return append(res, points...)
+	}
+
+	res = append(res, points[0])
+	for i := 1; i < len(points); i++ {
+		p1, p2 := points[i-1], points[i]
+
+		segments := math.Ceil(p1.GreatCircleDistance(p2) * 1000 / maxSegmentMeters)
+		for j := 1.0; j < segments; j++ {
+			res = append(res, Interpolate(p1, p2, j/segments))
+		}
+
+		res = append(res, p2)
+	}
+
+	return res
+}
+
+// Returns points spaced along the great circle from one Point to another at the passed in interval (in meters),
+// such as the positions to report along a flight, starting with the origin and ending with the destination.
+// Unlike DensifyPath, which splits a path into equal segments for drawing it,
+// every point is exactly the interval apart, so the last segment, to the destination, may be shorter.
+// If the interval is not positive, only the two endpoints are returned.
+func Waypoints(from *Point, to *Point, everyMeters float64) []*Point {
+	total := from.GreatCircleDistance(to) * 1000
+	if everyMeters <= 0 || total == 0 {
+		return []*Point{from, to}
+	}
+
+	res := []*Point{from}
+	for i := 1.0; i*everyMeters < total; i++ {
+		res = append(res, Interpolate(from, to, i*everyMeters/total))
+	}
+
+	return append(res, to)
+}
+
+// The number of segments that each semicircular end cap of a buffer is approximated with.
+const bufferCapSegments = 8
+
+// Returns a Polygon that approximately encloses everything within the passed in radius (in meters) of the passed in path,
+// such as a corridor along a route, so that Polygon.Contains can tell whether a point has left it.
+// The outline runs parallel to the path on either side, and is closed with semicircular caps around its ends,
+// each approximated by 8 segments, so the caps fall short of the radius by at most about 2%.
+// At each turn, the sides are extended until they meet.  This includes some points beyond the radius at the outside
+// of the turn, and at turns sharper than 120 degrees the join is cut off at twice the radius.
+// Segments shorter than the radius around sharp turns can make the outline intersect itself.
+// A path of a single point results in a circle.  Points that repeat the previous point are ignored.
+func BufferPath(points []*Point, radiusMeters float64) *Polygon {
+	path := []*Point{}
+	for _, p := range points {
+		if len(path) == 0 || !path[len(path)-1].Equal(p) {
+			path = append(path, p)
+		}
+	}
+
+	poly := NewPolygon([]*Point{})
+	if len(path) == 0 {
+		return poly
+	}
+
+	radius := radiusMeters / 1000
+	if len(path) == 1 {
+		for k := 0; k < 2*bufferCapSegments; k++ {
+			poly.Add(path[0].PointAtDistanceAndBearing(radius, float64(k)*180/bufferCapSegments))
+		}
+
+		return poly
+	}
+
+	last := len(path) - 1
+	startBearing := path[0].BearingTo(path[1])
+	endBearing := path[last-1].BearingTo(path[last])
+
+	// The left side, forwards.
+	for i := range path {
+		bearing, dist := bufferJoin(path, i, radius)
+		poly.Add(path[i].PointAtDistanceAndBearing(dist, bearing-90))
+	}
+
+	addBufferCap(poly, path[last], radius, endBearing-90)
+
+	// The right side, backwards.
+	for i := last; i >= 0; i-- {
+		bearing, dist := bufferJoin(path, i, radius)
+		poly.Add(path[i].PointAtDistanceAndBearing(dist, bearing+90))
+	}
+
+	addBufferCap(poly, path[0], radius, startBearing+90)
+
+	return poly
+}
+
+// Returns the bearing that the sides of a buffer run along at the passed in point of the path,
+// and the distance (in kilometers) that they are offset by there, so that the sides of adjacent segments meet.
+func bufferJoin(path []*Point, i int, radius float64) (float64, float64) {
+	if i == 0 {
+		return path[0].BearingTo(path[1]), radius
+	}
+
+	in := path[i-1].BearingTo(path[i])
+	if i == len(path)-1 {
+		return in, radius
+	}
+
+	out := path[i].BearingTo(path[i+1])
+	turn := math.Mod(out-in+540, 360) - 180
+
+	return in + turn/2, radius / math.Max(0.5, math.Cos(DegreesToRadians(turn/2)))
+}
+
+// Adds the points of a semicircular cap around the passed in center to the passed in Polygon,
+// sweeping clockwise from the passed in bearing, but excluding both ends, which lie on the sides of the buffer.
+func addBufferCap(poly *Polygon, center *Point, radius float64, fromBearing float64) {
+	for k := 1; k < bufferCapSegments; k++ {
+		poly.Add(center.PointAtDistanceAndBearing(radius, fromBearing+float64(k)*180/bufferCapSegments))
+	}
+}
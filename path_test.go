@@ -0,0 +1,178 @@
+package geo
+
+import (
+	"testing"
+)
+
+func TestDensifyPath(t *testing.T) {
+	// A ~5570km great circle from New York to London.
+	nyc := NewPoint(40.7486, -73.9864)
+	london := NewPoint(51.5072, -0.1276)
+	total := nyc.GreatCircleDistance(london) * 1000
+
+	res := DensifyPath([]*Point{nyc, london}, 1000000)
+	if len(res) != 7 {
+		t.Fatalf("Mismatched number of points in the densified path.  Expected: %d.  Actual: %d", 7, len(res))
+	}
+
+	if res[0] != nyc || res[len(res)-1] != london {
+		t.Error("Expected the densified path to start and end with the original points")
+	}
+
+	for i := 1; i < len(res); i++ {
+		d := res[i-1].GreatCircleDistance(res[i]) * 1000
+		if d > 1000000 {
+			t.Errorf("Expected no segment to exceed 1000000 meters, but segment %d is %f", i, d)
+		}
+
+		if expected := total / 6; d < expected-1 || d > expected+1 {
+			t.Errorf("Mismatched length of segment %d.  Expected: %f.  Actual: %f", i, expected, d)
+		}
+	}
+}
+
+func TestWaypoints(t *testing.T) {
+	// A ~5570km great circle from New York to London.
+	nyc := NewPoint(40.7486, -73.9864)
+	london := NewPoint(51.5072, -0.1276)
+	total := nyc.GreatCircleDistance(london) * 1000
+
+	res := Waypoints(nyc, london, 1000000)
+	if len(res) != 7 {
+		t.Fatalf("Mismatched number of waypoints.  Expected: %d.  Actual: %d", 7, len(res))
+	}
+
+	if res[0] != nyc || res[len(res)-1] != london {
+		t.Error("Expected the waypoints to start and end with the endpoints")
+	}
+
+	for i := 1; i < len(res)-1; i++ {
+		if d := res[i-1].GreatCircleDistance(res[i]) * 1000; d < 1000000-1 || d > 1000000+1 {
+			t.Errorf("Mismatched spacing of waypoint %d.  Expected: 1000000.  Actual: %f", i, d)
+		}
+	}
+
+	// The last segment is whatever is left over.
+	expected := total - 5000000
+	if d := res[5].GreatCircleDistance(london) * 1000; d < expected-1 || d > expected+1 {
+		t.Errorf("Mismatched length of the last segment.  Expected: %f.  Actual: %f", expected, d)
+	}
+
+	if res := Waypoints(nyc, london, 0); len(res) != 2 {
+		t.Errorf("Expected only the endpoints without an interval.  Actual: %v", res)
+	}
+
+	if res := Waypoints(nyc, london, 10000000); len(res) != 2 {
+		t.Errorf("Expected only the endpoints for an interval longer than the path.  Actual: %v", res)
+	}
+}
+
+// Ensures that a path that is an exact multiple of the interval does not repeat its destination.
+func TestWaypointsExactMultiple(t *testing.T) {
+	from, to := NewPoint(0, 0), NewPoint(0, 1)
+	total := from.GreatCircleDistance(to) * 1000
+
+	res := Waypoints(from, to, total/4)
+	if len(res) != 5 {
+		t.Errorf("Mismatched number of waypoints.  Expected: 5.  Actual: %d (%v)", len(res), res)
+	}
+}
+
+func TestDensifyPathKeepsShortSegments(t *testing.T) {
+	path := []*Point{
+		NewPoint(40.7486, -73.9864),
+		NewPoint(40.7487, -73.9864),
+		NewPoint(40.7488, -73.9864),
+	}
+
+	res := DensifyPath(path, 100)
+	if len(res) != len(path) {
+		t.Errorf("Mismatched number of points in the densified path.  Expected: %d.  Actual: %d", len(path), len(res))
+	}
+
+	if res := DensifyPath([]*Point{}, 100); len(res) != 0 {
+		t.Errorf("Expected an empty path to stay empty, but got %v", res)
+	}
+
+	if res := DensifyPath(path, 0); len(res) != len(path) {
+		t.Errorf("Expected a non-positive maximum to leave the path unchanged, but got %d points", len(res))
+	}
+}
+
+func TestBufferPath(t *testing.T) {
+	// SFO, and a point 10km to the north
+	start := NewPoint(37.619002, -122.37484)
+	end := start.PointAtDistanceAndBearing(10, 0)
+	mid := start.PointAtDistanceAndBearing(5, 0)
+
+	buffer := BufferPath([]*Point{start, end}, 500)
+
+	cases := []struct {
+		p        *Point
+		contains bool
+	}{
+		{mid, true},
+		{mid.PointAtDistanceAndBearing(0.3, 90), true},
+		{mid.PointAtDistanceAndBearing(0.3, 270), true},
+		{mid.PointAtDistanceAndBearing(0.7, 90), false},
+		{mid.PointAtDistanceAndBearing(0.7, 270), false},
+
+		// Within the caps
+		{end.PointAtDistanceAndBearing(0.45, 0), true},
+		{end.PointAtDistanceAndBearing(0.45, 45), true},
+		{start.PointAtDistanceAndBearing(0.45, 180), true},
+		{end.PointAtDistanceAndBearing(0.6, 0), false},
+		{start.PointAtDistanceAndBearing(0.6, 225), false},
+	}
+
+	for _, c := range cases {
+		if res := buffer.Contains(c.p); res != c.contains {
+			t.Errorf("Mismatched containment of %v.  Expected: %t.  Actual: %t", c.p, c.contains, res)
+		}
+	}
+}
+
+func TestBufferPathTurn(t *testing.T) {
+	// 5km north, then 5km east
+	start := NewPoint(37.619002, -122.37484)
+	corner := start.PointAtDistanceAndBearing(5, 0)
+	end := corner.PointAtDistanceAndBearing(5, 90)
+
+	buffer := BufferPath([]*Point{start, corner, corner, end}, 500)
+
+	cases := []struct {
+		p        *Point
+		contains bool
+	}{
+		{corner, true},
+		{corner.PointAtDistanceAndBearing(0.45, 315), true},
+		{corner.PointAtDistanceAndBearing(0.3, 135), true},
+		{corner.PointAtDistanceAndBearing(1, 315), false},
+		{corner.PointAtDistanceAndBearing(1, 135), false},
+		{start.PointAtDistanceAndBearing(2.5, 0).PointAtDistanceAndBearing(0.3, 90), true},
+		{corner.PointAtDistanceAndBearing(2.5, 90).PointAtDistanceAndBearing(0.3, 180), true},
+	}
+
+	for _, c := range cases {
+		if res := buffer.Contains(c.p); res != c.contains {
+			t.Errorf("Mismatched containment of %v.  Expected: %t.  Actual: %t", c.p, c.contains, res)
+		}
+	}
+}
+
+func TestBufferPathSinglePoint(t *testing.T) {
+	center := NewPoint(37.619002, -122.37484)
+	buffer := BufferPath([]*Point{center}, 1000)
+
+	if !buffer.Contains(center.PointAtDistanceAndBearing(0.9, 30)) {
+		t.Error("Expected the buffer around a single point to contain points within the radius")
+	}
+
+	if buffer.Contains(center.PointAtDistanceAndBearing(1.1, 30)) {
+		t.Error("Expected the buffer around a single point not to contain points beyond the radius")
+	}
+
+	if len(BufferPath([]*Point{}, 1000).Points()) != 0 {
+		t.Error("Expected the buffer around an empty path to be empty")
+	}
+}
@@ -0,0 +1,213 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+// Implements encoding and decoding of Open Location Codes (plus codes).
+// Original specification: https://github.com/google/open-location-code/blob/master/docs/specification.md
+const (
+	plusCodeAlphabet  = "23456789CFGHJMPQRVWX"
+	plusCodeSeparator = '+'
+	plusCodePadding   = '0'
+
+	// The position of the separator in a full code.
+	plusCodeSeparatorPosition = 8
+
+	// Codes longer than this add a single grid digit at a time instead of a pair.
+	plusCodePairLength = 10
+	plusCodeMaxLength  = 15
+	plusCodeGridLength = plusCodeMaxLength - plusCodePairLength
+
+	plusCodeBase     = 20
+	plusCodeGridRows = 5
+	plusCodeGridCols = 4
+
+	// The number of pair code units in a degree.
+	plusCodePairPrecision = plusCodeBase * plusCodeBase * plusCodeBase
+
+	// The number of grid code units in a pair code unit.
+	plusCodeGridLatPrecision = plusCodeGridRows * plusCodeGridRows * plusCodeGridRows * plusCodeGridRows * plusCodeGridRows
+	plusCodeGridLngPrecision = plusCodeGridCols * plusCodeGridCols * plusCodeGridCols * plusCodeGridCols * plusCodeGridCols
+
+	// The number of units in a degree at the longest code length.
+	plusCodeFinalLatPrecision = plusCodePairPrecision * plusCodeGridLatPrecision
+	plusCodeFinalLngPrecision = plusCodePairPrecision * plusCodeGridLngPrecision
+)
+
+// This is the error that consumers receive when decoding a string that is not a valid full plus code.
+var ErrInvalidPlusCode = errors.New("invalid plus code")
+
+// Returns the Open Location Code (plus code) of the passed in Point with the passed in number of digits.
+// The length is clamped to between 2 and 15 digits, and is rounded up to an even number below 10.
+// Codes shorter than 8 digits are padded with zeros, e.g. "7FG49Q00+".
+func EncodePlusCode(p *Point, codeLength int) string {
+	if codeLength < 2 {
+		codeLength = 2
+	}
+
+	if codeLength < plusCodePairLength && codeLength%2 == 1 {
+		codeLength++
+	}
+
+	if codeLength > plusCodeMaxLength {
+		codeLength = plusCodeMaxLength
+	}
+
+	lat := math.Max(-90, math.Min(90, p.lat))
+	lng := normalizeLongitude(p.lng)
+
+	// The north pole belongs to the cell below it, since cells contain their southern edge.
+	if lat == 90 {
+		lat -= plusCodeLatitudePrecision(codeLength)
+	}
+
+	// Rounding before truncating avoids floating point representation errors at cell edges.
+	latVal := int64(math.Floor(math.Round((lat+90)*plusCodeFinalLatPrecision*1e6) / 1e6))
+	lngVal := int64(math.Floor(math.Round((lng+180)*plusCodeFinalLngPrecision*1e6) / 1e6))
+
+	digits := make([]byte, plusCodeMaxLength)
+
+	for i := plusCodeMaxLength - 1; i >= plusCodePairLength; i-- {
+		row := latVal % plusCodeGridRows
+		col := lngVal % plusCodeGridCols
+		digits[i] = plusCodeAlphabet[row*plusCodeGridCols+col]
+		latVal /= plusCodeGridRows
+		lngVal /= plusCodeGridCols
+	}
+
+	for i := plusCodePairLength - 1; i >= 0; i -= 2 {
+		digits[i] = plusCodeAlphabet[lngVal%plusCodeBase]
+		digits[i-1] = plusCodeAlphabet[latVal%plusCodeBase]
+		latVal /= plusCodeBase
+		lngVal /= plusCodeBase
+	}
+
+	code := string(digits[:codeLength])
+	if codeLength < plusCodeSeparatorPosition {
+		code += strings.Repeat(string(plusCodePadding), plusCodeSeparatorPosition-codeLength)
+	}
+
+	return code[:plusCodeSeparatorPosition] + string(plusCodeSeparator) + code[plusCodeSeparatorPosition:]
+}
+
+// Returns the Point at the center of the area described by the passed in full plus code,
+// or ErrInvalidPlusCode if the code is malformed or is a short code.
+func DecodePlusCode(code string) (*Point, error) {
+	code = strings.ToUpper(code)
+	if !isFullPlusCode(code) {
+		return nil, ErrInvalidPlusCode
+	}
+
+	code = strings.Replace(code, string(plusCodeSeparator), "", 1)
+	code = strings.TrimRight(code, string(plusCodePadding))
+	if len(code) > plusCodeMaxLength {
+		code = code[:plusCodeMaxLength]
+	}
+
+	pairLength := len(code)
+	if pairLength > plusCodePairLength {
+		pairLength = plusCodePairLength
+	}
+
+	// Accumulate the south-west corner in integer units to avoid floating point drift.
+	var latVal, lngVal int64
+	placeValue := int64(plusCodeBase * plusCodeBase * plusCodeBase * plusCodeBase)
+	for i := 0; i < pairLength; i += 2 {
+		latVal += int64(strings.IndexByte(plusCodeAlphabet, code[i])) * placeValue
+		lngVal += int64(strings.IndexByte(plusCodeAlphabet, code[i+1])) * placeValue
+		if i < pairLength-2 {
+			placeValue /= plusCodeBase
+		}
+	}
+
+	latSize := float64(placeValue) / plusCodePairPrecision
+	lngSize := float64(placeValue) / plusCodePairPrecision
+	lat := float64(latVal) / plusCodePairPrecision
+	lng := float64(lngVal) / plusCodePairPrecision
+
+	if len(code) > plusCodePairLength {
+		var gridLatVal, gridLngVal int64
+		rowValue := int64(plusCodeGridLatPrecision / plusCodeGridRows)
+		colValue := int64(plusCodeGridLngPrecision / plusCodeGridCols)
+
+		for i := plusCodePairLength; i < len(code); i++ {
+			digit := int64(strings.IndexByte(plusCodeAlphabet, code[i]))
+			gridLatVal += (digit / plusCodeGridCols) * rowValue
+			gridLngVal += (digit % plusCodeGridCols) * colValue
+			if i < len(code)-1 {
+				rowValue /= plusCodeGridRows
+				colValue /= plusCodeGridCols
+			}
+		}
+
+		latSize = float64(rowValue) / plusCodeFinalLatPrecision
+		lngSize = float64(colValue) / plusCodeFinalLngPrecision
+		lat += float64(gridLatVal) / plusCodeFinalLatPrecision
+		lng += float64(gridLngVal) / plusCodeFinalLngPrecision
+	}
+
+	centerLat := math.Min(90, lat-90+latSize/2)
+	centerLng := math.Min(180, lng-180+lngSize/2)
+
+	return NewPoint(centerLat, centerLng), nil
+}
+
+// Returns the height (in degrees) of the area described by a code of the passed in length.
+func plusCodeLatitudePrecision(codeLength int) float64 {
+	if codeLength <= plusCodePairLength {
+		return math.Pow(plusCodeBase, float64(2-codeLength/2))
+	}
+
+	return math.Pow(plusCodeBase, -3) / math.Pow(plusCodeGridRows, float64(codeLength-plusCodePairLength))
+}
+
+// Returns whether or not the passed in upper case string is a valid full plus code.
+func isFullPlusCode(code string) bool {
+	if strings.Count(code, string(plusCodeSeparator)) != 1 || strings.IndexByte(code, plusCodeSeparator) != plusCodeSeparatorPosition {
+		return false
+	}
+
+	// A single digit after the separator is not allowed.
+	if len(code) == plusCodeSeparatorPosition+2 {
+		return false
+	}
+
+	padStart := strings.IndexByte(code, plusCodePadding)
+	if padStart >= 0 {
+		// Padding must come in pairs, fill out the rest of the code up to the separator,
+		// and not be followed by any digits.
+		if padStart == 0 || padStart%2 == 1 || len(code) > plusCodeSeparatorPosition+1 {
+			return false
+		}
+
+		if strings.TrimRight(code[:plusCodeSeparatorPosition], string(plusCodePadding)) != code[:padStart] {
+			return false
+		}
+	} else {
+		padStart = len(code)
+	}
+
+	for i := 0; i < padStart; i++ {
+		if i == plusCodeSeparatorPosition {
+			continue
+		}
+
+		if strings.IndexByte(plusCodeAlphabet, code[i]) < 0 {
+			return false
+		}
+	}
+
+	// The first latitude digit can only describe up to 180 degrees, and the first longitude digit up to 360.
+	if strings.IndexByte(plusCodeAlphabet, code[0])*plusCodeBase >= 180 {
+		return false
+	}
+
+	if strings.IndexByte(plusCodeAlphabet, code[1])*plusCodeBase >= 360 {
+		return false
+	}
+
+	return true
+}
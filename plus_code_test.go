@@ -0,0 +1,103 @@
+package geo
+
+import (
+	"testing"
+)
+
+// Reference values from the Open Location Code test data.
+func TestEncodePlusCode(t *testing.T) {
+	cases := []struct {
+		lat, lng float64
+		length   int
+		expected string
+	}{
+		{20.375, 2.775, 6, "7FG49Q00+"},
+		{20.3700625, 2.7821875, 10, "7FG49QCJ+2V"},
+		{20.3701125, 2.782234375, 11, "7FG49QCJ+2VX"},
+		{47.0000625, 8.0000625, 10, "8FVC2222+22"},
+		{-41.2730625, 174.7859375, 10, "4VCPPQGP+Q9"},
+		{0.5, -179.5, 4, "62G20000+"},
+		{90, 1, 4, "CFX30000+"},
+		{1, 180, 4, "62H20000+"},
+	}
+
+	for _, c := range cases {
+		res := EncodePlusCode(NewPoint(c.lat, c.lng), c.length)
+		if res != c.expected {
+			t.Errorf("Mismatched plus code for [%f, %f].  Expected: %s.  Actual: %s", c.lat, c.lng, c.expected, res)
+		}
+	}
+}
+
+func TestDecodePlusCode(t *testing.T) {
+	p, err := DecodePlusCode("7FG49Q00+")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if RoundFloat(p.lat, 7) != 20.375 || RoundFloat(p.lng, 7) != 2.775 {
+		t.Errorf("Expected the center of the code area, but got %v", p)
+	}
+
+	p, err = DecodePlusCode("8fvc2222+22")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if RoundFloat(p.lat, 9) != 47.0000625 || RoundFloat(p.lng, 9) != 8.0000625 {
+		t.Errorf("Expected the center of the code area, but got %v", p)
+	}
+}
+
+// Ensures that decoding an encoded point lands within the resolution of the code.
+func TestPlusCodeRoundTrip(t *testing.T) {
+	points := []*Point{
+		NewPoint(37.619002, -122.37484),
+		NewPoint(-33.866, 151.209),
+		NewPoint(0, 0),
+		NewPoint(64.1466, -21.9426),
+	}
+
+	for _, p := range points {
+		for _, length := range []int{8, 10, 11, 12, 15} {
+			code := EncodePlusCode(p, length)
+			decoded, err := DecodePlusCode(code)
+			if err != nil {
+				t.Fatalf("Unexpected error decoding %s: %v", code, err)
+			}
+
+			// Half of the code area's diagonal is the furthest the center can be from the original point.
+			maxLatError := plusCodeLatitudePrecision(length)/2 + 1e-9
+			if diff := decoded.lat - p.lat; diff > maxLatError || diff < -maxLatError {
+				t.Errorf("Decoded %s to %v, which is too far from %v", code, decoded, p)
+			}
+
+			if EncodePlusCode(decoded, length) != code {
+				t.Errorf("Expected the center of %s to encode to the same code, but got %s", code, EncodePlusCode(decoded, length))
+			}
+		}
+	}
+}
+
+func TestDecodeInvalidPlusCode(t *testing.T) {
+	invalid := []string{
+		"",
+		"7FG49Q",
+		"7FG49QCJ2V",
+		"7FG49QCJ+2+",
+		"9QCJ+2VX",
+		"7FG49QCJ+2",
+		"7FG4900Q+",
+		"7FG49Q0+",
+		"7FG49Q00+2V",
+		"7FG49QCJ+AB",
+		"ZFG49QCJ+2V",
+		"7ZG49QCJ+2V",
+	}
+
+	for _, code := range invalid {
+		if _, err := DecodePlusCode(code); err != ErrInvalidPlusCode {
+			t.Errorf("Expected %q to be an invalid plus code, but got: %v", code, err)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"math/rand"
 )
 
 // Represents a Physical Point in geographic notation [lat, lng].
@@ -19,6 +20,16 @@ const (
 	EARTH_RADIUS = 6371
 )
 
+// Converts the passed in angle from degrees to radians.
+func DegreesToRadians(degrees float64) float64 {
+	return degrees * math.Pi / 180.0
+}
+
+// Converts the passed in angle from radians to degrees.
+func RadiansToDegrees(radians float64) float64 {
+	return radians * 180.0 / math.Pi
+}
+
 // Returns a new Point populated by the passed in latitude (lat) and longitude (lng) values.
 func NewPoint(lat float64, lng float64) *Point {
 	return &Point{lat: lat, lng: lng}
@@ -34,6 +45,16 @@ func (p *Point) Lng() float64 {
 	return p.lng
 }
 
+// Returns Point p's latitude in radians.
+func (p *Point) LatRadians() float64 {
+	return DegreesToRadians(p.lat)
+}
+
+// Returns Point p's longitude in radians.
+func (p *Point) LngRadians() float64 {
+	return DegreesToRadians(p.lng)
+}
+
 // Returns a Point populated with the lat and lng coordinates
 // by transposing the origin point the passed in distance (in kilometers)
 // by the passed in compass bearing (in degrees).
@@ -42,10 +63,10 @@ func (p *Point) PointAtDistanceAndBearing(dist float64, bearing float64) *Point
 
 	dr := dist / EARTH_RADIUS
 
-	bearing = (bearing * (math.Pi / 180.0))
+	bearing = DegreesToRadians(bearing)
 
-	lat1 := (p.lat * (math.Pi / 180.0))
-	lng1 := (p.lng * (math.Pi / 180.0))
+	lat1 := DegreesToRadians(p.lat)
+	lng1 := DegreesToRadians(p.lng)
 
 	lat2_part1 := math.Sin(lat1) * math.Cos(dr)
 	lat2_part2 := math.Cos(lat1) * math.Sin(dr) * math.Cos(bearing)
@@ -58,44 +79,142 @@ func (p *Point) PointAtDistanceAndBearing(dist float64, bearing float64) *Point
 	lng2 := lng1 + math.Atan2(lng2_part1, lng2_part2)
 	lng2 = math.Mod((lng2+3*math.Pi), (2*math.Pi)) - math.Pi
 
-	lat2 = lat2 * (180.0 / math.Pi)
-	lng2 = lng2 * (180.0 / math.Pi)
+	lat2 = RadiansToDegrees(lat2)
+	lng2 = RadiansToDegrees(lng2)
 
 	return &Point{lat: lat2, lng: lng2}
 }
 
+// Returns a Point shifted from the current Point by the passed in distances (in meters)
+// to the north and to the east.  Negative distances shift to the south and to the west.
+// The shift is made on a plane tangent to the Earth at the current Point, which assumes that
+// the angles involved are small: it is accurate to within a few meters for offsets of a few kilometers,
+// but degrades over longer distances and close to the poles.
+// Use PointAtDistanceAndBearing for larger offsets.
+func (p *Point) OffsetMeters(dNorth float64, dEast float64) *Point {
+	r := EARTH_RADIUS * 1000.0
+
+	dLat := dNorth / r
+	dLng := dEast / (r * math.Cos(DegreesToRadians(p.lat)))
+
+	lat := p.lat + RadiansToDegrees(dLat)
+	lng := normalizeLongitude(p.lng + RadiansToDegrees(dLng))
+
+	return NewPoint(lat, lng)
+}
+
 // Calculates the Haversine distance between two points in kilometers.
 // Original Implementation from: http://www.movable-type.co.uk/scripts/latlong.html
 func (p *Point) GreatCircleDistance(p2 *Point) float64 {
-	dLat := (p2.lat - p.lat) * (math.Pi / 180.0)
-	dLon := (p2.lng - p.lng) * (math.Pi / 180.0)
+	return EARTH_RADIUS * p.centralAngle(p2)
+}
+
+// Returns the angle (in radians) between two points as seen from the center of the sphere,
+// using the Haversine formula.
+func (p *Point) centralAngle(p2 *Point) float64 {
+	dLat := DegreesToRadians(p2.lat - p.lat)
+	dLon := DegreesToRadians(p2.lng - p.lng)
 
-	lat1 := p.lat * (math.Pi / 180.0)
-	lat2 := p2.lat * (math.Pi / 180.0)
+	lat1 := DegreesToRadians(p.lat)
+	lat2 := DegreesToRadians(p2.lat)
 
 	a1 := math.Sin(dLat/2) * math.Sin(dLat/2)
 	a2 := math.Sin(dLon/2) * math.Sin(dLon/2) * math.Cos(lat1) * math.Cos(lat2)
 
 	a := a1 + a2
 
-	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
-
-	return EARTH_RADIUS * c
+	return 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
 }
 
 // Calculates the initial bearing (sometimes referred to as forward azimuth)
 // Original Implementation from: http://www.movable-type.co.uk/scripts/latlong.html
 func (p *Point) BearingTo(p2 *Point) float64 {
 
-	dLon := (p2.lng - p.lng) * math.Pi / 180.0
+	dLon := DegreesToRadians(p2.lng - p.lng)
 
-	lat1 := p.lat * math.Pi / 180.0
-	lat2 := p2.lat * math.Pi / 180.0
+	lat1 := DegreesToRadians(p.lat)
+	lat2 := DegreesToRadians(p2.lat)
 
 	y := math.Sin(dLon) * math.Cos(lat2)
 	x := math.Cos(lat1)*math.Sin(lat2) -
 		math.Sin(lat1)*math.Cos(lat2)*math.Cos(dLon)
-	brng := math.Atan2(y, x) * 180.0 / math.Pi
+	brng := RadiansToDegrees(math.Atan2(y, x))
+
+	return brng
+}
+
+// Calculates the final bearing on arriving at the supplied point along the great circle from 'this' point,
+// which differs from the initial bearing given by BearingTo unless the path follows a meridian or the equator.
+// It is the reverse of the initial bearing from the supplied point back to 'this' one, in the same range as BearingTo.
+// Original Implementation from: http://www.movable-type.co.uk/scripts/latlong.html
+func (p *Point) FinalBearing(p2 *Point) float64 {
+	brng := p2.BearingTo(p) + 180
+	if brng > 180 {
+		brng -= 360
+	}
+
+	return brng
+}
+
+// Returns a new Point moved from the current one in a random direction, by a random distance of up to the passed in maximum (in meters),
+// so that exact locations, such as users' positions, can be fuzzed before they are stored or displayed.
+// The new Point is uniformly distributed over the disk of that radius, rather than clustered around its center.
+// Randomness comes from the passed in rng, so that a seeded one gives reproducible results, or from the math/rand package if it is nil.
+// A maximum that is not positive returns a copy of the current Point.
+// This is not cryptographically secure anonymization: the rng is predictable, and repeatedly jittering the same location
+// lets its average give it away, so jitter each location once and store only the result.
+func (p *Point) Jitter(maxMeters float64, rng *rand.Rand) *Point {
+	if maxMeters <= 0 {
+		return NewPoint(p.lat, p.lng)
+	}
+
+	random := rand.Float64
+	if rng != nil {
+		random = rng.Float64
+	}
+
+	// Taking the square root spreads the distances so that every area of the disk is equally likely.
+	dist := maxMeters * math.Sqrt(random()) / 1000
+	bearing := random() * 360
+
+	return p.PointAtDistanceAndBearing(dist, bearing)
+}
+
+// Returns the center of the cell that the current Point falls in, on a grid whose cells are about the passed in resolution (in meters) on each side,
+// so that nearby points can be bucketed together, such as for heatmaps or to coarsen locations for privacy.
+// Unlike Jitter, the same Point always snaps to the same cell center.
+// The grid divides latitude into rows of equal height, and each row into columns of equal width in degrees,
+// as many as fit the row's circumference at its center latitude.  Cells therefore stay about the resolution wide in meters,
+// but span more degrees of longitude towards the poles, until the rows nearest them are only a few cells around.
+// The row and column sizes are rounded so that they divide the globe evenly.  A resolution that is not positive returns a copy of the current Point.
+func (p *Point) Snap(resolutionMeters float64) *Point {
+	if resolutionMeters <= 0 {
+		return NewPoint(p.lat, p.lng)
+	}
+
+	metersPerDegree := EARTH_RADIUS * 1000 * DegreesToRadians(1)
+
+	rows := math.Max(1, math.Round(180*metersPerDegree/resolutionMeters))
+	rowHeight := 180 / rows
+	row := math.Min(rows-1, math.Floor((p.lat+90)/rowHeight))
+	lat := -90 + (row+0.5)*rowHeight
+
+	cols := math.Max(1, math.Round(360*metersPerDegree*math.Cos(DegreesToRadians(lat))/resolutionMeters))
+	colWidth := 360 / cols
+	col := math.Min(cols-1, math.Floor((normalizeLongitude(p.lng)+180)/colWidth))
+	lng := -180 + (col+0.5)*colWidth
+
+	return NewPoint(lat, lng)
+}
+
+// Calculates the initial bearing to the supplied point relative to magnetic north, in degrees from 0 up to 360,
+// given the magnetic declination at the current point, in degrees, positive where magnetic north lies east of true north.
+// The declination is not computed here; take it from a chart or a model such as the World Magnetic Model.
+func (p *Point) BearingToMagnetic(p2 *Point, declinationDeg float64) float64 {
+	brng := math.Mod(p.BearingTo(p2)-declinationDeg, 360)
+	if brng < 0 {
+		brng += 360
+	}
 
 	return brng
 }
@@ -103,11 +222,11 @@ func (p *Point) BearingTo(p2 *Point) float64 {
 // Calculates the midpoint between 'this' point and the supplied point.
 // Original implementation from http://www.movable-type.co.uk/scripts/latlong.html
 func (p *Point) MidpointTo(p2 *Point) *Point {
-	lat1 := p.lat * math.Pi / 180.0
-	lat2 := p2.lat * math.Pi / 180.0
+	lat1 := DegreesToRadians(p.lat)
+	lat2 := DegreesToRadians(p2.lat)
 
-	lon1 := p.lng * math.Pi / 180.0
-	dLon := (p2.lng - p.lng) * math.Pi / 180.0
+	lon1 := DegreesToRadians(p.lng)
+	dLon := DegreesToRadians(p2.lng - p.lng)
 
 	bx := math.Cos(lat2) * math.Cos(dLon)
 	by := math.Cos(lat2) * math.Sin(dLon)
@@ -118,12 +237,129 @@ func (p *Point) MidpointTo(p2 *Point) *Point {
 	)
 	lon3Rad := lon1 + math.Atan2(by, math.Cos(lat1)+bx)
 
-	lat3 := lat3Rad * 180.0 / math.Pi
-	lon3 := lon3Rad * 180.0 / math.Pi
+	lat3 := RadiansToDegrees(lat3Rad)
+	lon3 := RadiansToDegrees(lon3Rad)
 
 	return NewPoint(lat3, lon3)
 }
 
+// Returns the Point that lies the passed in fraction of the way along the great circle path
+// from p1 to p2, using spherical linear interpolation.  A fraction of 0 returns p1, 1 returns p2,
+// and 0.5 returns the midpoint.  Paths that cross the antimeridian take the shorter way around.
+// The great circle between two antipodal points is undefined, so the result for them is not meaningful.
+// Original Implementation from: http://www.movable-type.co.uk/scripts/latlong.html
+func Interpolate(p1 *Point, p2 *Point, fraction float64) *Point {
+	if fraction == 0 {
+		return NewPoint(p1.lat, p1.lng)
+	}
+
+	if fraction == 1 {
+		return NewPoint(p2.lat, p2.lng)
+	}
+
+	d := p1.GreatCircleDistance(p2) / EARTH_RADIUS
+	if d == 0 {
+		return NewPoint(p1.lat, p1.lng)
+	}
+
+	lat1 := DegreesToRadians(p1.lat)
+	lng1 := DegreesToRadians(p1.lng)
+	lat2 := DegreesToRadians(p2.lat)
+	lng2 := DegreesToRadians(p2.lng)
+
+	a := math.Sin((1-fraction)*d) / math.Sin(d)
+	b := math.Sin(fraction*d) / math.Sin(d)
+
+	x := a*math.Cos(lat1)*math.Cos(lng1) + b*math.Cos(lat2)*math.Cos(lng2)
+	y := a*math.Cos(lat1)*math.Sin(lng1) + b*math.Cos(lat2)*math.Sin(lng2)
+	z := a*math.Sin(lat1) + b*math.Sin(lat2)
+
+	lat3 := RadiansToDegrees(math.Atan2(z, math.Sqrt(x*x+y*y)))
+	lng3 := RadiansToDegrees(math.Atan2(y, x))
+
+	return NewPoint(lat3, lng3)
+}
+
+// Returns the antipode of the current Point: the point on the diametrically opposite side of the Earth.
+// The latitude is negated and the longitude is shifted by 180 degrees into the range [-180, 180).
+func (p *Point) Antipode() *Point {
+	return NewPoint(-p.lat, normalizeLongitude(p.lng+180))
+}
+
+// Returns the current Point with its longitude wrapped into the range [-180, 180),
+// so that, for example, a longitude of 190 becomes -170.  The latitude is left as it is.
+func (p *Point) Wrap() *Point {
+	return NewPoint(p.lat, normalizeLongitude(p.lng))
+}
+
+// Returns the signed shortest difference (in degrees) from the first passed in longitude to the second,
+// going either way around the antimeridian, in the range [-180, 180).
+// It is positive when the second longitude is east of the first, so LongitudeDiff(179, -179) is 2, not -358.
+func LongitudeDiff(lng1 float64, lng2 float64) float64 {
+	return normalizeLongitude(lng2 - lng1)
+}
+
+// The largest number of decimal places a PointKey can be quantized to.
+const maxPointKeyPrecision = 15
+
+// A comparable value that identifies the grid cell a Point falls in, for use as a map key.
+// Points whose coordinates round to the same number of decimal places get equal keys.
+type PointKey struct {
+	Lat       int64
+	Lng       int64
+	Precision int
+}
+
+// Returns the PointKey of the current Point, quantized to the passed in number of decimal places.
+// A precision of 4, for example, groups points into cells of about 11 meters,
+// so that points that differ only by floating point error still get equal keys.
+// The precision is clamped to between 0 and 15, and longitudes are wrapped into [-180, 180) first.
+func (p *Point) Key(precision int) PointKey {
+	precision = int(math.Max(0, math.Min(maxPointKeyPrecision, float64(precision))))
+	scale := math.Pow(10, float64(precision))
+
+	lng := math.Round(normalizeLongitude(p.lng) * scale)
+	if lng == 180*scale {
+		// Longitudes just short of 180 round up to it, which is the same meridian as -180.
+		lng = -lng
+	}
+
+	return PointKey{
+		Lat:       int64(math.Round(p.lat * scale)),
+		Lng:       int64(lng),
+		Precision: precision,
+	}
+}
+
+// Returns the Point at the center of the grid cell that the current PointKey identifies.
+func (k PointKey) Point() *Point {
+	scale := math.Pow(10, float64(k.Precision))
+	return NewPoint(float64(k.Lat)/scale, float64(k.Lng)/scale)
+}
+
+// Returns whether or not the current Point is nil or sits exactly at 0, 0.
+// Since 0, 0 is the zero value of a Point, a Point that reports true here was most likely never set,
+// for example by a geocoder that failed without saying so, rather than placed deliberately off the coast of Africa.
+// Use a nil *Point to represent a location that is known to be missing.
+func (p *Point) IsZero() bool {
+	return p == nil || (p.lat == 0 && p.lng == 0)
+}
+
+// Returns whether or not the passed in Point has exactly the same coordinates as the current Point.
+func (p *Point) Equal(p2 *Point) bool {
+	return p.lat == p2.lat && p.lng == p2.lng
+}
+
+// Returns whether or not the passed in Point lies within the passed in tolerance (in meters)
+// of the current Point, as measured by the great circle distance between them.
+func (p *Point) EqualWithin(p2 *Point, toleranceMeters float64) bool {
+	if p.Equal(p2) {
+		return true
+	}
+
+	return p.GreatCircleDistance(p2)*1000 <= toleranceMeters
+}
+
 // Renders the current Point to valid JSON.
 // Implements the json.Marshaller Interface.
 func (p *Point) MarshalJSON() ([]byte, error) {
@@ -148,3 +384,76 @@ func (p *Point) UnmarshalJSON(data []byte) error {
 
 	return nil
 }
+
+// Returns a copy of the passed in points with near-duplicates removed.
+// A point is dropped if it lies within the passed in tolerance (in meters) of a point
+// that has already been kept, so the first of each group of duplicates survives
+// and the output preserves the input order.
+// Points are first bucketed by geohash so that each point is only compared
+// against kept points in its own and neighbouring cells.
+func DedupPoints(points []*Point, toleranceMeters float64) []*Point {
+	precision := dedupGeohashPrecision(points, toleranceMeters)
+	height, width := geohashCellSize(precision)
+
+	buckets := make(map[string][]*Point)
+	res := []*Point{}
+
+	for _, p := range points {
+		if isDuplicatePoint(p, buckets, precision, height, width, toleranceMeters) {
+			continue
+		}
+
+		res = append(res, p)
+		hash := p.Geohash(precision)
+		buckets[hash] = append(buckets[hash], p)
+	}
+
+	return res
+}
+
+// Returns whether or not the passed in point is within the tolerance
+// of any point kept in its own or one of its eight neighbouring geohash cells.
+func isDuplicatePoint(p *Point, buckets map[string][]*Point, precision int, height float64, width float64, toleranceMeters float64) bool {
+	seen := make(map[string]bool, 9)
+
+	for dLat := -1.0; dLat <= 1; dLat++ {
+		for dLng := -1.0; dLng <= 1; dLng++ {
+			lat := math.Max(-90, math.Min(90, p.lat+dLat*height))
+			hash := NewPoint(lat, p.lng+dLng*width).Geohash(precision)
+			if seen[hash] {
+				continue
+			}
+			seen[hash] = true
+
+			for _, kept := range buckets[hash] {
+				if p.EqualWithin(kept, toleranceMeters) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// Returns the longest geohash precision whose cells are at least as tall and wide as the tolerance
+// for every one of the passed in points.  Cells narrow towards the poles,
+// so points at high latitudes result in coarser buckets.
+func dedupGeohashPrecision(points []*Point, toleranceMeters float64) int {
+	maxLat := 0.0
+	for _, p := range points {
+		maxLat = math.Max(maxLat, math.Abs(p.lat))
+	}
+
+	metersPerDegree := EARTH_RADIUS * 1000 * DegreesToRadians(1)
+	cosLat := math.Cos(DegreesToRadians(maxLat))
+
+	for precision := maxGeohashPrecision; precision > 1; precision-- {
+		height, width := geohashCellSize(precision)
+		if height*metersPerDegree >= toleranceMeters && width*metersPerDegree*cosLat >= toleranceMeters {
+			return precision
+		}
+	}
+
+	return 1
+}
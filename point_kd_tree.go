@@ -0,0 +1,179 @@
+package geo
+
+import (
+	"math"
+	"sort"
+)
+
+// A PointKDTree answers nearest-neighbor queries over a large, fixed set of points, such as stores or stations,
+// without measuring the distance to every point for every query.
+// Points are projected onto the unit sphere as x, y and z coordinates, and split along each axis in turn.
+// The straight-line distance through the sphere grows with the great circle distance,
+// so the search is exact everywhere, including across the antimeridian and near the poles,
+// and the distances it returns are Haversine distances, in kilometers.
+// The tree reflects the points as they were when it was built.
+type PointKDTree struct {
+	nodes []kdNode
+	root  int
+}
+
+// A single Point of a PointKDTree, with the indexes of the nodes on either side of its split, or -1 if there are none.
+type kdNode struct {
+	point *Point
+	xyz   [3]float64
+	axis  int
+	left  int
+	right int
+}
+
+// Creates and returns a pointer to a new PointKDTree of the passed in points.  Nil points are left out.
+func NewPointKDTree(points []*Point) *PointKDTree {
+	nodes := make([]kdNode, 0, len(points))
+	for _, p := range points {
+		if p != nil {
+			nodes = append(nodes, kdNode{point: p, xyz: unitSphereXYZ(p)})
+		}
+	}
+
+	t := &PointKDTree{nodes: nodes}
+	t.root = t.build(0, len(nodes), 0)
+
+	return t
+}
+
+// Returns the number of points in the current PointKDTree.
+func (t *PointKDTree) Len() int {
+	return len(t.nodes)
+}
+
+// Arranges the nodes between the passed in start and end into a subtree split along the passed in axis,
+// and returns the index of its root, or -1 if there are no nodes between them.
+func (t *PointKDTree) build(start int, end int, axis int) int {
+	if start >= end {
+		return -1
+	}
+
+	nodes := t.nodes[start:end]
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].xyz[axis] < nodes[j].xyz[axis] })
+
+	mid := start + (end-start)/2
+	next := (axis + 1) % 3
+	t.nodes[mid].axis = axis
+	t.nodes[mid].left = t.build(start, mid, next)
+	t.nodes[mid].right = t.build(mid+1, end, next)
+
+	return mid
+}
+
+// Returns the Point of the current PointKDTree nearest to the passed in Point, and its distance in kilometers.
+// Returns nil and 0 if the tree is empty.
+func (t *PointKDTree) Nearest(p *Point) (*Point, float64) {
+	nearest := t.NearestN(p, 1)
+	if len(nearest) == 0 {
+		return nil, 0
+	}
+
+	return nearest[0], p.GreatCircleDistance(nearest[0])
+}
+
+// Returns the passed in number of points of the current PointKDTree nearest to the passed in Point, nearest first.
+// Returns every point if the tree has fewer, and none if the number is not positive.
+func (t *PointKDTree) NearestN(p *Point, n int) []*Point {
+	if n <= 0 || len(t.nodes) == 0 {
+		return []*Point{}
+	}
+
+	s := &kdSearch{tree: t, target: unitSphereXYZ(p), n: n}
+	s.visit(t.root)
+
+	// Points at the same straight-line distance are ordered by their Haversine distance, which also settles
+	// the rounding of the projection for points all but equally near.
+	nearest := make([]*Point, len(s.found))
+	distances := make([]float64, len(s.found))
+	for i, c := range s.found {
+		nearest[i] = t.nodes[c.node].point
+		distances[i] = p.GreatCircleDistance(nearest[i])
+	}
+	sort.Stable(&kdResults{nearest, distances})
+
+	return nearest
+}
+
+// The state of a nearest-neighbor search of a PointKDTree.
+type kdSearch struct {
+	tree   *PointKDTree
+	target [3]float64
+	n      int
+
+	// The nearest nodes found so far, nearest first, by their squared straight-line distance to the target.
+	found []kdCandidate
+}
+
+// A node found by a kdSearch, and its squared straight-line distance to the target.
+type kdCandidate struct {
+	node     int
+	distance float64
+}
+
+// Searches the subtree rooted at the passed in node, skipping the far side of each split
+// when it lies further from the target than the furthest of the nearest nodes found so far.
+func (s *kdSearch) visit(i int) {
+	if i < 0 {
+		return
+	}
+
+	node := &s.tree.nodes[i]
+	s.offer(i, squaredDistance(node.xyz, s.target))
+
+	d := s.target[node.axis] - node.xyz[node.axis]
+	near, far := node.left, node.right
+	if d > 0 {
+		near, far = far, near
+	}
+
+	s.visit(near)
+	if len(s.found) < s.n || d*d <= s.found[len(s.found)-1].distance {
+		s.visit(far)
+	}
+}
+
+// Keeps the passed in node if it is among the nearest nodes found so far.
+func (s *kdSearch) offer(i int, distance float64) {
+	if len(s.found) == s.n && distance >= s.found[len(s.found)-1].distance {
+		return
+	}
+
+	j := sort.Search(len(s.found), func(j int) bool { return s.found[j].distance > distance })
+	if len(s.found) < s.n {
+		s.found = append(s.found, kdCandidate{})
+	}
+	copy(s.found[j+1:], s.found[j:len(s.found)-1])
+	s.found[j] = kdCandidate{node: i, distance: distance}
+}
+
+// Sorts points by their parallel distances.
+type kdResults struct {
+	points    []*Point
+	distances []float64
+}
+
+func (r *kdResults) Len() int           { return len(r.points) }
+func (r *kdResults) Less(i, j int) bool { return r.distances[i] < r.distances[j] }
+func (r *kdResults) Swap(i, j int) {
+	r.points[i], r.points[j] = r.points[j], r.points[i]
+	r.distances[i], r.distances[j] = r.distances[j], r.distances[i]
+}
+
+// Returns the x, y and z coordinates of the passed in Point projected onto the unit sphere.
+func unitSphereXYZ(p *Point) [3]float64 {
+	lat, lng := p.LatRadians(), p.LngRadians()
+
+	return [3]float64{math.Cos(lat) * math.Cos(lng), math.Cos(lat) * math.Sin(lng), math.Sin(lat)}
+}
+
+// Returns the squared straight-line distance between the passed in coordinates.
+func squaredDistance(a [3]float64, b [3]float64) float64 {
+	dx, dy, dz := a[0]-b[0], a[1]-b[1], a[2]-b[2]
+
+	return dx*dx + dy*dy + dz*dz
+}
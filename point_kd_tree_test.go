@@ -0,0 +1,91 @@
+package geo
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// Returns the passed in number of random points, drawn from the passed in seed.
+func randomPoints(n int, seed int64) []*Point {
+	rng := rand.New(rand.NewSource(seed))
+	points := make([]*Point, n)
+	for i := range points {
+		points[i] = NewPoint(rng.Float64()*180-90, rng.Float64()*360-180)
+	}
+
+	return points
+}
+
+// Ensures that the tree finds the same nearest points as measuring the distance to every one of them.
+func TestPointKDTreeMatchesLinearScan(t *testing.T) {
+	points := randomPoints(500, 1)
+	tree := NewPointKDTree(points)
+	if tree.Len() != len(points) {
+		t.Errorf("Mismatched length.  Expected: %d.  Actual: %d", len(points), tree.Len())
+	}
+
+	for _, q := range randomPoints(50, 2) {
+		sorted := append([]*Point{}, points...)
+		sort.SliceStable(sorted, func(i, j int) bool { return q.GreatCircleDistance(sorted[i]) < q.GreatCircleDistance(sorted[j]) })
+
+		nearest, d := tree.Nearest(q)
+		if nearest != sorted[0] || d != q.GreatCircleDistance(sorted[0]) {
+			t.Errorf("Mismatched nearest point to %v.  Expected: %v, %f.  Actual: %v, %f", q, sorted[0], q.GreatCircleDistance(sorted[0]), nearest, d)
+		}
+
+		res := tree.NearestN(q, 5)
+		if len(res) != 5 {
+			t.Fatalf("Mismatched number of points.  Expected: 5.  Actual: %d", len(res))
+		}
+
+		for i := range res {
+			if res[i] != sorted[i] {
+				t.Errorf("Mismatched point %d nearest to %v.  Expected: %v.  Actual: %v", i, q, sorted[i], res[i])
+			}
+		}
+	}
+}
+
+// Ensures that points just across the antimeridian and the pole are found to be near.
+func TestPointKDTreeWraparound(t *testing.T) {
+	fiji := NewPoint(-17.7, 179.9)
+	samoa := NewPoint(-13.8, -172.1)
+	tree := NewPointKDTree([]*Point{NewPoint(-17.7, 170), samoa, nil})
+
+	if nearest, _ := tree.Nearest(fiji); nearest != samoa {
+		t.Errorf("Mismatched nearest point across the antimeridian.  Expected: %v.  Actual: %v", samoa, nearest)
+	}
+
+	across := NewPoint(89, 179)
+	tree = NewPointKDTree([]*Point{NewPoint(85, 0), across})
+	if nearest, _ := tree.Nearest(NewPoint(89, 0)); nearest != across {
+		t.Errorf("Mismatched nearest point across the pole.  Expected: %v.  Actual: %v", across, nearest)
+	}
+}
+
+func TestPointKDTreeEdgeCases(t *testing.T) {
+	empty := NewPointKDTree(nil)
+	if p, d := empty.Nearest(NewPoint(0, 0)); p != nil || d != 0 {
+		t.Errorf("Expected no nearest point in an empty tree.  Actual: %v, %f", p, d)
+	}
+
+	tree := NewPointKDTree([]*Point{NewPoint(0, 1), NewPoint(0, 2)})
+	if res := tree.NearestN(NewPoint(0, 0), 5); len(res) != 2 {
+		t.Errorf("Mismatched number of points.  Expected: 2.  Actual: %d", len(res))
+	}
+
+	if res := tree.NearestN(NewPoint(0, 0), 0); len(res) != 0 {
+		t.Errorf("Mismatched number of points.  Expected: 0.  Actual: %d", len(res))
+	}
+}
+
+func BenchmarkPointKDTreeNearest(b *testing.B) {
+	tree := NewPointKDTree(randomPoints(100000, 1))
+	q := NewPoint(40.7486, -73.9864)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.Nearest(q)
+	}
+}
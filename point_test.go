@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
+	"math/rand"
 	"testing"
 )
 
@@ -76,6 +78,33 @@ func TestPointAtDistanceAndBearing(t *testing.T) {
 	}
 }
 
+func TestDegreesToRadians(t *testing.T) {
+	cases := []struct {
+		degrees float64
+		radians float64
+	}{
+		{0, 0},
+		{180, math.Pi},
+		{-90, -math.Pi / 2},
+		{360, 2 * math.Pi},
+	}
+
+	for _, c := range cases {
+		if r := DegreesToRadians(c.degrees); math.Abs(r-c.radians) > 1e-12 {
+			t.Errorf("Mismatched radians of %v degrees.  Expected: %v.  Actual: %v", c.degrees, c.radians, r)
+		}
+
+		if d := RadiansToDegrees(c.radians); math.Abs(d-c.degrees) > 1e-12 {
+			t.Errorf("Mismatched degrees of %v radians.  Expected: %v.  Actual: %v", c.radians, c.degrees, d)
+		}
+	}
+
+	p := NewPoint(45, -180)
+	if math.Abs(p.LatRadians()-math.Pi/4) > 1e-12 || math.Abs(p.LngRadians()+math.Pi) > 1e-12 {
+		t.Errorf("Mismatched radian coordinates.  Expected: %v, %v.  Actual: %v, %v", math.Pi/4, -math.Pi, p.LatRadians(), p.LngRadians())
+	}
+}
+
 func TestBearingTo(t *testing.T) {
 	p1 := &Point{lat: 40.7486, lng: -73.9864}
 	p2 := &Point{lat: 0.0, lng: 0.0}
@@ -90,6 +119,132 @@ func TestBearingTo(t *testing.T) {
 	}
 }
 
+func TestFinalBearing(t *testing.T) {
+	cases := []struct {
+		from     *Point
+		to       *Point
+		initial  float64
+		expected float64
+	}{
+		// Along the equator, the bearing never changes.
+		{NewPoint(0, 0), NewPoint(0, 10), 90, 90},
+		{NewPoint(0, 10), NewPoint(0, 0), -90, -90},
+		// From New York to London, the path curves north and then back south.
+		{NewPoint(40.7128, -74.0060), NewPoint(51.5074, -0.1278), 51.212617, 108.329702},
+		{NewPoint(10, 5), NewPoint(-10, 5), 180, 180},
+	}
+
+	for _, c := range cases {
+		if bearing := c.from.BearingTo(c.to); math.Abs(bearing-c.initial) > 0.0001 {
+			t.Errorf("Mismatched initial bearing from %v to %v.  Expected: %f.  Actual: %f", c.from, c.to, c.initial, bearing)
+		}
+
+		if bearing := c.from.FinalBearing(c.to); math.Abs(bearing-c.expected) > 0.0001 {
+			t.Errorf("Mismatched final bearing from %v to %v.  Expected: %f.  Actual: %f", c.from, c.to, c.expected, bearing)
+		}
+	}
+}
+
+func TestJitter(t *testing.T) {
+	p := NewPoint(40.7486, -73.9864)
+
+	// A seeded rng gives the same jittered Point every time.
+	if a, b := p.Jitter(500, rand.New(rand.NewSource(42))), p.Jitter(500, rand.New(rand.NewSource(42))); !a.Equal(b) {
+		t.Errorf("Expected the same seed to give the same Point.  Actual: %v and %v", a, b)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	inner := 0
+	for i := 0; i < 10000; i++ {
+		q := p.Jitter(500, rng)
+
+		d := p.GreatCircleDistance(q) * 1000
+		if d > 500.001 {
+			t.Fatalf("Expected the jittered Point to be within 500m.  Actual: %fm", d)
+		}
+
+		if d <= 250 {
+			inner++
+		}
+	}
+
+	// The inner half of the radius covers a quarter of the disk's area.
+	if inner < 2300 || inner > 2700 {
+		t.Errorf("Expected about a quarter of the Points within half of the radius.  Actual: %d of 10000", inner)
+	}
+
+	if q := p.Jitter(0, rng); !q.Equal(p) || q == p {
+		t.Errorf("Expected a copy of the Point for no jitter.  Actual: %v", q)
+	}
+
+	if q := p.Jitter(100, nil); p.GreatCircleDistance(q)*1000 > 100.001 {
+		t.Errorf("Expected the jittered Point to be within 100m.  Actual: %v", q)
+	}
+}
+
+func TestSnap(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		p := NewPoint(rng.Float64()*140-70, rng.Float64()*360-180)
+		snapped := p.Snap(1000)
+
+		// The cell center is within half of the cell's diagonal of the Point, allowing for the rounding of the grid.
+		if d := p.GreatCircleDistance(snapped) * 1000; d > 1000*math.Sqrt2/2*1.01 {
+			t.Errorf("Expected %v to snap within a cell of itself.  Actual: %v, %fm away", p, snapped, d)
+		}
+
+		if again := snapped.Snap(1000); !again.Equal(snapped) {
+			t.Errorf("Expected a cell center to snap to itself.  Expected: %v.  Actual: %v", snapped, again)
+		}
+	}
+
+	// Points in the same cell share its center, and those in neighboring cells do not.
+	a, b, c := NewPoint(40.7486, -73.9864), NewPoint(40.7487, -73.9865), NewPoint(40.7486, -73.8864)
+	if !a.Snap(1000).Equal(b.Snap(1000)) || a.Snap(1000).Equal(c.Snap(1000)) {
+		t.Errorf("Mismatched cells.  Actual: %v, %v, %v", a.Snap(1000), b.Snap(1000), c.Snap(1000))
+	}
+
+	// Around the poles, cells span many degrees of longitude: the row nearest the pole has a circumference of about 3km.
+	if n, m := NewPoint(89.999, 10).Snap(1000), NewPoint(89.999, 50).Snap(1000); !n.Equal(m) || n.Lng() != 0 {
+		t.Errorf("Expected a wide cell around the pole.  Actual: %v and %v", n, m)
+	}
+
+	// The antimeridian does not split a cell.
+	if e, w := NewPoint(10, 180).Snap(1000), NewPoint(10, -180).Snap(1000); !e.Equal(w) {
+		t.Errorf("Expected 180 and -180 to snap to the same cell.  Actual: %v and %v", e, w)
+	}
+
+	if p := NewPoint(1, 2); !p.Snap(0).Equal(p) {
+		t.Errorf("Expected a copy of the Point for no resolution.  Actual: %v", p.Snap(0))
+	}
+}
+
+func TestBearingToMagnetic(t *testing.T) {
+	p1 := &Point{lat: 40.7486, lng: -73.9864}
+	p2 := &Point{lat: 0.0, lng: 0.0}
+
+	cases := []struct {
+		from        *Point
+		to          *Point
+		declination float64
+		expected    float64
+	}{
+		// New York's declination is about 13 degrees west.
+		{p1, p2, -13, 113.610833},
+		{p1, p2, 0, 100.610833},
+		// A true bearing of -48.13 degrees wraps around to 311.87, less an easterly declination.
+		{p2, p1, 4, 307.872926},
+		{NewPoint(0, 0), NewPoint(1, 0), 10, 350},
+	}
+
+	for _, c := range cases {
+		bearing := c.from.BearingToMagnetic(c.to, c.declination)
+		if math.Abs(bearing-c.expected) > 0.01 {
+			t.Errorf("Mismatched magnetic bearing with a declination of %v.  Expected: %f.  Actual: %f", c.declination, c.expected, bearing)
+		}
+	}
+}
+
 func TestMidpointTo(t *testing.T) {
 	p1 := &Point{lat: 52.205, lng: 0.119}
 	p2 := &Point{lat: 48.857, lng: 2.351}
@@ -136,3 +291,288 @@ func TestUnmarshalJSON(t *testing.T) {
 		t.Errorf("Point has mismatched data after Unmarshalling from JSON")
 	}
 }
+
+func TestAntipode(t *testing.T) {
+	cases := []struct {
+		p        *Point
+		expected *Point
+	}{
+		{NewPoint(40.7486, -73.9864), NewPoint(-40.7486, 106.0136)},
+		{NewPoint(-33.8688, 151.2093), NewPoint(33.8688, -28.7907)},
+		{NewPoint(0, 0), NewPoint(0, -180)},
+		{NewPoint(10, 180), NewPoint(-10, 0)},
+		{NewPoint(90, 45), NewPoint(-90, -135)},
+	}
+
+	for _, c := range cases {
+		res := c.p.Antipode()
+		if math.Abs(res.Lat()-c.expected.Lat()) > 1e-9 || math.Abs(res.Lng()-c.expected.Lng()) > 1e-9 {
+			t.Errorf("Mismatched antipode of %v.  Expected: %v.  Actual: %v", c.p, c.expected, res)
+		}
+
+		if res.Lng() < -180 || res.Lng() >= 180 {
+			t.Errorf("Expected the antipode's longitude to be within [-180, 180), but got %f", res.Lng())
+		}
+
+		// Antipodal points are half the Earth's circumference apart.
+		// The haversine formula is ill-conditioned here, so allow it to be off by up to a kilometer.
+		dist := c.p.GreatCircleDistance(res)
+		if math.Abs(dist-math.Pi*EARTH_RADIUS) > 1 {
+			t.Errorf("Mismatched distance to the antipode of %v.  Expected: %f.  Actual: %f", c.p, math.Pi*EARTH_RADIUS, dist)
+		}
+
+		if back := res.Antipode(); !c.p.EqualWithin(back, 1e-6) {
+			t.Errorf("Expected the antipode of the antipode of %v to be itself, but got %v", c.p, back)
+		}
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	p1 := NewPoint(40.7486, -73.9864)
+	p2 := NewPoint(51.5072, -0.1276)
+
+	if res := Interpolate(p1, p2, 0); !res.Equal(p1) {
+		t.Errorf("Mismatched interpolated point at fraction 0.  Expected: %v.  Actual: %v", p1, res)
+	}
+
+	if res := Interpolate(p1, p2, 1); !res.Equal(p2) {
+		t.Errorf("Mismatched interpolated point at fraction 1.  Expected: %v.  Actual: %v", p2, res)
+	}
+
+	mid := p1.MidpointTo(p2)
+	if res := Interpolate(p1, p2, 0.5); !res.EqualWithin(mid, 1) {
+		t.Errorf("Mismatched interpolated point at fraction 0.5.  Expected: %v.  Actual: %v", mid, res)
+	}
+
+	// Interpolated points lie on the great circle, so their distances from each end add up to the whole.
+	total := p1.GreatCircleDistance(p2)
+	res := Interpolate(p1, p2, 0.25)
+	if d := p1.GreatCircleDistance(res); math.Abs(d-total*0.25) > 1e-6 {
+		t.Errorf("Mismatched distance to the interpolated point.  Expected: %f.  Actual: %f", total*0.25, d)
+	}
+	if d := res.GreatCircleDistance(p2); math.Abs(d-total*0.75) > 1e-6 {
+		t.Errorf("Mismatched distance from the interpolated point.  Expected: %f.  Actual: %f", total*0.75, d)
+	}
+
+	// Coincident endpoints
+	if res := Interpolate(p1, NewPoint(p1.Lat(), p1.Lng()), 0.5); !res.Equal(p1) {
+		t.Errorf("Expected interpolating between coincident points to return the point, but got %v", res)
+	}
+
+	// Across the antimeridian, the path takes the short way around.
+	fiji := NewPoint(-17.7134, 178.065)
+	samoa := NewPoint(-13.759, -172.1046)
+	res = Interpolate(fiji, samoa, 0.5)
+	if math.Abs(res.Lng()) < 175 {
+		t.Errorf("Expected the midpoint across the antimeridian to lie near it, but got %v", res)
+	}
+	if mid := fiji.MidpointTo(samoa); !res.EqualWithin(NewPoint(mid.Lat(), normalizeLongitude(mid.Lng())), 1) {
+		t.Errorf("Mismatched interpolated point across the antimeridian.  Expected: %v.  Actual: %v", mid, res)
+	}
+}
+
+func TestOffsetMeters(t *testing.T) {
+	p := NewPoint(40.7486, -73.9864)
+
+	cases := []struct {
+		dNorth  float64
+		dEast   float64
+		bearing float64
+	}{
+		{1000, 0, 0},
+		{0, 1000, 90},
+		{-1000, 0, 180},
+		{0, -1000, 270},
+		{300, 400, 53.130102},
+	}
+
+	for _, c := range cases {
+		res := p.OffsetMeters(c.dNorth, c.dEast)
+		dist := math.Hypot(c.dNorth, c.dEast)
+		expected := p.PointAtDistanceAndBearing(dist/1000, c.bearing)
+
+		if !res.EqualWithin(expected, 0.5) {
+			t.Errorf("Mismatched point offset by [%f, %f].  Expected: %v.  Actual: %v", c.dNorth, c.dEast, expected, res)
+		}
+	}
+
+	// Offsets across the antimeridian keep the longitude in range.
+	res := NewPoint(0, 179.999).OffsetMeters(0, 1000)
+	if res.Lng() >= -179 || res.Lng() < -180 {
+		t.Errorf("Expected the longitude to wrap across the antimeridian, but got %f", res.Lng())
+	}
+}
+
+func TestEqual(t *testing.T) {
+	p1 := NewPoint(40.7486, -73.9864)
+	p2 := NewPoint(40.7486, -73.9864)
+	p3 := NewPoint(40.7487, -73.9864)
+
+	if !p1.Equal(p2) {
+		t.Error("Expected points with identical coordinates to be equal")
+	}
+
+	if p1.Equal(p3) {
+		t.Error("Expected points with different coordinates not to be equal")
+	}
+}
+
+func TestLongitudeDiff(t *testing.T) {
+	cases := []struct {
+		lng1     float64
+		lng2     float64
+		expected float64
+	}{
+		{179, -179, 2},
+		{-179, 179, -2},
+		{10, 20, 10},
+		{20, 10, -10},
+		{-170, 170, -20},
+		{0, 180, -180},
+		{0, 0, 0},
+		{350, 10, 20},
+	}
+
+	for _, c := range cases {
+		if res := LongitudeDiff(c.lng1, c.lng2); math.Abs(res-c.expected) > 1e-9 {
+			t.Errorf("Mismatched difference from %f to %f.  Expected: %f.  Actual: %f", c.lng1, c.lng2, c.expected, res)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	cases := []struct {
+		p        *Point
+		expected *Point
+	}{
+		{NewPoint(10, 190), NewPoint(10, -170)},
+		{NewPoint(-10, -190), NewPoint(-10, 170)},
+		{NewPoint(45, 180), NewPoint(45, -180)},
+		{NewPoint(45, 540), NewPoint(45, -180)},
+		{NewPoint(45, -73.9864), NewPoint(45, -73.9864)},
+	}
+
+	for _, c := range cases {
+		if res := c.p.Wrap(); math.Abs(res.Lat()-c.expected.Lat()) > 1e-9 || math.Abs(res.Lng()-c.expected.Lng()) > 1e-9 {
+			t.Errorf("Mismatched wrapped point for %v.  Expected: %v.  Actual: %v", c.p, c.expected, res)
+		}
+	}
+}
+
+func TestIsZero(t *testing.T) {
+	var unset *Point
+	if !unset.IsZero() {
+		t.Error("Expected a nil Point to be zero")
+	}
+
+	if !(&Point{}).IsZero() || !NewPoint(0, 0).IsZero() {
+		t.Error("Expected a Point at 0, 0 to be zero")
+	}
+
+	for _, p := range []*Point{NewPoint(0, 0.000001), NewPoint(-0.000001, 0), NewPoint(40.7486, -73.9864)} {
+		if p.IsZero() {
+			t.Errorf("Expected %v not to be zero", p)
+		}
+	}
+}
+
+func TestEqualWithin(t *testing.T) {
+	p1 := NewPoint(40.7486, -73.9864)
+
+	if !p1.EqualWithin(NewPoint(40.7486, -73.9864), 0) {
+		t.Error("Expected identical points to be equal with a tolerance of 0")
+	}
+
+	// ~11 meters north
+	p2 := NewPoint(40.7487, -73.9864)
+	if !p1.EqualWithin(p2, 12) {
+		t.Error("Expected points ~11 meters apart to be equal within 12 meters")
+	}
+
+	if p1.EqualWithin(p2, 10) {
+		t.Error("Expected points ~11 meters apart not to be equal within 10 meters")
+	}
+
+	// A round trip through float arithmetic should still be equal within a small tolerance.
+	p3 := p1.PointAtDistanceAndBearing(1, 90).PointAtDistanceAndBearing(1, 270)
+	if !p1.EqualWithin(p3, 1) {
+		t.Errorf("Expected a round-tripped point to be equal within 1 meter, but got %v", p3)
+	}
+}
+
+func TestPointKey(t *testing.T) {
+	p := NewPoint(40.7486, -73.9864)
+
+	// The same point after some floating point arithmetic
+	p2 := NewPoint(40.7486*3/3+1e-12, -73.9864-1e-12)
+	if p.Key(6) != p2.Key(6) {
+		t.Errorf("Expected nearly identical points to have equal keys, but got %+v and %+v", p.Key(6), p2.Key(6))
+	}
+
+	counts := map[PointKey]int{}
+	for _, q := range []*Point{p, p2, NewPoint(40.74861, -73.98641), NewPoint(40.7586, -73.9864)} {
+		counts[q.Key(3)]++
+	}
+
+	if len(counts) != 2 || counts[p.Key(3)] != 3 {
+		t.Errorf("Mismatched counts per key: %v", counts)
+	}
+
+	expected := PointKey{Lat: 407, Lng: -740, Precision: 1}
+	if k := p.Key(1); k != expected {
+		t.Errorf("Mismatched key.  Expected: %+v.  Actual: %+v", expected, k)
+	}
+
+	if c := p.Key(2).Point(); !c.Equal(NewPoint(40.75, -73.99)) {
+		t.Errorf("Mismatched center of the key's cell.  Expected: %v.  Actual: %v", NewPoint(40.75, -73.99), c)
+	}
+
+	// Longitudes on either side of the antimeridian wrap to the same key.
+	if NewPoint(10, 180).Key(4) != NewPoint(10, -180).Key(4) || NewPoint(10, 179.99999).Key(4) != NewPoint(10, -180).Key(4) {
+		t.Error("Expected longitudes of 180 and -180 to have equal keys")
+	}
+
+	if k := p.Key(99); k.Precision != 15 {
+		t.Errorf("Expected the precision to be clamped to 15, but got %d", k.Precision)
+	}
+}
+
+func TestDedupPoints(t *testing.T) {
+	a := NewPoint(40.7486, -73.9864)
+	b := NewPoint(40.74861, -73.9864) // ~1 meter from a
+	c := NewPoint(47.4489, -122.3094)
+	d := NewPoint(40.7487, -73.9864) // ~11 meters from a
+	e := NewPoint(47.44891, -122.3094)
+
+	res := DedupPoints([]*Point{a, b, c, d, e}, 5)
+
+	expected := []*Point{a, c, d}
+	if len(res) != len(expected) {
+		t.Fatalf("Mismatched deduplicated points.  Expected: %v, Actual: %v", expected, res)
+	}
+
+	for i := range expected {
+		if res[i] != expected[i] {
+			t.Errorf("Mismatched deduplicated points.  Expected: %v, Actual: %v", expected, res)
+		}
+	}
+
+	if len(DedupPoints([]*Point{a, b, d}, 0)) != 3 {
+		t.Error("Expected a tolerance of 0 to only remove exact duplicates")
+	}
+
+	if len(DedupPoints([]*Point{a, NewPoint(40.7486, -73.9864)}, 0)) != 1 {
+		t.Error("Expected exact duplicates to be removed with a tolerance of 0")
+	}
+}
+
+// Ensures that duplicates straddling a geohash cell boundary are still found.
+func TestDedupPointsAcrossCells(t *testing.T) {
+	// Either side of the prime meridian and the equator.
+	a := NewPoint(0.00001, -0.00001)
+	b := NewPoint(-0.00001, 0.00001)
+
+	if len(DedupPoints([]*Point{a, b}, 10)) != 1 {
+		t.Error("Expected points on either side of a cell boundary to be deduplicated")
+	}
+}
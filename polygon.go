@@ -10,6 +10,9 @@ import (
 // It can thus contain holes, and can be self-intersecting.
 type Polygon struct {
 	points []*Point
+
+	// The bounding box of the points, kept up to date by Add, or nil if there are none.
+	box *BoundingBox
 }
 
 // Creates and returns a new pointer to a Polygon
@@ -17,7 +20,31 @@ type Polygon struct {
 // considered to be in order such that the last point
 // forms an edge with the first point.
 func NewPolygon(points []*Point) *Polygon {
-	return &Polygon{points: points}
+	p := &Polygon{points: points}
+	for _, point := range points {
+		p.extendBoundingBox(point)
+	}
+
+	return p
+}
+
+// Creates and returns a new pointer to a Polygon that approximates the geodesic circle
+// of the passed in radius (in meters) around the passed in center, such as a geofence for "within 5km",
+// with the passed in number of segments.  The vertices lie on the circle, clockwise from due north,
+// so the segments cut slightly inside of it; more segments give a smoother, closer outline.
+// As with any Polygon, circles that cross the antimeridian or enclose a pole are not contained correctly.
+// Returns nil if there are fewer than 3 segments.
+func CirclePolygon(center *Point, radiusMeters float64, segments int) *Polygon {
+	if segments < 3 {
+		return nil
+	}
+
+	points := make([]*Point, segments)
+	for i := range points {
+		points[i] = center.PointAtDistanceAndBearing(radiusMeters/1000, 360*float64(i)/float64(segments))
+	}
+
+	return NewPolygon(points)
 }
 
 // Returns the points of the current Polygon.
@@ -28,6 +55,28 @@ func (p *Polygon) Points() []*Point {
 // Appends the passed in contour to the current Polygon.
 func (p *Polygon) Add(point *Point) {
 	p.points = append(p.points, point)
+	p.extendBoundingBox(point)
+}
+
+// Grows the bounding box of the current Polygon to cover the passed in Point.
+// A new BoundingBox replaces the current one, so that boxes already returned by BoundingBox are left as they were.
+func (p *Polygon) extendBoundingBox(point *Point) {
+	if point == nil {
+		return
+	}
+
+	if p.box == nil {
+		p.box = NewBoundingBox(point, point)
+		return
+	}
+
+	if p.box.Contains(point) {
+		return
+	}
+
+	sw := NewPoint(math.Min(p.box.sw.lat, point.lat), math.Min(p.box.sw.lng, point.lng))
+	ne := NewPoint(math.Max(p.box.ne.lat, point.lat), math.Max(p.box.ne.lng, point.lng))
+	p.box = NewBoundingBox(sw, ne)
 }
 
 // Returns whether or not the polygon is closed.
@@ -42,6 +91,327 @@ func (p *Polygon) IsClosed() bool {
 	return true
 }
 
+// Returns whether or not the current Polygon is simple, that is, closed and without any edges that cross or overlap,
+// since Contains gives unreliable results for self-intersecting polygons.
+// Consecutive repeated points, such as a last point that repeats the first, are ignored.
+// Edges are treated as straight lines in the lat / lng plane, as they are by Contains.
+// Every pair of edges is compared, so this takes time quadratic in the number of points.
+func (p *Polygon) IsSimple() bool {
+	points := []*Point{}
+	for i, point := range p.points {
+		if !point.Equal(p.points[(i+1)%len(p.points)]) {
+			points = append(points, point)
+		}
+	}
+
+	n := len(points)
+	if n < 3 {
+		return false
+	}
+
+	for i := 0; i < n; i++ {
+		a, b := points[i], points[(i+1)%n]
+
+		// Adjacent edges always share a point, so they only break simplicity if they double back over each other.
+		if next := points[(i+2)%n]; planarCross(b, a, next) == 0 && planarDot(b, a, next) > 0 {
+			return false
+		}
+
+		for j := i + 2; j < n; j++ {
+			if i == 0 && j == n-1 {
+				continue
+			}
+
+			if segmentsIntersect(a, b, points[j], points[(j+1)%n]) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Returns whether or not the current Polygon and the passed in Polygon overlap, that is,
+// whether any of their edges cross, or one of them lies entirely inside of the other.
+// Polygons that only touch, sharing an edge or a single point, also count as intersecting.
+// Edges are treated as straight lines in the lat / lng plane, as they are by Contains.
+// Polygons whose bounding boxes do not overlap are ruled out before any of their edges are compared.
+// Polygons that are not closed never intersect.
+func (p *Polygon) Intersects(other *Polygon) bool {
+	if !p.IsClosed() || !other.IsClosed() {
+		return false
+	}
+
+	if !p.BoundingBox().Intersects(other.BoundingBox()) {
+		return false
+	}
+
+	for i, a := range p.points {
+		b := p.points[(i+1)%len(p.points)]
+		for j, c := range other.points {
+			if segmentsIntersect(a, b, c, other.points[(j+1)%len(other.points)]) {
+				return true
+			}
+		}
+	}
+
+	// With no edges crossing, either polygon is entirely inside of the other or they are apart.
+	return p.Contains(other.points[0]) || other.Contains(p.points[0])
+}
+
+// The number of samples along each side of the bounding box that CoverageFraction takes.
+const DefaultCoverageSamples = 100
+
+// Returns the approximate fraction, from 0 to 1, of the area of the passed in Polygon that lies inside of the current one,
+// such as how much of a delivery zone is in a flood zone.  It is estimated by CoverageFractionWithSamples,
+// with DefaultCoverageSamples samples along each side of the passed in Polygon's bounding box.
+func (p *Polygon) CoverageFraction(other *Polygon) float64 {
+	return p.CoverageFractionWithSamples(other, DefaultCoverageSamples)
+}
+
+// Returns the approximate fraction, from 0 to 1, of the area of the passed in Polygon that lies inside of the current one,
+// by sampling a regular grid over the passed in Polygon's bounding box, with the passed in number of samples along each side.
+// Of the samples that the passed in Polygon contains, the fraction that the current one also contains is returned,
+// each weighted by the cosine of its latitude, since the cells of the grid shrink towards the poles.
+// It is an estimate: its error depends on how much of the boundaries a grid cell straddles, roughly in proportion to 1 / samples,
+// while its cost grows with samples squared.  The default 100 is usually within a percent or two.
+// Returns 0 if the passed in Polygon has no area, if no samples land inside of it, or if the samples are not positive.
+func (p *Polygon) CoverageFractionWithSamples(other *Polygon, samples int) float64 {
+	if samples <= 0 || other.Area() == 0 {
+		return 0
+	}
+
+	box := other.BoundingBox()
+	if !p.IsClosed() || !p.BoundingBox().Intersects(box) {
+		return 0
+	}
+
+	height := (box.ne.lat - box.sw.lat) / float64(samples)
+	width := (box.ne.lng - box.sw.lng) / float64(samples)
+
+	inside, covered := 0.0, 0.0
+	for i := 0; i < samples; i++ {
+		lat := box.sw.lat + (float64(i)+0.5)*height
+		weight := math.Cos(DegreesToRadians(lat))
+
+		for j := 0; j < samples; j++ {
+			point := NewPoint(lat, box.sw.lng+(float64(j)+0.5)*width)
+			if !other.Contains(point) {
+				continue
+			}
+
+			inside += weight
+			if p.Contains(point) {
+				covered += weight
+			}
+		}
+	}
+
+	if inside == 0 {
+		return 0
+	}
+
+	return covered / inside
+}
+
+// Returns the z component of the cross product of the vectors from the passed in origin to a and to b,
+// in the lat / lng plane.  It is positive if b is counterclockwise from a, negative if clockwise, and zero if collinear.
+func planarCross(origin *Point, a *Point, b *Point) float64 {
+	return (a.lng-origin.lng)*(b.lat-origin.lat) - (a.lat-origin.lat)*(b.lng-origin.lng)
+}
+
+// Returns the dot product of the vectors from the passed in origin to a and to b, in the lat / lng plane.
+func planarDot(origin *Point, a *Point, b *Point) float64 {
+	return (a.lng-origin.lng)*(b.lng-origin.lng) + (a.lat-origin.lat)*(b.lat-origin.lat)
+}
+
+// Returns whether or not the segment from a to b and the segment from c to d touch or cross in the lat / lng plane.
+func segmentsIntersect(a *Point, b *Point, c *Point, d *Point) bool {
+	d1 := planarCross(c, d, a)
+	d2 := planarCross(c, d, b)
+	d3 := planarCross(a, b, c)
+	d4 := planarCross(a, b, d)
+
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+
+	return (d1 == 0 && onSegment(c, d, a)) ||
+		(d2 == 0 && onSegment(c, d, b)) ||
+		(d3 == 0 && onSegment(a, b, c)) ||
+		(d4 == 0 && onSegment(a, b, d))
+}
+
+// Returns whether or not the passed in point, known to be collinear with the segment from a to b, lies on that segment.
+func onSegment(a *Point, b *Point, point *Point) bool {
+	return math.Min(a.lng, b.lng) <= point.lng && point.lng <= math.Max(a.lng, b.lng) &&
+		math.Min(a.lat, b.lat) <= point.lat && point.lat <= math.Max(a.lat, b.lat)
+}
+
+// Returns the smallest BoundingBox that contains every point of the current Polygon,
+// or nil if the Polygon has no points.
+// The box is kept as points are added rather than computed on each call, so it is cheap enough to rule out
+// points and polygons before testing them in full.  Changes made to the slice returned by Points are not reflected in it.
+func (p *Polygon) BoundingBox() *BoundingBox {
+	return p.box
+}
+
+// Returns the area of the current Polygon on the surface of the Earth, in square kilometers,
+// with its edges treated as straight lines in the lat / lng plane, as they are by Contains.
+// The area is exact for polygons whose edges follow meridians and parallels, such as bounding boxes,
+// and otherwise close for edges that are short compared to the size of the Earth.
+// The Polygon is treated as a single ring, and has no area if it has fewer than 3 points.
+// Original Implementation from: Chamberlain and Duquette, "Some Algorithms for Polygons on a Sphere" (2007)
+func (p *Polygon) Area() float64 {
+	if len(p.points) < 3 {
+		return 0
+	}
+
+	sum := 0.0
+	for i, a := range p.points {
+		b := p.points[(i+1)%len(p.points)]
+		dLng := DegreesToRadians(b.lng - a.lng)
+		sum += dLng * (2 + math.Sin(DegreesToRadians(a.lat)) + math.Sin(DegreesToRadians(b.lat)))
+	}
+
+	return math.Abs(sum) * EARTH_RADIUS * EARTH_RADIUS / 2
+}
+
+// Returns the centroid (center of mass) of the current Polygon, such as a point to place its label at,
+// with its edges treated as straight lines in the lat / lng plane, as they are by Contains.
+// A concave Polygon's centroid may lie outside of it.  The Polygon is treated as a single ring.
+// A Polygon whose points are all in a line has no area, and returns the mean of its points instead,
+// and a Polygon with no points returns nil.
+func (p *Polygon) Centroid() *Point {
+	if len(p.points) == 0 {
+		return nil
+	}
+
+	area, lat, lng := 0.0, 0.0, 0.0
+	for i, a := range p.points {
+		b := p.points[(i+1)%len(p.points)]
+		cross := a.lng*b.lat - b.lng*a.lat
+		area += cross
+		lat += (a.lat + b.lat) * cross
+		lng += (a.lng + b.lng) * cross
+	}
+
+	if area == 0 {
+		for _, point := range p.points {
+			lat += point.lat
+			lng += point.lng
+		}
+
+		return NewPoint(lat/float64(len(p.points)), lng/float64(len(p.points)))
+	}
+
+	return NewPoint(lat/(3*area), lng/(3*area))
+}
+
+// Returns the centroid of the passed in polygons taken together, such as a point to label a feature made up of several regions at.
+// Each Polygon's Centroid is weighted by its Area, so that large parts pull the centroid towards them more than small ones do,
+// unlike a plain mean of the centroids or of the points.  Polygons without any area are ignored.
+// Returns nil if there are no polygons with any area.
+func CentroidOfPolygons(polys []*Polygon) *Point {
+	total, lat, lng := 0.0, 0.0, 0.0
+	for _, poly := range polys {
+		if poly == nil {
+			continue
+		}
+
+		area := poly.Area()
+		if area == 0 {
+			continue
+		}
+
+		c := poly.Centroid()
+		total += area
+		lat += c.lat * area
+		lng += c.lng * area
+	}
+
+	if total == 0 {
+		return nil
+	}
+
+	return NewPoint(lat/total, lng/total)
+}
+
+// Returns the point on the edges of the current Polygon that is closest to the passed in Point,
+// along with the great circle distance to it (in kilometers).
+// Each edge, including the one from the last point back to the first, is treated as a great circle segment,
+// and the passed in Point is projected onto it using cross-track and along-track distances.
+// A Polygon with a single point returns that point, and a Polygon with no points returns nil.
+// Original Implementation from: http://www.movable-type.co.uk/scripts/latlong.html
+func (p *Polygon) NearestBoundaryPoint(point *Point) (*Point, float64) {
+	if len(p.points) == 0 {
+		return nil, 0
+	}
+
+	nearest := p.points[0]
+	distance := point.GreatCircleDistance(nearest)
+
+	for i := range p.points {
+		start := p.points[i]
+		end := p.points[(i+1)%len(p.points)]
+
+		candidate := nearestPointOnSegment(point, start, end)
+		if d := point.GreatCircleDistance(candidate); d < distance {
+			nearest, distance = candidate, d
+		}
+	}
+
+	return nearest, distance
+}
+
+// Returns the distance, in the passed in unit, from the passed in Point to the current Polygon:
+// 0 if the Polygon contains the Point, and otherwise the great circle distance to its nearest edge.
+// Returns positive infinity for a Polygon with no points.
+func (p *Polygon) DistanceTo(point *Point, unit Unit) float64 {
+	if p.Contains(point) {
+		return 0
+	}
+
+	nearest, km := p.NearestBoundaryPoint(point)
+	if nearest == nil {
+		return math.Inf(1)
+	}
+
+	return km * 1000 / unit.meters()
+}
+
+// Returns the point on the great circle segment between the passed in start and end points
+// that is closest to the passed in Point.
+func nearestPointOnSegment(point *Point, start *Point, end *Point) *Point {
+	segment := start.centralAngle(end)
+	if segment == 0 {
+		return start
+	}
+
+	toPoint := start.centralAngle(point)
+	bearing := DegreesToRadians(start.BearingTo(end))
+	bearingToPoint := DegreesToRadians(start.BearingTo(point))
+
+	crossTrack := math.Asin(math.Sin(toPoint) * math.Sin(bearingToPoint-bearing))
+
+	cosAlongTrack := math.Max(-1, math.Min(1, math.Cos(toPoint)/math.Cos(crossTrack)))
+	alongTrack := math.Acos(cosAlongTrack)
+	if math.Cos(bearingToPoint-bearing) < 0 {
+		alongTrack = -alongTrack
+	}
+
+	// Points that project beyond either end of the segment are closest to that end.
+	if alongTrack <= 0 {
+		return start
+	}
+
+	if alongTrack >= segment {
+		return end
+	}
+
+	return start.PointAtDistanceAndBearing(alongTrack*EARTH_RADIUS, RadiansToDegrees(bearing))
+}
+
 // Returns whether or not the current Polygon contains the passed in Point.
 func (p *Polygon) Contains(point *Point) bool {
 	if !p.IsClosed() {
@@ -62,6 +432,29 @@ func (p *Polygon) Contains(point *Point) bool {
 	return contains
 }
 
+// Returns the indices, in ascending order, of every one of the passed in polygons that contains the passed in Point,
+// since regions such as sales territories may overlap.  Returns an empty slice if none of them do.
+// Each polygon's bounding box is checked before the full Contains test, so polygons far from the point are quickly ruled out.
+func WhichPolygonsContain(p *Point, polys []*Polygon) []int {
+	indices := []int{}
+	for i, poly := range polys {
+		if poly == nil {
+			continue
+		}
+
+		box := poly.BoundingBox()
+		if box == nil || !box.Contains(p) {
+			continue
+		}
+
+		if poly.Contains(p) {
+			indices = append(indices, i)
+		}
+	}
+
+	return indices
+}
+
 // Using the raycast algorithm, this returns whether or not the passed in point
 // Intersects with the edge drawn by the passed in start and end points.
 // Original implementation: http://rosettacode.org/wiki/Ray-casting_algorithm#Go
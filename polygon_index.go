@@ -0,0 +1,124 @@
+package geo
+
+import (
+	"math"
+	"sync"
+)
+
+// The size, in degrees of latitude and longitude, of the grid cells that a PolygonIndex buckets polygons into.
+const polygonIndexCellDegrees = 10
+
+// A PolygonIndex answers whether points lie inside any of a large, fixed set of polygons, such as landmasses,
+// without testing every polygon for every point.
+// Polygons are bucketed by the grid cells their bounding boxes overlap, so only the few polygons near a point
+// are checked against their bounding box, and only those whose box contains it are tested in full.
+// The index reflects the polygons as they were when it was built, so Points added to them later are not taken into account.
+type PolygonIndex struct {
+	polygons []*Polygon
+	boxes    []*BoundingBox
+	cells    map[int][]int
+}
+
+// Creates and returns a pointer to a new PolygonIndex of the passed in polygons.
+// Nil polygons, and those with fewer than 3 points, contain nothing, and are left out.
+func NewPolygonIndex(polys []*Polygon) *PolygonIndex {
+	idx := &PolygonIndex{cells: map[int][]int{}}
+	for _, poly := range polys {
+		if poly == nil || len(poly.points) < 3 {
+			continue
+		}
+
+		box := poly.BoundingBox()
+		i := len(idx.polygons)
+		idx.polygons = append(idx.polygons, poly)
+		idx.boxes = append(idx.boxes, box)
+
+		minRow, minCol := polygonIndexCell(box.sw)
+		maxRow, maxCol := polygonIndexCell(box.ne)
+		for row := minRow; row <= maxRow; row++ {
+			for col := minCol; col <= maxCol; col++ {
+				cell := row*polygonIndexCols + col
+				idx.cells[cell] = append(idx.cells[cell], i)
+			}
+		}
+	}
+
+	return idx
+}
+
+// The number of grid columns a PolygonIndex has, across all longitudes.
+const polygonIndexCols = 360 / polygonIndexCellDegrees
+
+// Returns the row and column of the grid cell of a PolygonIndex that the passed in Point falls in.
+// Points on the edge of the map fall in the cells along it.
+func polygonIndexCell(p *Point) (int, int) {
+	row := int(math.Floor((p.lat + 90) / polygonIndexCellDegrees))
+	col := int(math.Floor((p.lng + 180) / polygonIndexCellDegrees))
+
+	row = int(math.Max(0, math.Min(float64(180/polygonIndexCellDegrees-1), float64(row))))
+	col = int(math.Max(0, math.Min(float64(polygonIndexCols-1), float64(col))))
+
+	return row, col
+}
+
+// Returns whether or not any of the polygons of the current PolygonIndex contains the passed in Point.
+func (idx *PolygonIndex) Contains(p *Point) bool {
+	row, col := polygonIndexCell(p)
+	for _, i := range idx.cells[row*polygonIndexCols+col] {
+		if idx.boxes[i].Contains(p) && idx.polygons[i].Contains(p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns whether or not the passed in Point lies inside any of the passed in landmass polygons,
+// such as to discard GPS fixes that error has placed out at sea.
+// The landmasses are indexed, as by NewMultiPolygon, the first time a slice of them is passed,
+// and the index is reused for every later call with the same slice,
+// so that each point is only tested against the polygons in its own cell of the index.
+// Pass a new slice, rather than changing the polygons in one already passed, when the landmasses change.
+// Returns false if there are no landmasses.
+func OnLand(p *Point, land []*Polygon) bool {
+	if len(land) == 0 {
+		return false
+	}
+
+	return cachedLandIndex(land).Contains(p)
+}
+
+// The most landmass indexes kept by cachedLandIndex.  Beyond it, the cache is emptied and starts over.
+const maxCachedLandIndexes = 16
+
+// Identifies a slice of landmasses passed to OnLand by the address of its first element and its length.
+type landIndexKey struct {
+	first **Polygon
+	n     int
+}
+
+// The PolygonIndexes made by cachedLandIndex, by the slice of landmasses they index.
+var landIndexCache = struct {
+	sync.Mutex
+	indexes map[landIndexKey]*PolygonIndex
+}{indexes: map[landIndexKey]*PolygonIndex{}}
+
+// Returns the PolygonIndex of the passed in non-empty slice of landmasses, made once and shared by every call with it.
+func cachedLandIndex(land []*Polygon) *PolygonIndex {
+	key := landIndexKey{first: &land[0], n: len(land)}
+
+	landIndexCache.Lock()
+	defer landIndexCache.Unlock()
+
+	if idx, ok := landIndexCache.indexes[key]; ok {
+		return idx
+	}
+
+	idx := NewPolygonIndex(land)
+	if len(landIndexCache.indexes) >= maxCachedLandIndexes {
+		landIndexCache.indexes = map[landIndexKey]*PolygonIndex{}
+	}
+	landIndexCache.indexes[key] = idx
+
+	return idx
+}
@@ -0,0 +1,98 @@
+package geo
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Returns a set of polygons scattered across the map, including ones spanning several grid cells and one at its edge.
+func polygonIndexTestPolygons() []*Polygon {
+	polys := []*Polygon{
+		squarePolygon(-5, -5, 30),
+		squarePolygon(80, 170, 10),
+		nil,
+		NewPolygon([]*Point{NewPoint(0, 0), NewPoint(1, 1)}),
+	}
+
+	brunei, err := polygonFromFile("test/data/brunei.json")
+	if err == nil {
+		polys = append(polys, brunei)
+	}
+
+	for lat := -60.0; lat < 60; lat += 17 {
+		for lng := -170.0; lng < 170; lng += 23 {
+			polys = append(polys, squarePolygon(lat, lng, 3))
+		}
+	}
+
+	return polys
+}
+
+func TestOnLand(t *testing.T) {
+	land := polygonIndexTestPolygons()
+
+	if !OnLand(NewPoint(10, 10), land) || !OnLand(NewPoint(85, 175), land) || !OnLand(NewPoint(4.5, 114.7), land) {
+		t.Error("Expected points inside the landmasses to be on land")
+	}
+
+	if OnLand(NewPoint(-30, -30), land) || OnLand(NewPoint(50, -100), land) {
+		t.Error("Expected points between the landmasses not to be on land")
+	}
+
+	if OnLand(NewPoint(0, 0), nil) || OnLand(NewPoint(0, 0), []*Polygon{}) || OnLand(NewPoint(0, 0), []*Polygon{nil}) {
+		t.Error("Expected no point to be on land without any landmasses")
+	}
+
+	// The index is made once for each slice of landmasses, and a new slice gets a new index.
+	if cachedLandIndex(land) != cachedLandIndex(land) {
+		t.Error("Expected the index of the landmasses to be reused")
+	}
+
+	moved := []*Polygon{squarePolygon(-40, -40, 20)}
+	if !OnLand(NewPoint(-30, -30), moved) || OnLand(NewPoint(10, 10), moved) {
+		t.Error("Expected a new slice of landmasses to be indexed afresh")
+	}
+}
+
+// Ensures that a PolygonIndex agrees with testing every polygon, everywhere on the map.
+func TestPolygonIndexContains(t *testing.T) {
+	land := polygonIndexTestPolygons()
+	idx := NewPolygonIndex(land)
+
+	r := rand.New(rand.NewSource(1))
+	points := []*Point{NewPoint(90, 180), NewPoint(-90, -180), NewPoint(85, 180), NewPoint(4.5, 114.7)}
+	for i := 0; i < 5000; i++ {
+		points = append(points, NewPoint(r.Float64()*180-90, r.Float64()*360-180))
+	}
+
+	for _, p := range points {
+		expected := len(WhichPolygonsContain(p, land)) > 0
+		if idx.Contains(p) != expected {
+			t.Errorf("Mismatched containment of %v.  Expected: %t.  Actual: %t", p, expected, idx.Contains(p))
+		}
+	}
+
+	if NewPolygonIndex(nil).Contains(NewPoint(0, 0)) {
+		t.Error("Expected an empty index to contain nothing")
+	}
+}
+
+func BenchmarkOnLand(b *testing.B) {
+	land := polygonIndexTestPolygons()
+	p := NewPoint(50, -100)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		OnLand(p, land)
+	}
+}
+
+func BenchmarkPolygonIndexContains(b *testing.B) {
+	idx := NewPolygonIndex(polygonIndexTestPolygons())
+	p := NewPoint(50, -100)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		idx.Contains(p)
+	}
+}
@@ -2,6 +2,8 @@ package geo
 
 import (
 	"encoding/json"
+	"fmt"
+	"math"
 	"os"
 	"testing"
 )
@@ -128,6 +130,344 @@ func TestEquatorGreenwichContains(t *testing.T) {
 	}
 }
 
+// Ensures that points are snapped to the nearest point on a polygon's edges.
+// The square's edges follow the equator and meridians, which are great circles.
+func TestNearestBoundaryPoint(t *testing.T) {
+	square := NewPolygon([]*Point{
+		NewPoint(0, 0),
+		NewPoint(0, 1),
+		NewPoint(1, 1),
+		NewPoint(1, 0),
+	})
+
+	tests := []struct {
+		point    *Point
+		expected *Point
+	}{
+		// Outside, beside an edge.
+		{NewPoint(0.5, 1.5), NewPoint(0.5, 1)},
+		{NewPoint(-0.5, 0.5), NewPoint(0, 0.5)},
+		// Outside, beyond a corner.
+		{NewPoint(-1, -1), NewPoint(0, 0)},
+		{NewPoint(1.2, 1.3), NewPoint(1, 1)},
+		// Inside, nearest the closing edge from the last point back to the first.
+		{NewPoint(0.5, 0.1), NewPoint(0.5, 0)},
+		// On a vertex.
+		{NewPoint(1, 0), NewPoint(1, 0)},
+	}
+
+	for _, test := range tests {
+		// Off the equator, the perpendicular to a meridian meets it slightly poleward of the point's latitude,
+		// so allow for a few meters.
+		nearest, dist := square.NearestBoundaryPoint(test.point)
+		if nearest.GreatCircleDistance(test.expected) > 0.01 {
+			t.Errorf("Mismatched nearest boundary point to %v.  Expected: %v.  Actual: %v", test.point, test.expected, nearest)
+		}
+
+		if expected := test.point.GreatCircleDistance(test.expected); math.Abs(dist-expected) > 0.001 {
+			t.Errorf("Mismatched distance to the boundary from %v.  Expected: %f.  Actual: %f", test.point, expected, dist)
+		}
+	}
+}
+
+// Ensures that degenerate polygons snap to their only vertex, or to nothing at all.
+func TestNearestBoundaryPointDegenerate(t *testing.T) {
+	vertex := NewPoint(40.7486, -73.9864)
+	point := NewPoint(40.7, -74.0)
+
+	nearest, dist := NewPolygon([]*Point{vertex}).NearestBoundaryPoint(point)
+	if nearest != vertex {
+		t.Errorf("Mismatched nearest boundary point.  Expected: %v.  Actual: %v", vertex, nearest)
+	}
+
+	if expected := point.GreatCircleDistance(vertex); dist != expected {
+		t.Errorf("Mismatched distance to the boundary.  Expected: %f.  Actual: %f", expected, dist)
+	}
+
+	if nearest, _ := NewPolygon([]*Point{}).NearestBoundaryPoint(point); nearest != nil {
+		t.Errorf("Expected an empty polygon to have no nearest boundary point.  Actual: %v", nearest)
+	}
+}
+
+// Ensures that the distance to a polygon is 0 inside it, and the distance to its nearest edge outside of it.
+func TestPolygonDistanceTo(t *testing.T) {
+	square := NewPolygon([]*Point{
+		NewPoint(0, 0),
+		NewPoint(0, 1),
+		NewPoint(1, 1),
+		NewPoint(1, 0),
+	})
+
+	if d := square.DistanceTo(NewPoint(0.5, 0.5), Kilometers); d != 0 {
+		t.Errorf("Mismatched distance from inside the polygon.  Expected: 0.  Actual: %f", d)
+	}
+
+	outside := NewPoint(-0.5, 0.5)
+	expected := outside.GreatCircleDistance(NewPoint(0, 0.5))
+	if d := square.DistanceTo(outside, Kilometers); math.Abs(d-expected) > 0.001 {
+		t.Errorf("Mismatched distance in kilometers.  Expected: %f.  Actual: %f", expected, d)
+	}
+
+	if d := square.DistanceTo(outside, Miles); math.Abs(d-expected/1.609344) > 0.001 {
+		t.Errorf("Mismatched distance in miles.  Expected: %f.  Actual: %f", expected/1.609344, d)
+	}
+
+	if d := NewPolygon([]*Point{}).DistanceTo(outside, Kilometers); !math.IsInf(d, 1) {
+		t.Errorf("Expected an infinite distance to an empty polygon.  Actual: %f", d)
+	}
+}
+
+// Ensures that a circle's vertices lie on it, and that it contains what is within its radius.
+func TestCirclePolygon(t *testing.T) {
+	center := NewPoint(40.7486, -73.9864)
+	circle := CirclePolygon(center, 5000, 64)
+
+	if len(circle.Points()) != 64 {
+		t.Fatalf("Mismatched number of points.  Expected: 64.  Actual: %d", len(circle.Points()))
+	}
+
+	for _, p := range circle.Points() {
+		if d := center.GreatCircleDistance(p) * 1000; math.Abs(d-5000) > 0.01 {
+			t.Errorf("Expected %v to be 5000 meters from the center.  Actual: %f", p, d)
+		}
+	}
+
+	if !circle.IsSimple() {
+		t.Error("Expected the circle to be a simple polygon")
+	}
+
+	for _, bearing := range []float64{0, 45, 100, 200, 300} {
+		if inside := center.PointAtDistanceAndBearing(4.9, bearing); !circle.Contains(inside) {
+			t.Errorf("Expected the circle to contain %v", inside)
+		}
+
+		if outside := center.PointAtDistanceAndBearing(5.1, bearing); circle.Contains(outside) {
+			t.Errorf("Expected the circle not to contain %v", outside)
+		}
+	}
+
+	if CirclePolygon(center, 5000, 2) != nil {
+		t.Error("Expected a circle of fewer than 3 segments to be nil")
+	}
+
+	if triangle := CirclePolygon(center, 5000, 3); len(triangle.Points()) != 3 {
+		t.Errorf("Mismatched number of points.  Expected: 3.  Actual: %d", len(triangle.Points()))
+	}
+}
+
+// Ensures that self-intersecting polygons are detected.
+func TestIsSimple(t *testing.T) {
+	brunei, err := polygonFromFile("test/data/brunei.json")
+	if err != nil {
+		t.Error("brunei json file failed to parse: ", err)
+	}
+
+	tests := []struct {
+		name     string
+		polygon  *Polygon
+		expected bool
+	}{
+		{"brunei, whose last point repeats its first", brunei, true},
+		{"square", NewPolygon([]*Point{NewPoint(0, 0), NewPoint(0, 1), NewPoint(1, 1), NewPoint(1, 0)}), true},
+		{"concave", NewPolygon([]*Point{NewPoint(0, 0), NewPoint(0, 2), NewPoint(2, 2), NewPoint(1, 1), NewPoint(2, 0)}), true},
+		{"bowtie", NewPolygon([]*Point{NewPoint(0, 0), NewPoint(1, 1), NewPoint(1, 0), NewPoint(0, 1)}), false},
+		{"figure eight touching at a vertex", NewPolygon([]*Point{
+			NewPoint(0, 0), NewPoint(1, 1), NewPoint(0, 2), NewPoint(2, 2), NewPoint(1, 1), NewPoint(2, 0),
+		}), false},
+		{"vertex touching another edge", NewPolygon([]*Point{
+			NewPoint(0, 0), NewPoint(0, 2), NewPoint(2, 2), NewPoint(0, 1), NewPoint(2, 0),
+		}), false},
+		{"edge doubling back over the previous one", NewPolygon([]*Point{
+			NewPoint(0, 0), NewPoint(0, 2), NewPoint(0, 1), NewPoint(1, 1),
+		}), false},
+		{"collinear points", NewPolygon([]*Point{NewPoint(0, 0), NewPoint(0, 2), NewPoint(0, 1)}), false},
+		{"too few points", NewPolygon([]*Point{NewPoint(0, 0), NewPoint(0, 1), NewPoint(0, 0)}), false},
+	}
+
+	for _, test := range tests {
+		if res := test.polygon.IsSimple(); res != test.expected {
+			t.Errorf("Mismatched simplicity for %s.  Expected: %t.  Actual: %t", test.name, test.expected, res)
+		}
+	}
+}
+
+func TestPolygonIntersects(t *testing.T) {
+	brunei, err := polygonFromFile("test/data/brunei.json")
+	if err != nil {
+		t.Error("brunei json file failed to parse: ", err)
+	}
+
+	square := NewPolygon([]*Point{NewPoint(0, 0), NewPoint(0, 2), NewPoint(2, 2), NewPoint(2, 0)})
+
+	tests := []struct {
+		name     string
+		other    *Polygon
+		expected bool
+	}{
+		{"overlapping", NewPolygon([]*Point{NewPoint(1, 1), NewPoint(1, 3), NewPoint(3, 3), NewPoint(3, 1)}), true},
+		{"crossing without sharing a vertex", NewPolygon([]*Point{NewPoint(-1, 0.5), NewPoint(-1, 1.5), NewPoint(3, 1.5), NewPoint(3, 0.5)}), true},
+		{"inside", NewPolygon([]*Point{NewPoint(0.5, 0.5), NewPoint(0.5, 1.5), NewPoint(1.5, 1.5)}), true},
+		{"sharing an edge", NewPolygon([]*Point{NewPoint(0, 2), NewPoint(0, 4), NewPoint(2, 4), NewPoint(2, 2)}), true},
+		{"touching at a corner", NewPolygon([]*Point{NewPoint(2, 2), NewPoint(2, 4), NewPoint(4, 4), NewPoint(4, 2)}), true},
+		{"apart within the bounding box", NewPolygon([]*Point{NewPoint(1.9, 2.5), NewPoint(3, 2.5), NewPoint(3, 1.1)}), false},
+		{"far away", brunei, false},
+		{"not closed", NewPolygon([]*Point{NewPoint(1, 1), NewPoint(1, 3)}), false},
+	}
+
+	for _, test := range tests {
+		if res := square.Intersects(test.other); res != test.expected {
+			t.Errorf("Mismatched intersection with the %s polygon.  Expected: %t.  Actual: %t", test.name, test.expected, res)
+		}
+
+		if res := test.other.Intersects(square); res != test.expected {
+			t.Errorf("Mismatched intersection of the %s polygon.  Expected: %t.  Actual: %t", test.name, test.expected, res)
+		}
+	}
+}
+
+func TestPolygonArea(t *testing.T) {
+	// A one degree square on the equator.
+	square := NewPolygon([]*Point{NewPoint(0, 0), NewPoint(0, 1), NewPoint(1, 1), NewPoint(1, 0)})
+	expected := EARTH_RADIUS * EARTH_RADIUS * math.Pi / 180 * math.Sin(math.Pi/180)
+	if res := square.Area(); math.Abs(res-expected) > 1e-6 {
+		t.Errorf("Mismatched area.  Expected: %f.  Actual: %f", expected, res)
+	}
+
+	// The winding order does not matter.
+	reversed := NewPolygon([]*Point{NewPoint(1, 0), NewPoint(1, 1), NewPoint(0, 1), NewPoint(0, 0)})
+	if res := reversed.Area(); math.Abs(res-expected) > 1e-6 {
+		t.Errorf("Mismatched area of the reversed square.  Expected: %f.  Actual: %f", expected, res)
+	}
+
+	// The fixture's coarse outline of Brunei covers about 10,747 square kilometers,
+	// by the planar shoelace formula scaled at its mean latitude.
+	brunei, err := polygonFromFile("test/data/brunei.json")
+	if err != nil {
+		t.Error("brunei json file failed to parse: ", err)
+	}
+
+	if res := brunei.Area(); math.Abs(res-10747)/10747 > 0.005 {
+		t.Errorf("Mismatched area of Brunei.  Expected: about 10747.  Actual: %f", res)
+	}
+
+	if res := NewPolygon([]*Point{NewPoint(0, 0), NewPoint(1, 1)}).Area(); res != 0 {
+		t.Errorf("Expected a polygon of 2 points to have no area.  Actual: %f", res)
+	}
+}
+
+func TestPolygonCentroid(t *testing.T) {
+	tests := []struct {
+		name     string
+		polygon  *Polygon
+		expected *Point
+	}{
+		{"square", NewPolygon([]*Point{NewPoint(0, 0), NewPoint(0, 2), NewPoint(2, 2), NewPoint(2, 0)}), NewPoint(1, 1)},
+		{"triangle", NewPolygon([]*Point{NewPoint(0, 0), NewPoint(0, 3), NewPoint(3, 0)}), NewPoint(1, 1)},
+		{"L shape", NewPolygon([]*Point{
+			NewPoint(0, 0), NewPoint(0, 2), NewPoint(1, 2), NewPoint(1, 1), NewPoint(2, 1), NewPoint(2, 0),
+		}), NewPoint(5.0/6, 5.0/6)},
+		{"collinear", NewPolygon([]*Point{NewPoint(0, 0), NewPoint(0, 1), NewPoint(0, 5)}), NewPoint(0, 2)},
+	}
+
+	for _, test := range tests {
+		res := test.polygon.Centroid()
+		if math.Abs(res.lat-test.expected.lat) > 1e-9 || math.Abs(res.lng-test.expected.lng) > 1e-9 {
+			t.Errorf("Mismatched centroid of the %s.  Expected: %v.  Actual: %v", test.name, test.expected, res)
+		}
+	}
+
+	if res := NewPolygon([]*Point{}).Centroid(); res != nil {
+		t.Errorf("Expected an empty polygon not to have a centroid.  Actual: %v", res)
+	}
+}
+
+func TestCentroidOfPolygons(t *testing.T) {
+	large := NewPolygon([]*Point{NewPoint(0, 0), NewPoint(0, 3), NewPoint(3, 3), NewPoint(3, 0)})
+	small := NewPolygon([]*Point{NewPoint(0, 10), NewPoint(0, 11), NewPoint(1, 11), NewPoint(1, 10)})
+
+	// The large square has 9 times the area of the small one, so it pulls the centroid 9 times as hard.
+	expected := NewPoint((1.5*large.Area()+0.5*small.Area())/(large.Area()+small.Area()), (1.5*large.Area()+10.5*small.Area())/(large.Area()+small.Area()))
+	res := CentroidOfPolygons([]*Polygon{large, nil, small, NewPolygon([]*Point{NewPoint(50, 50)})})
+	if res == nil || math.Abs(res.lat-expected.lat) > 1e-9 || math.Abs(res.lng-expected.lng) > 1e-9 {
+		t.Errorf("Mismatched centroid.  Expected: %v.  Actual: %v", expected, res)
+	}
+
+	if res.lng > 3 {
+		t.Errorf("Expected the large polygon to dominate, unlike the mean of the centroids at 6.  Actual: %v", res)
+	}
+
+	if res := CentroidOfPolygons(nil); res != nil {
+		t.Errorf("Expected no polygons not to have a centroid.  Actual: %v", res)
+	}
+}
+
+func TestCoverageFraction(t *testing.T) {
+	zone := NewPolygon([]*Point{NewPoint(0, 0), NewPoint(0, 2), NewPoint(2, 2), NewPoint(2, 0)})
+
+	tests := []struct {
+		name     string
+		other    *Polygon
+		expected float64
+	}{
+		{"half overlapping", NewPolygon([]*Point{NewPoint(0, 1), NewPoint(0, 3), NewPoint(2, 3), NewPoint(2, 1)}), 0.5},
+		{"inside", NewPolygon([]*Point{NewPoint(0.5, 0.5), NewPoint(0.5, 1.5), NewPoint(1.5, 1.5), NewPoint(1.5, 0.5)}), 1},
+		{"quarter overlapping", NewPolygon([]*Point{NewPoint(1, 1), NewPoint(1, 3), NewPoint(3, 3), NewPoint(3, 1)}), 0.25},
+		{"enclosing", NewPolygon([]*Point{NewPoint(-1, -1), NewPoint(-1, 3), NewPoint(3, 3), NewPoint(3, -1)}), 0.25},
+		{"apart", NewPolygon([]*Point{NewPoint(5, 5), NewPoint(5, 6), NewPoint(6, 6), NewPoint(6, 5)}), 0},
+		{"without area", NewPolygon([]*Point{NewPoint(1, 1), NewPoint(1, 2)}), 0},
+	}
+
+	for _, test := range tests {
+		if res := zone.CoverageFraction(test.other); math.Abs(res-test.expected) > 0.01 {
+			t.Errorf("Mismatched coverage of the %s polygon.  Expected: %f.  Actual: %f", test.name, test.expected, res)
+		}
+	}
+
+	// A triangle covering half of the zone, which the grid only approximates.
+	triangle := NewPolygon([]*Point{NewPoint(0, 0), NewPoint(2, 2), NewPoint(2, 0)})
+	if res := triangle.CoverageFractionWithSamples(zone, 20); math.Abs(res-0.5) > 0.05 {
+		t.Errorf("Mismatched coverage with 20 samples.  Expected: about 0.5.  Actual: %f", res)
+	}
+
+	if res := triangle.CoverageFractionWithSamples(zone, 500); math.Abs(res-0.5) > 0.005 {
+		t.Errorf("Mismatched coverage with 500 samples.  Expected: about 0.5.  Actual: %f", res)
+	}
+
+	if res := triangle.CoverageFractionWithSamples(zone, 0); res != 0 {
+		t.Errorf("Expected no samples to give no coverage.  Actual: %f", res)
+	}
+}
+
+// Ensures that every polygon containing a point is found, including overlapping ones.
+func TestWhichPolygonsContain(t *testing.T) {
+	brunei, err := polygonFromFile("test/data/brunei.json")
+	if err != nil {
+		t.Error("brunei json file failed to parse: ", err)
+	}
+
+	west := NewPolygon([]*Point{NewPoint(0, 0), NewPoint(0, 2), NewPoint(2, 2), NewPoint(2, 0)})
+	east := NewPolygon([]*Point{NewPoint(0, 1), NewPoint(0, 3), NewPoint(2, 3), NewPoint(2, 1)})
+	polys := []*Polygon{west, brunei, east, nil, NewPolygon([]*Point{})}
+
+	cases := []struct {
+		point    *Point
+		expected []int
+	}{
+		{NewPoint(1, 0.5), []int{0}},
+		{NewPoint(1, 1.5), []int{0, 2}},
+		{NewPoint(1, 2.5), []int{2}},
+		{NewPoint(4.9402900, 114.9480600), []int{1}},
+		{NewPoint(47.6062, -122.3321), []int{}},
+	}
+
+	for _, c := range cases {
+		res := WhichPolygonsContain(c.point, polys)
+		if fmt.Sprint(res) != fmt.Sprint(c.expected) {
+			t.Errorf("Mismatched polygons containing %v.  Expected: %v.  Actual: %v", c.point, c.expected, res)
+		}
+	}
+}
+
 // A test struct used to encapsulate and
 // Unmarshal JSON into.
 type testPoints struct {
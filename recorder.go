@@ -0,0 +1,215 @@
+package geo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Describes whether a Recorder sends requests on to the provider or answers them from its cassette.
+type RecorderMode int
+
+const (
+	// Requests are answered from the cassette, without touching the network.
+	RecorderReplay RecorderMode = iota
+
+	// Requests are sent on to the provider, and each interaction is added to the cassette.
+	RecorderRecord
+)
+
+// The value that secret query parameters are replaced with in recorded URLs.
+const redactedValue = "REDACTED"
+
+// The query parameters that a Recorder redacts by default:
+// API keys, for-work client IDs, and request signatures.
+var DefaultRedactedParams = []string{"key", "client", "signature"}
+
+// This is the error that consumers receive when a replaying Recorder
+// has no recorded interaction left that matches a request.
+var ErrInteractionNotFound = errors.New("no recorded interaction matches the request")
+
+// A single recorded request and the response it received.
+// Secrets in the URL have already been redacted.
+type Interaction struct {
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// The recorded interactions that a Recorder saves to and replays from a file.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// An http.RoundTripper that records the interactions with a provider to a cassette file,
+// and later replays them, so that geocoders can be tested deterministically and without credentials.
+// Use it as the Transport of a geocoder's http.Client.
+// Secret query parameters are redacted before interactions are stored,
+// and requests are matched against recorded interactions by method and redacted URL.
+// Each recorded interaction is replayed at most once, in the order it was recorded.
+type Recorder struct {
+	// The path of the cassette file.
+	Path string
+
+	Mode RecorderMode
+
+	// The RoundTripper that recorded requests are sent with.  Defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+
+	// The query parameters whose values are redacted.  Defaults to DefaultRedactedParams.
+	RedactedParams []string
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     map[*Interaction]bool
+}
+
+// Creates and returns a pointer to a new Recorder for the cassette at the passed in path.
+// In RecorderReplay mode, the cassette is loaded immediately, and an error is returned if it cannot be read.
+// In RecorderRecord mode, the cassette starts empty, and is written by Save.
+func NewRecorder(path string, mode RecorderMode) (*Recorder, error) {
+	r := &Recorder{
+		Path:     path,
+		Mode:     mode,
+		cassette: &Cassette{},
+		used:     make(map[*Interaction]bool),
+	}
+
+	if mode == RecorderReplay {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		err = json.Unmarshal(data, r.cassette)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// Implements the http.RoundTripper interface.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if r.Mode == RecorderRecord {
+		return r.record(req)
+	}
+
+	return r.replay(req)
+}
+
+// Writes the recorded interactions to the cassette file.
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(r.Path, data, 0644)
+}
+
+// Sends the passed in request on to the provider and records the interaction.
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, &Interaction{
+		Method:     req.Method,
+		URL:        r.redact(req.URL),
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       string(body),
+	})
+	r.mu.Unlock()
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// Answers the passed in request with the first unused interaction that matches it.
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	u := r.redact(req.URL)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, i := range r.cassette.Interactions {
+		if r.used[i] || i.Method != req.Method || i.URL != u {
+			continue
+		}
+
+		r.used[i] = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", i.StatusCode, http.StatusText(i.StatusCode)),
+			StatusCode:    i.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        i.Header,
+			Body:          ioutil.NopCloser(bytes.NewReader([]byte(i.Body))),
+			ContentLength: int64(len(i.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, ErrInteractionNotFound
+}
+
+// Returns the passed in URL with the values of the redacted query parameters replaced.
+// The order of the query parameters is preserved, since signatures depend on it.
+func (r *Recorder) redact(u *url.URL) string {
+	params := r.RedactedParams
+	if params == nil {
+		params = DefaultRedactedParams
+	}
+
+	pairs := strings.Split(u.RawQuery, "&")
+	for i, pair := range pairs {
+		name := strings.SplitN(pair, "=", 2)[0]
+		if isRedactedParam(name, params) {
+			pairs[i] = fmt.Sprintf("%s=%s", name, redactedValue)
+		}
+	}
+
+	redacted := *u
+	redacted.RawQuery = strings.Join(pairs, "&")
+
+	return redacted.String()
+}
+
+// Returns whether or not the passed in query parameter name is one of the passed in redacted params.
+func isRedactedParam(name string, params []string) bool {
+	for _, p := range params {
+		if p == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,130 @@
+package geo
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const googleCassetteBaseURL = "https://maps.googleapis.com/maps/api/geocode/json"
+
+// Replays the golden Google cassette.  Its URLs carry REDACTED in place of the API key,
+// and no client ID or signature, so the checked in fixture holds no credentials.
+func TestRecorderReplay(t *testing.T) {
+	rec, err := NewRecorder("test/data/cassettes/google.json", RecorderReplay)
+	if err != nil {
+		t.Fatalf("Unexpected error loading the cassette: %v", err)
+	}
+
+	for _, i := range rec.cassette.Interactions {
+		u, err := url.Parse(i.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		for _, param := range DefaultRedactedParams {
+			if value, ok := u.Query()[param]; ok && (len(value) != 1 || value[0] != redactedValue) {
+				t.Errorf("Expected the %s of %s to be redacted.  Actual: %v", param, i.URL, value)
+			}
+		}
+	}
+
+	g := NewGoogleGeocoder(
+		WithAPIKey("any-key"),
+		WithBaseURL(googleCassetteBaseURL),
+		WithHTTPClient(&http.Client{Transport: rec}),
+	)
+
+	p, err := g.Geocode("San Francisco Airport")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if p.Lat() != 37.615223 || p.Lng() != -122.389979 {
+		t.Errorf("Mismatched point.  Expected: [37.615223, -122.389979].  Actual: [%f, %f]", p.Lat(), p.Lng())
+	}
+
+	address, err := g.ReverseGeocode(NewPoint(40.714224, -73.961452))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if address == "" {
+		t.Error("Expected a reverse geocoded address")
+	}
+
+	// Each interaction is only replayed once.
+	_, err = g.Geocode("San Francisco Airport")
+	if !errors.Is(err, ErrInteractionNotFound) {
+		t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", ErrInteractionNotFound, err)
+	}
+}
+
+func TestRecorderRecordRedactsSecrets(t *testing.T) {
+	server := mockGoogleServer("test/data/google_geocode_success.json")
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	rec, err := NewRecorder(path, RecorderRecord)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	g := NewGoogleGeocoder(
+		WithForWorkAuth("secret-client", "vNIXE0xscrmjlyV-12Nj_BvUPaw=", "my-channel"),
+		WithBaseURL(server.URL),
+		WithHTTPClient(&http.Client{Transport: rec}),
+	)
+
+	recorded, err := g.Geocode("San Francisco Airport")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	err = rec.Save()
+	if err != nil {
+		t.Fatalf("Unexpected error saving the cassette: %v", err)
+	}
+
+	data, _ := ioutil.ReadFile(path)
+	if strings.Contains(string(data), "secret-client") || !strings.Contains(string(data), "client=REDACTED") || !strings.Contains(string(data), "signature=REDACTED") {
+		t.Errorf("Expected secrets to be redacted from the cassette, but got: %s", data)
+	}
+
+	if !strings.Contains(string(data), "channel=my-channel") {
+		t.Errorf("Expected parameters that are not secret to be kept, but got: %s", data)
+	}
+
+	// The cassette replays for other credentials too, since they are redacted before matching.
+	server.Close()
+	rec, err = NewRecorder(path, RecorderReplay)
+	if err != nil {
+		t.Fatalf("Unexpected error loading the cassette: %v", err)
+	}
+
+	g = NewGoogleGeocoder(
+		WithForWorkAuth("other-client", "c2VjcmV0LWtleQ==", "my-channel"),
+		WithBaseURL(server.URL),
+		WithHTTPClient(&http.Client{Transport: rec}),
+	)
+
+	replayed, err := g.Geocode("San Francisco Airport")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !replayed.Equal(recorded) {
+		t.Errorf("Mismatched replayed point.  Expected: %v.  Actual: %v", recorded, replayed)
+	}
+}
+
+func TestNewRecorderMissingCassette(t *testing.T) {
+	_, err := NewRecorder(filepath.Join(t.TempDir(), "missing.json"), RecorderReplay)
+	if err == nil {
+		t.Error("Expected an error replaying from a missing cassette")
+	}
+}
@@ -0,0 +1,89 @@
+package geo
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// The columns that WriteCSV writes, in order, and the keys of each line that WriteJSONL writes:
+// the query, the latitude and longitude, the formatted address, the accuracy (the result's LocationType),
+// and the error that occurred instead, if any.
+var ExportColumns = []string{"input", "lat", "lng", "formatted_address", "accuracy", "error"}
+
+// A single result as WriteJSONL writes it.  The latitude and longitude are null for a result without a Point.
+type exportedResult struct {
+	Input            string   `json:"input"`
+	Lat              *float64 `json:"lat"`
+	Lng              *float64 `json:"lng"`
+	FormattedAddress string   `json:"formatted_address"`
+	Accuracy         string   `json:"accuracy"`
+	Error            string   `json:"error"`
+}
+
+// Writes the passed in results, such as those of a BatchGeocoder, to the passed in writer as CSV,
+// with a header row of ExportColumns followed by a row for each result, in the same order.
+// The latitude and longitude are left empty for a result without a Point, and the error is empty for one without an Err.
+// Rows are written as they are formatted, rather than all at once.
+// Returns an error if writing to the passed in writer fails.
+func WriteCSV(w io.Writer, results []GeocodeResult) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write(ExportColumns)
+	if err != nil {
+		return err
+	}
+
+	for _, res := range results {
+		e := newExportedResult(res)
+
+		lat, lng := "", ""
+		if e.Lat != nil {
+			lat = strconv.FormatFloat(*e.Lat, 'f', -1, 64)
+			lng = strconv.FormatFloat(*e.Lng, 'f', -1, 64)
+		}
+
+		err = writer.Write([]string{e.Input, lat, lng, e.FormattedAddress, e.Accuracy, e.Error})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// Writes the passed in results to the passed in writer as JSON Lines, with one JSON object per result, in the same order.
+// Each object has the keys of ExportColumns, in that order, and a null latitude and longitude for a result without a Point.
+// Returns an error if writing to the passed in writer fails.
+func WriteJSONL(w io.Writer, results []GeocodeResult) error {
+	encoder := json.NewEncoder(w)
+	for _, res := range results {
+		err := encoder.Encode(newExportedResult(res))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Creates and returns a new exportedResult from the passed in result.
+func newExportedResult(res GeocodeResult) exportedResult {
+	e := exportedResult{
+		Input:            res.Input,
+		FormattedAddress: res.FormattedAddress,
+		Accuracy:         res.LocationType,
+	}
+
+	if res.Point != nil {
+		lat, lng := res.Point.lat, res.Point.lng
+		e.Lat, e.Lng = &lat, &lng
+	}
+
+	if res.Err != nil {
+		e.Error = res.Err.Error()
+	}
+
+	return e
+}
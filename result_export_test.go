@@ -0,0 +1,65 @@
+package geo
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// Returns a set of results with and without points and errors, as a BatchGeocoder might.
+func exportTestResults() []GeocodeResult {
+	return []GeocodeResult{
+		{Input: "SFO", Point: NewPoint(37.619002, -122.37484), FormattedAddress: "San Francisco International Airport, CA, USA", LocationType: "ROOFTOP"},
+		{Input: "Nowhere", Err: ErrZeroResults},
+		{Input: "Quote \"this\"", Point: NewPoint(-33.8688, 151.2093), Err: errors.New("partial, at best")},
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteCSV(&buf, exportTestResults())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "input,lat,lng,formatted_address,accuracy,error\n" +
+		"SFO,37.619002,-122.37484,\"San Francisco International Airport, CA, USA\",ROOFTOP,\n" +
+		"Nowhere,,,,,ZERO_RESULTS\n" +
+		"\"Quote \"\"this\"\"\",-33.8688,151.2093,,,\"partial, at best\"\n"
+	if buf.String() != expected {
+		t.Errorf("Mismatched CSV.  Expected: %s.  Actual: %s", expected, buf.String())
+	}
+}
+
+func TestWriteJSONL(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteJSONL(&buf, exportTestResults())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `{"input":"SFO","lat":37.619002,"lng":-122.37484,"formatted_address":"San Francisco International Airport, CA, USA","accuracy":"ROOFTOP","error":""}` + "\n" +
+		`{"input":"Nowhere","lat":null,"lng":null,"formatted_address":"","accuracy":"","error":"ZERO_RESULTS"}` + "\n" +
+		`{"input":"Quote \"this\"","lat":-33.8688,"lng":151.2093,"formatted_address":"","accuracy":"","error":"partial, at best"}` + "\n"
+	if buf.String() != expected {
+		t.Errorf("Mismatched JSON Lines.  Expected: %s.  Actual: %s", expected, buf.String())
+	}
+}
+
+func TestWriteExportErrors(t *testing.T) {
+	w := &failingWriter{}
+	if err := WriteCSV(w, exportTestResults()); err == nil {
+		t.Error("Expected an error when the writer fails")
+	}
+
+	if err := WriteJSONL(w, exportTestResults()); err == nil {
+		t.Error("Expected an error when the writer fails")
+	}
+}
+
+// A writer whose writes always fail.
+type failingWriter struct{}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
@@ -0,0 +1,123 @@
+package geo
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The longest Retry-After delay that a RetryAfterTransport waits out, unless it sets its own MaxWait.
+const DefaultMaxRetryAfter = time.Minute
+
+// A RetryAfterTransport retries requests that a provider rejects with 429 Too Many Requests,
+// after waiting for as long as the response's Retry-After header asks, rather than guessing with a backoff.
+// Responses without a Retry-After header, or whose delay is longer than MaxWait, are returned as they are.
+// It may be used as the Transport of the HttpClient of any of the geocoders.
+type RetryAfterTransport struct {
+	// Sends each request.  Defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+
+	// The most times a single request is retried, after which the last 429 response is returned.
+	MaxRetries int
+
+	// The longest delay that is waited out.  Falls back to DefaultMaxRetryAfter if not positive.
+	MaxWait time.Duration
+}
+
+// Sends the passed in request, and retries it while the provider responds with 429 and a Retry-After header.
+// The wait is abandoned, with the request's context error, if the request's context is cancelled or times out first.
+// Requests with a body are only retried if the body can be rewound, as it can for those created by http.NewRequest.
+func (t *RetryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	maxWait := t.MaxWait
+	if maxWait <= 0 {
+		maxWait = DefaultMaxRetryAfter
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := transport.RoundTrip(req)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests || attempt >= t.MaxRetries {
+			return resp, err
+		}
+
+		wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		if !ok || wait > maxWait || (req.Body != nil && req.GetBody == nil) {
+			return resp, nil
+		}
+
+		// Drain the body so that the connection can be reused for the retry.
+		io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 4<<10))
+		resp.Body.Close()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// Retries requests that Google rejects with 429 Too Many Requests up to the passed in number of times,
+// waiting for as long as each response's Retry-After header asks.  See RetryAfterTransport.
+// It wraps the HttpClient's Transport, in a copy of the HttpClient, so it must be passed after WithHTTPClient,
+// WithTransportConfig, and WithProxy, which would otherwise replace it.
+func WithRetryAfter(maxRetries int) GoogleOption {
+	return func(g *GoogleGeocoder) {
+		client := &http.Client{}
+		if g.HttpClient != nil {
+			c := *g.HttpClient
+			client = &c
+		}
+
+		client.Transport = &RetryAfterTransport{Transport: client.Transport, MaxRetries: maxRetries}
+		g.HttpClient = client
+	}
+}
+
+// Returns the delay that the passed in Retry-After header value asks for, relative to the passed in time,
+// and whether or not it could be parsed.  The value is either a number of seconds or an HTTP-date;
+// a date in the past asks for no delay at all.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	if wait := date.Sub(now); wait > 0 {
+		return wait, true
+	}
+
+	return 0, true
+}
@@ -0,0 +1,174 @@
+package geo
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Returns a server that rejects the first passed in number of requests with 429 and the passed in Retry-After header,
+// and responds with the passed in body after that, along with a count of the requests it has received.
+func mockRetryAfterServer(rejections int32, retryAfter string, body string) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= rejections {
+			w.Header().Set("Retry-After", retryAfter)
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		w.Write([]byte(body))
+	}))
+
+	return server, &requests
+}
+
+func TestRetryAfterTransportWaits(t *testing.T) {
+	server, requests := mockRetryAfterServer(1, "1", "ok")
+	defer server.Close()
+
+	client := &http.Client{Transport: &RetryAfterTransport{MaxRetries: 3}}
+
+	start := time.Now()
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("Expected the request to wait out the Retry-After delay of 1s.  Actual: %v", elapsed)
+	}
+
+	if resp.StatusCode != http.StatusOK || atomic.LoadInt32(requests) != 2 {
+		t.Errorf("Mismatched status or requests.  Expected: 200, 2.  Actual: %d, %d", resp.StatusCode, atomic.LoadInt32(requests))
+	}
+}
+
+// Ensures that 429 responses are returned as they are once the retries run out,
+// or when they ask for no delay at all or for one longer than MaxWait.
+func TestRetryAfterTransportGivesUp(t *testing.T) {
+	cases := []struct {
+		name       string
+		retryAfter string
+		transport  *RetryAfterTransport
+		requests   int32
+	}{
+		{"out of retries", "0", &RetryAfterTransport{MaxRetries: 2}, 3},
+		{"without a Retry-After", "", &RetryAfterTransport{MaxRetries: 2}, 1},
+		{"longer than MaxWait", "120", &RetryAfterTransport{MaxRetries: 2}, 1},
+		{"longer than a custom MaxWait", "2", &RetryAfterTransport{MaxRetries: 2, MaxWait: time.Second}, 1},
+	}
+
+	for _, c := range cases {
+		server, requests := mockRetryAfterServer(5, c.retryAfter, "ok")
+
+		resp, err := (&http.Client{Transport: c.transport}).Get(server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error %s: %v", c.name, err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusTooManyRequests || atomic.LoadInt32(requests) != c.requests {
+			t.Errorf("Mismatched status or requests %s.  Expected: 429, %d.  Actual: %d, %d", c.name, c.requests, resp.StatusCode, atomic.LoadInt32(requests))
+		}
+
+		server.Close()
+	}
+}
+
+func TestRetryAfterTransportCancelled(t *testing.T) {
+	server, _ := mockRetryAfterServer(1, "30", "ok")
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+
+	start := time.Now()
+	_, err := (&http.Client{Transport: &RetryAfterTransport{MaxRetries: 1}}).Do(req)
+	if err == nil || !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+		t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", context.DeadlineExceeded, err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the wait to be abandoned when the context timed out.  Actual: %v", elapsed)
+	}
+}
+
+// Ensures that a request with a body is sent in full again when it is retried.
+func TestRetryAfterTransportBody(t *testing.T) {
+	var bodies []string
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &RetryAfterTransport{MaxRetries: 1}}
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{"size":1}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if len(bodies) != 2 || bodies[0] != `{"size":1}` || bodies[1] != `{"size":1}` {
+		t.Errorf("Mismatched request bodies.  Expected the same body twice.  Actual: %q", bodies)
+	}
+}
+
+func TestWithRetryAfter(t *testing.T) {
+	server, requests := mockRetryAfterServer(1, "0", `{"results": [{"geometry": {"location": {"lat": 37.619002, "lng": -122.37484}}}], "status": "OK"}`)
+	defer server.Close()
+
+	shared := &http.Client{Timeout: 5 * time.Second}
+	g := NewGoogleGeocoder(WithBaseURL(server.URL), WithHTTPClient(shared), WithRetryAfter(2))
+	if g.HttpClient == shared || g.HttpClient.Timeout != 5*time.Second || shared.Transport != nil {
+		t.Errorf("Expected a copy of the client with its timeout, leaving the original untouched.  Actual: %+v", g.HttpClient)
+	}
+
+	p, err := g.Geocode("San Francisco Airport")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !p.Equal(NewPoint(37.619002, -122.37484)) || atomic.LoadInt32(requests) != 2 {
+		t.Errorf("Mismatched point or requests.  Expected: %v, 2.  Actual: %v, %d", NewPoint(37.619002, -122.37484), p, atomic.LoadInt32(requests))
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2015, time.October, 21, 7, 28, 0, 0, time.UTC)
+	cases := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"120", 2 * time.Minute, true},
+		{" 0 ", 0, true},
+		{"Wed, 21 Oct 2015 07:28:30 GMT", 30 * time.Second, true},
+		{"Wed, 21 Oct 2015 07:27:00 GMT", 0, true},
+		{"", 0, false},
+		{"-1", 0, false},
+		{"soon", 0, false},
+	}
+
+	for _, c := range cases {
+		wait, ok := parseRetryAfter(c.value, now)
+		if wait != c.expected || ok != c.ok {
+			t.Errorf("Mismatched delay for %q.  Expected: %v, %t.  Actual: %v, %t", c.value, c.expected, c.ok, wait, ok)
+		}
+	}
+}
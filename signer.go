@@ -0,0 +1,79 @@
+package geo
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"hash"
+	"sync"
+)
+
+// A Signer signs Google Maps for Work request URLs with a private key, as Google requires of requests that carry a client ID.
+// The key is decoded once, and the HMACs keyed with it are pooled, so that signing many requests is cheap.
+// It is safe for concurrent use.
+type Signer struct {
+	privateKey string
+	macs       sync.Pool
+}
+
+// Creates and returns a pointer to a new Signer for the passed in URL-safe base64 private key,
+// as Google issues it, or returns ErrInvalidGooglePrivateKey if the key cannot be decoded.
+func NewSigner(privateKey string) (*Signer, error) {
+	decodedKey, err := base64.URLEncoding.DecodeString(privateKey)
+	if err != nil || len(decodedKey) == 0 {
+		return nil, ErrInvalidGooglePrivateKey
+	}
+
+	s := &Signer{privateKey: privateKey}
+	s.macs.New = func() interface{} {
+		return hmac.New(sha1.New, decodedKey)
+	}
+
+	return s, nil
+}
+
+// Returns the URL-safe base64 signature of the passed in request URI, which is the path and query of the request URL,
+// such as "/maps/api/geocode/json?address=...&client=...".
+func (s *Signer) Sign(requestURI string) string {
+	mac := s.macs.Get().(hash.Hash)
+	defer s.macs.Put(mac)
+
+	mac.Reset()
+	mac.Write([]byte(requestURI))
+
+	return base64.URLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// The most Signers that cachedSigner keeps, past which it forgets them all,
+// so that keys that change often do not pile up.
+const maxCachedSigners = 16
+
+// The Signers made by cachedSigner, by their private key.
+var signerCache = struct {
+	sync.Mutex
+	signers map[string]*Signer
+}{signers: map[string]*Signer{}}
+
+// Returns the Signer for the passed in private key, made once and shared by every request signed with it,
+// such as those of geocoders that use the package level GooglePrivateKey or set their PrivateKey field directly.
+// Returns ErrInvalidGooglePrivateKey if the key cannot be decoded.
+func cachedSigner(privateKey string) (*Signer, error) {
+	signerCache.Lock()
+	defer signerCache.Unlock()
+
+	if s, ok := signerCache.signers[privateKey]; ok {
+		return s, nil
+	}
+
+	s, err := NewSigner(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(signerCache.signers) >= maxCachedSigners {
+		signerCache.signers = map[string]*Signer{}
+	}
+	signerCache.signers[privateKey] = s
+
+	return s, nil
+}
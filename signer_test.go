@@ -0,0 +1,141 @@
+package geo
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+// The URL-safe base64 private key of Google's signing examples.
+const signerTestKey = "vNIXE0xscrmjlyV-12Nj_BvUPaw="
+
+func TestSignerSign(t *testing.T) {
+	s, err := NewSigner(signerTestKey)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		uri      string
+		expected string
+	}{
+		{"/maps/api/geocode/json?address=New+York&client=clientID", "chaRF2hTJKOScPr-RQCEhZbSzIE="},
+		{"/maps/api/geocode/json?address=Boston&client=clientID", "b8pdbj4jloFuNMZZmXsziENY4Yk="},
+	}
+
+	// Signing concurrently, and more than once with each pooled HMAC, gives the same signatures.
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				for _, c := range cases {
+					if signature := s.Sign(c.uri); signature != c.expected {
+						t.Errorf("Mismatched signature of %s.  Expected: %s.  Actual: %s", c.uri, c.expected, signature)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestNewSignerInvalidKey(t *testing.T) {
+	// Standard base64, with + and /, is a common mistake for the URL-safe alphabet.
+	for _, key := range []string{"not base64!", "vNIXE0xscrmjlyV+12Nj/BvUPaw=", "vNIXE0xscrmjlyV-12Nj_BvUPaw", ""} {
+		if _, err := NewSigner(key); err != ErrInvalidGooglePrivateKey {
+			t.Errorf("Mismatched error for %q.  Expected: %v.  Actual: %v", key, ErrInvalidGooglePrivateKey, err)
+		}
+
+		if err := ValidateGooglePrivateKey(key); err != ErrInvalidGooglePrivateKey {
+			t.Errorf("Mismatched validation error for %q.  Expected: %v.  Actual: %v", key, ErrInvalidGooglePrivateKey, err)
+		}
+	}
+
+	if err := ValidateGooglePrivateKey(signerTestKey); err != nil {
+		t.Errorf("Unexpected error for a valid key: %v", err)
+	}
+
+	expected := "invalid Google for-work private key: must be URL-safe base64"
+	if ErrInvalidGooglePrivateKey.Error() != expected {
+		t.Errorf("Mismatched message.  Expected: %s.  Actual: %s", expected, ErrInvalidGooglePrivateKey.Error())
+	}
+}
+
+// Ensures that the geocoder reuses the Signer made by WithForWorkAuth, and makes a new one once the key changes.
+func TestGoogleForWorkSigner(t *testing.T) {
+	g := NewGoogleGeocoder(WithForWorkAuth("clientID", signerTestKey, ""), WithBaseURL("https://maps.googleapis.com/maps/api/geocode/json"))
+
+	s, err := g.forWorkSigner(signerTestKey)
+	if err != nil || s != g.signer {
+		t.Errorf("Expected the geocoder's own Signer.  Actual: %v, %v", s, err)
+	}
+
+	g.PrivateKey = "AAAAAAAAAAAAAAAAAAAAAAAAAAA="
+	query, err := g.googleFormattedRequestStr("address=New+York")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !strings.HasSuffix(query, "&signature=cwcdcytneIkpwCTOJZua8kbMzSU=") {
+		t.Errorf("Expected the query to be signed with the new key.  Actual: %s", query)
+	}
+
+	g.PrivateKey = "not base64!"
+	if _, err := g.googleFormattedRequestStr("address=New+York"); err != ErrInvalidGooglePrivateKey {
+		t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", ErrInvalidGooglePrivateKey, err)
+	}
+}
+
+// Ensures that geocoders signing with the package level key, or a key set on their field, reuse one Signer for it.
+func TestCachedSigner(t *testing.T) {
+	defer SetGoogleClientID("")
+	defer SetGooglePrivateKey("")
+	SetGoogleClientID("clientID")
+	SetGooglePrivateKey(signerTestKey)
+
+	first, err := (&GoogleGeocoder{}).forWorkSigner(signerTestKey)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	g := &GoogleGeocoder{AuthSchema: GoogleMapsForWorkAuth}
+	if s, _ := g.forWorkSigner(g.privateKey()); s != first {
+		t.Errorf("Expected the Signer of the package level key to be reused.  Expected: %p.  Actual: %p", first, s)
+	}
+
+	g = &GoogleGeocoder{AuthSchema: GoogleMapsForWorkAuth, ClientID: "clientID", PrivateKey: signerTestKey}
+	if s, _ := g.forWorkSigner(g.privateKey()); s != first {
+		t.Errorf("Expected the Signer of a key set on the field to be reused.  Expected: %p.  Actual: %p", first, s)
+	}
+
+	if _, err := cachedSigner("not base64!"); err != ErrInvalidGooglePrivateKey {
+		t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", ErrInvalidGooglePrivateKey, err)
+	}
+}
+
+func BenchmarkSignerSign(b *testing.B) {
+	s, err := NewSigner(signerTestKey)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s.Sign("/maps/api/geocode/json?address=New+York&client=clientID")
+	}
+}
+
+// Decodes the key and keys a new HMAC for every signature, as was done before Signer was introduced, for comparison.
+func BenchmarkSignerSignUncached(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s, err := NewSigner(signerTestKey)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		s.Sign("/maps/api/geocode/json?address=New+York&client=clientID")
+	}
+}
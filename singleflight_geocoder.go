@@ -0,0 +1,49 @@
+package geo
+
+import (
+	"golang.org/x/sync/singleflight"
+)
+
+// A Geocoder that wraps another Geocoder and coalesces concurrent identical requests into one,
+// so that many goroutines geocoding the same address at once only cost a single call to the provider.
+// Every caller waiting on the shared call receives its result, including any error.
+// Requests are identical if their queries only differ by case and whitespace, as for the TTLCachingGeocoder, and their options match;
+// wrap it in a NormalizingGeocoder to coalesce differently spelled addresses as well.
+// Nothing is kept once the shared call returns, so it pairs well with a TTLCachingGeocoder wrapped around it.
+type SingleflightGeocoder struct {
+	// The Geocoder that performs the shared calls.
+	Geocoder Geocoder
+
+	group singleflight.Group
+}
+
+// Creates and returns a pointer to a new SingleflightGeocoder that coalesces requests to the passed in Geocoder.
+func NewSingleflightGeocoder(g Geocoder) *SingleflightGeocoder {
+	return &SingleflightGeocoder{Geocoder: g}
+}
+
+// Geocodes the passed in query with the wrapped Geocoder,
+// or waits for the result of an identical request that is already in flight.
+func (s *SingleflightGeocoder) Geocode(query string, opts ...GeocodeOption) (*Point, error) {
+	v, err, _ := s.group.Do("geocode:"+geocodeCacheKey(query, opts), func() (interface{}, error) {
+		return s.Geocoder.Geocode(query, opts...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v.(*Point), nil
+}
+
+// Reverse geocodes the passed in Point with the wrapped Geocoder,
+// or waits for the result of an identical request that is already in flight.
+func (s *SingleflightGeocoder) ReverseGeocode(p *Point, opts ...GeocodeOption) (string, error) {
+	v, err, _ := s.group.Do("reverse:"+reverseGeocodeCacheKey(p, opts), func() (interface{}, error) {
+		return s.Geocoder.ReverseGeocode(p, opts...)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return v.(string), nil
+}
@@ -0,0 +1,140 @@
+package geo
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var _ Geocoder = &SingleflightGeocoder{}
+
+// Returns a Geocoder that counts its calls and blocks each one until the passed in channel is closed.
+func gatedGeocoder(calls *int32, release chan struct{}) Geocoder {
+	return GeocoderFuncs{
+		GeocodeFunc: func(query string, opts ...GeocodeOption) (*Point, error) {
+			atomic.AddInt32(calls, 1)
+			<-release
+
+			if query == "fail" {
+				return nil, errTestGeocode
+			}
+
+			return NewPoint(37.619002, -122.37484), nil
+		},
+		ReverseGeocodeFunc: func(p *Point, opts ...GeocodeOption) (string, error) {
+			atomic.AddInt32(calls, 1)
+			<-release
+
+			return "San Francisco International Airport", nil
+		},
+	}
+}
+
+// Starts the passed in number of goroutines running the passed in function,
+// and releases the gated Geocoder once they have had time to join the shared call.
+func runConcurrently(n int, release chan struct{}, fn func()) {
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn()
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+}
+
+func TestSingleflightGeocode(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	s := NewSingleflightGeocoder(gatedGeocoder(&calls, release))
+
+	var mu sync.Mutex
+	points := []*Point{}
+	runConcurrently(10, release, func() {
+		p, err := s.Geocode("  San Francisco  Airport")
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+			return
+		}
+
+		mu.Lock()
+		points = append(points, p)
+		mu.Unlock()
+	})
+
+	if calls != 1 {
+		t.Errorf("Mismatched number of calls to the wrapped Geocoder.  Expected: 1.  Actual: %d", calls)
+	}
+
+	for _, p := range points {
+		if !p.Equal(NewPoint(37.619002, -122.37484)) {
+			t.Errorf("Mismatched point.  Expected: %v.  Actual: %v", NewPoint(37.619002, -122.37484), p)
+		}
+	}
+
+	// Nothing is kept once the shared call returns.
+	if _, err := s.Geocode("san francisco airport"); err != nil || calls != 2 {
+		t.Errorf("Expected a later request to call the wrapped Geocoder again.  Actual: %d calls, %v", calls, err)
+	}
+}
+
+// Ensures that requests with different queries or options are not coalesced.
+func TestSingleflightGeocodeDistinct(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	s := NewSingleflightGeocoder(gatedGeocoder(&calls, release))
+
+	var i int32
+	runConcurrently(3, release, func() {
+		switch atomic.AddInt32(&i, 1) {
+		case 1:
+			s.Geocode("San Francisco Airport")
+		case 2:
+			s.Geocode("San Francisco Airport", WithLanguage("de"))
+		default:
+			s.Geocode("Oakland Airport")
+		}
+	})
+
+	if calls != 3 {
+		t.Errorf("Mismatched number of calls to the wrapped Geocoder.  Expected: 3.  Actual: %d", calls)
+	}
+}
+
+func TestSingleflightGeocodeError(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	s := NewSingleflightGeocoder(gatedGeocoder(&calls, release))
+
+	runConcurrently(5, release, func() {
+		if _, err := s.Geocode("fail"); err != errTestGeocode {
+			t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", errTestGeocode, err)
+		}
+	})
+
+	if calls != 1 {
+		t.Errorf("Mismatched number of calls to the wrapped Geocoder.  Expected: 1.  Actual: %d", calls)
+	}
+}
+
+func TestSingleflightReverseGeocode(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	s := NewSingleflightGeocoder(gatedGeocoder(&calls, release))
+
+	runConcurrently(10, release, func() {
+		address, err := s.ReverseGeocode(NewPoint(37.619002, -122.37484))
+		if err != nil || address != "San Francisco International Airport" {
+			t.Errorf("Mismatched address.  Expected: San Francisco International Airport.  Actual: %s, %v", address, err)
+		}
+	})
+
+	if calls != 1 {
+		t.Errorf("Mismatched number of calls to the wrapped Geocoder.  Expected: 1.  Actual: %d", calls)
+	}
+}
@@ -2,9 +2,15 @@ package geo
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"strconv"
 )
 
+// This is the error that consumers receive when requesting a page
+// with a limit that is not positive or an offset that is negative.
+var ErrInvalidPage = errors.New("limit must be positive and offset must not be negative")
+
 // A Mapper that uses Standard SQL Syntax to perform mapping functions and queries
 type SQLMapper struct {
 	conf    *SQLConf
@@ -26,21 +32,133 @@ func (s *SQLMapper) SqlDbConn() *sql.DB {
 	return s.sqlConn
 }
 
-// Uses SQL to retrieve all points within the radius (in meters)
+// Uses SQL to retrieve all points within the radius (in kilometers)
 // passed in from the origin point passed in.
 // Original implemenation from : http://www.movable-type.co.uk/scripts/latlong-db.html
 // Returns a pointer to a sql.Rows as a result, or an error if one occurs during the query.
 func (s *SQLMapper) PointsWithinRadius(p *Point, radius float64) (*sql.Rows, error) {
-	select_str := fmt.Sprintf("SELECT * FROM %v a", s.conf.table)
-	lat1 := fmt.Sprintf("sin(radians(%f)) * sin(radians(a.lat))", p.lat)
-	lng1 := fmt.Sprintf("cos(radians(%f)) * cos(radians(a.lat)) * cos(radians(a.lng) - radians(%f))", p.lat, p.lng)
-	where_str := fmt.Sprintf("WHERE acos(%s + %s) * %f <= %f", lat1, lng1, float64(EARTH_RADIUS), radius)
-	query := fmt.Sprintf("%s %s", select_str, where_str)
-
-	res, err := s.sqlConn.Query(query)
+	res, err := s.sqlConn.Query(s.pointsWithinRadiusQuery(p, radius))
 	if err != nil {
 		panic(err)
 	}
 
 	return res, err
 }
+
+// Uses SQL to retrieve one page of the points within the radius (in kilometers)
+// passed in from the origin point passed in, ordered by ascending distance from the origin.
+// Points at the same distance are ordered by lat and lng, so that the order is stable across pages.
+// The page holds at most limit points, after skipping the first offset points.
+// Returns a pointer to a sql.Rows as a result, or an error if one occurs during the query.
+func (s *SQLMapper) PointsWithinRadiusPage(p *Point, radius float64, limit int, offset int) (*sql.Rows, error) {
+	if limit <= 0 || offset < 0 {
+		return nil, ErrInvalidPage
+	}
+
+	order_str := fmt.Sprintf("ORDER BY %s ASC, a.%s ASC, a.%s ASC", s.distanceFromPointSQL(p), s.conf.latCol, s.conf.lngCol)
+	page_str := fmt.Sprintf("LIMIT %d OFFSET %d", limit, offset)
+	query := fmt.Sprintf("%s %s %s", s.pointsWithinRadiusQuery(p, radius), order_str, page_str)
+
+	return s.sqlConn.Query(query)
+}
+
+// Returns the query that selects every point within the radius (in kilometers) of the passed in origin point.
+func (s *SQLMapper) pointsWithinRadiusQuery(p *Point, radius float64) string {
+	select_str := fmt.Sprintf("SELECT * FROM %v a", s.conf.table)
+	where_str := fmt.Sprintf("WHERE %s <= %f", s.distanceFromPointSQL(p), radius)
+
+	return fmt.Sprintf("%s %s", select_str, where_str)
+}
+
+// Returns the SQL expression for the great circle distance between the passed in point and each row,
+// read from the configured lat and lng columns.
+func (s *SQLMapper) distanceFromPointSQL(p *Point) string {
+	lat1 := fmt.Sprintf("sin(radians(%f)) * sin(radians(a.%s))", p.lat, s.conf.latCol)
+	lng1 := fmt.Sprintf("cos(radians(%f)) * cos(radians(a.%s)) * cos(radians(a.%s) - radians(%f))", p.lat, s.conf.latCol, s.conf.lngCol, p.lng)
+
+	return fmt.Sprintf("acos(%s + %s) * %f", lat1, lng1, float64(EARTH_RADIUS))
+}
+
+// Uses SQL to retrieve all points that lie within the passed in Polygon.
+// The query only narrows the points down to the Polygon's bounding box,
+// which lets the database use an index on the lat and lng columns;
+// each remaining point is then tested for containment with Polygon.Contains,
+// so the result is exactly what filtering every point in Go would give.
+// Returns the matching points, or an error if one occurs during the query.
+func (s *SQLMapper) PointsWithinPolygon(poly *Polygon) ([]*Point, error) {
+	points := []*Point{}
+
+	box := poly.BoundingBox()
+	if box == nil {
+		return points, nil
+	}
+
+	rows, err := s.sqlConn.Query(s.pointsWithinBoundingBoxQuery(box))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		p := &Point{}
+		if err := rows.Scan(&p.lat, &p.lng); err != nil {
+			return nil, err
+		}
+
+		if poly.Contains(p) {
+			points = append(points, p)
+		}
+	}
+
+	return points, rows.Err()
+}
+
+// Uses SQL to retrieve all points within the passed in radius of the center that also lie within the passed in Polygon,
+// such as the stations within 5km that are inside the city limits.
+// The radius is measured as PointsWithinRadius measures it, in kilometers.
+// The query narrows the points down to those within the radius and within the Polygon's bounding box,
+// and each remaining point is then tested for containment with Polygon.Contains,
+// so the result is exactly what applying PointsWithinRadius and PointsWithinPolygon separately and intersecting them would give.
+// Returns the matching points, or an error if one occurs during the query.
+func (s *SQLMapper) PointsWithinRadiusInPolygon(center *Point, radius float64, poly *Polygon) ([]*Point, error) {
+	points := []*Point{}
+
+	box := poly.BoundingBox()
+	if box == nil {
+		return points, nil
+	}
+
+	query := fmt.Sprintf("%s AND %s <= %f", s.pointsWithinBoundingBoxQuery(box), s.distanceFromPointSQL(center), radius)
+	rows, err := s.sqlConn.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		p := &Point{}
+		if err := rows.Scan(&p.lat, &p.lng); err != nil {
+			return nil, err
+		}
+
+		if poly.Contains(p) {
+			points = append(points, p)
+		}
+	}
+
+	return points, rows.Err()
+}
+
+// Returns the query that selects the lat and lng of every point within the passed in BoundingBox.
+func (s *SQLMapper) pointsWithinBoundingBoxQuery(box *BoundingBox) string {
+	select_str := fmt.Sprintf("SELECT a.%s, a.%s FROM %v a", s.conf.latCol, s.conf.lngCol, s.conf.table)
+	lat_str := fmt.Sprintf("a.%s BETWEEN %s AND %s", s.conf.latCol, sqlFloat(box.sw.lat), sqlFloat(box.ne.lat))
+	lng_str := fmt.Sprintf("a.%s BETWEEN %s AND %s", s.conf.lngCol, sqlFloat(box.sw.lng), sqlFloat(box.ne.lng))
+
+	return fmt.Sprintf("%s WHERE %s AND %s", select_str, lat_str, lng_str)
+}
+
+// Renders the passed in float at full precision, so that no point on the edge of a range is excluded by rounding.
+func sqlFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
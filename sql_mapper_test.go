@@ -3,6 +3,7 @@ package geo
 import (
 	"database/sql"
 	"fmt"
+	"github.com/erikstmartin/go-testdb"
 	"os"
 	"testing"
 )
@@ -40,3 +41,157 @@ func TestSqlDbConn(t *testing.T) {
 		t.Error("Expected db connections are mismatched.")
 	}
 }
+
+// Ensures that points are narrowed down to the polygon's bounding box by the database,
+// and then filtered to exactly those that the polygon contains.
+func TestPointsWithinPolygon(t *testing.T) {
+	s, err := HandleWithSQL()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	poly := NewPolygon([]*Point{
+		NewPoint(37.7, -122.5),
+		NewPoint(37.8, -122.4),
+		NewPoint(37.6, -122.3),
+	})
+
+	inside := NewPoint(37.71, -122.41)
+	candidates := []*Point{
+		inside,
+		NewPoint(37.61, -122.49), // Within the bounding box, but outside of the polygon
+	}
+
+	if os.Getenv("DB") == "mock" {
+		query := "SELECT a.lat, a.lng FROM points a WHERE a.lat BETWEEN 37.6 AND 37.8 AND a.lng BETWEEN -122.5 AND -122.3"
+		testdb.StubQuery(query, testdb.RowsFromCSVString([]string{"lat", "lng"}, "37.71,-122.41\n37.61,-122.49"))
+	} else {
+		for _, p := range candidates {
+			s.sqlConn.Exec(fmt.Sprintf("INSERT INTO points(lat, lng) VALUES(%f, %f);", p.lat, p.lng))
+		}
+
+		// Outside of the bounding box
+		s.sqlConn.Exec("INSERT INTO points(lat, lng) VALUES(40.7486, -73.9864);")
+		defer FlushTestDB(s)
+	}
+
+	res, err := s.PointsWithinPolygon(poly)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(res) != 1 || !res[0].Equal(inside) {
+		t.Errorf("Mismatched points within the polygon.  Expected: [%v].  Actual: %v", inside, res)
+	}
+
+	for _, p := range res {
+		if !poly.Contains(p) {
+			t.Errorf("Expected every returned point to be contained by the polygon, but got %v", p)
+		}
+	}
+}
+
+// Ensures that pages of points within a radius are ordered by distance, with a stable tiebreak.
+func TestPointsWithinRadiusInPolygon(t *testing.T) {
+	s, err := HandleWithSQL()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	poly := NewPolygon([]*Point{
+		NewPoint(37.7, -122.5),
+		NewPoint(37.8, -122.4),
+		NewPoint(37.6, -122.3),
+	})
+
+	center := NewPoint(37.74, -122.44)
+	inside := NewPoint(37.745, -122.445)
+
+	if os.Getenv("DB") == "mock" {
+		distance := fmt.Sprintf("acos(sin(radians(37.740000)) * sin(radians(a.lat)) + cos(radians(37.740000)) * cos(radians(a.lat)) * cos(radians(a.lng) - radians(-122.440000))) * %f", float64(EARTH_RADIUS))
+		query := fmt.Sprintf("SELECT a.lat, a.lng FROM points a WHERE a.lat BETWEEN 37.6 AND 37.8 AND a.lng BETWEEN -122.5 AND -122.3 AND %s <= 5.000000", distance)
+		testdb.StubQuery(query, testdb.RowsFromCSVString([]string{"lat", "lng"}, "37.745,-122.445\n37.76,-122.46"))
+	} else {
+		// Within the radius but outside of the polygon, and within the polygon but outside of the radius.
+		for _, p := range []*Point{inside, NewPoint(37.76, -122.46), NewPoint(37.65, -122.35)} {
+			s.sqlConn.Exec(fmt.Sprintf("INSERT INTO points(lat, lng) VALUES(%f, %f);", p.lat, p.lng))
+		}
+		defer FlushTestDB(s)
+	}
+
+	res, err := s.PointsWithinRadiusInPolygon(center, 5, poly)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(res) != 1 || !res[0].Equal(inside) {
+		t.Errorf("Mismatched points within the radius and the polygon.  Expected: [%v].  Actual: %v", inside, res)
+	}
+
+	for _, p := range res {
+		if !poly.Contains(p) || center.GreatCircleDistance(p) > 5 {
+			t.Errorf("Expected every returned point to be within both the radius and the polygon, but got %v", p)
+		}
+	}
+
+	if res, err := s.PointsWithinRadiusInPolygon(center, 5, NewPolygon(nil)); err != nil || len(res) != 0 {
+		t.Errorf("Expected no points for an empty polygon.  Actual: %v, %v", res, err)
+	}
+}
+
+// Ensures that the distance clause reads the configured lat and lng columns, as the bounding box clause does.
+func TestDistanceFromPointSQLConfiguredColumns(t *testing.T) {
+	s := &SQLMapper{conf: &SQLConf{table: "stations", latCol: "latitude", lngCol: "longitude"}}
+
+	expected := fmt.Sprintf("acos(sin(radians(37.740000)) * sin(radians(a.latitude)) + cos(radians(37.740000)) * cos(radians(a.latitude)) * cos(radians(a.longitude) - radians(-122.440000))) * %f", float64(EARTH_RADIUS))
+	if actual := s.distanceFromPointSQL(NewPoint(37.74, -122.44)); actual != expected {
+		t.Errorf("Mismatched distance clause.  Expected: %s.  Actual: %s", expected, actual)
+	}
+}
+
+func TestPointsWithinRadiusPage(t *testing.T) {
+	s, err := HandleWithSQL()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// SFO
+	origin := &Point{37.619002, -122.37484}
+
+	if os.Getenv("DB") == "mock" {
+		distance := fmt.Sprintf("acos(sin(radians(37.619002)) * sin(radians(a.lat)) + cos(radians(37.619002)) * cos(radians(a.lat)) * cos(radians(a.lng) - radians(-122.374840))) * %f", float64(EARTH_RADIUS))
+		query := fmt.Sprintf("SELECT * FROM points a WHERE %s <= 9.000000 ORDER BY %s ASC, a.lat ASC, a.lng ASC LIMIT 1 OFFSET 1", distance, distance)
+		testdb.StubQuery(query, testdb.RowsFromCSVString([]string{"lat", "lng"}, "37.690939,-122.374840"))
+	} else {
+		for _, dist := range []float64{7.999, 1, 8.001} {
+			p := origin.PointAtDistanceAndBearing(dist, 0)
+			s.sqlConn.Exec(fmt.Sprintf("INSERT INTO points(lat, lng) VALUES(%f, %f);", p.lat, p.lng))
+		}
+		defer FlushTestDB(s)
+	}
+
+	// The second closest point
+	rows, err := s.PointsWithinRadiusPage(origin, 9, 1, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		p := &Point{}
+		rows.Scan(&p.lat, &p.lng)
+		if expected := origin.PointAtDistanceAndBearing(7.999, 0); !p.EqualWithin(expected, 1) {
+			t.Errorf("Mismatched point.  Expected: %v.  Actual: %v", expected, p)
+		}
+		count++
+	}
+
+	if count != 1 {
+		t.Errorf("Mismatched number of points in the page.  Expected: 1.  Actual: %d", count)
+	}
+
+	if _, err := s.PointsWithinRadiusPage(origin, 9, 0, 0); err != ErrInvalidPage {
+		t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", ErrInvalidPage, err)
+	}
+}
@@ -0,0 +1,71 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// This is the error that consumers receive when the sun stays above the horizon for the whole day.
+var ErrMidnightSun = errors.New("the sun does not set on this day")
+
+// This is the error that consumers receive when the sun stays below the horizon for the whole day.
+var ErrPolarNight = errors.New("the sun does not rise on this day")
+
+// The Julian dates of the Unix epoch, and of the J2000 epoch (noon UTC on January 1st, 2000).
+const (
+	julianUnixEpoch = 2440587.5
+	julian2000      = 2451545.0
+)
+
+// Returns the times of sunrise and sunset at the passed in Point on the calendar day of the passed in date,
+// in the date's location.  The day is the solar day whose noon is closest to noon on that date at the Point's longitude,
+// so the date should be given in the Point's local time zone.
+// Sunrise and sunset are when the upper edge of the sun crosses the horizon, allowing for atmospheric refraction.
+// Returns ErrMidnightSun or ErrPolarNight, and zero times, on days when the sun never sets or never rises.
+// Uses the sunrise equation, which is accurate to within a minute or so away from the polar circles.
+// Original Implementation from: https://en.wikipedia.org/wiki/Sunrise_equation
+func SunriseSunset(p *Point, date time.Time) (time.Time, time.Time, error) {
+	noon := time.Date(date.Year(), date.Month(), date.Day(), 12, 0, 0, 0, time.UTC)
+	days := julianDate(noon) - julian2000
+
+	// The mean solar noon at the Point's longitude.
+	meanNoon := days - p.lng/360
+
+	anomaly := DegreesToRadians(math.Mod(357.5291+0.98560028*meanNoon, 360))
+	center := 1.9148*math.Sin(anomaly) + 0.02*math.Sin(2*anomaly) + 0.0003*math.Sin(3*anomaly)
+	eclipticLng := DegreesToRadians(math.Mod(RadiansToDegrees(anomaly)+center+180+102.9372, 360))
+
+	transit := julian2000 + meanNoon + 0.0053*math.Sin(anomaly) - 0.0069*math.Sin(2*eclipticLng)
+
+	sinDeclination := math.Sin(eclipticLng) * math.Sin(DegreesToRadians(23.4397))
+	cosDeclination := math.Cos(math.Asin(sinDeclination))
+
+	lat := DegreesToRadians(p.lat)
+	cosHourAngle := (math.Sin(-DegreesToRadians(0.833)) - math.Sin(lat)*sinDeclination) / (math.Cos(lat) * cosDeclination)
+
+	if cosHourAngle < -1 {
+		return time.Time{}, time.Time{}, ErrMidnightSun
+	}
+
+	if cosHourAngle > 1 {
+		return time.Time{}, time.Time{}, ErrPolarNight
+	}
+
+	hourAngle := RadiansToDegrees(math.Acos(cosHourAngle))
+
+	sunrise := timeFromJulianDate(transit - hourAngle/360).In(date.Location())
+	sunset := timeFromJulianDate(transit + hourAngle/360).In(date.Location())
+
+	return sunrise, sunset, nil
+}
+
+// Returns the Julian date of the passed in time.
+func julianDate(t time.Time) float64 {
+	return float64(t.Unix())/86400 + julianUnixEpoch
+}
+
+// Returns the time of the passed in Julian date, to the second.
+func timeFromJulianDate(jd float64) time.Time {
+	return time.Unix(int64(math.Round((jd-julianUnixEpoch)*86400)), 0).UTC()
+}
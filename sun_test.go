@@ -0,0 +1,71 @@
+package geo
+
+import (
+	"testing"
+	"time"
+)
+
+// The expected times are as published, to the minute, by the U.S. Naval Observatory and timeanddate.com.
+func TestSunriseSunset(t *testing.T) {
+	edt := time.FixedZone("EDT", -4*60*60)
+	aedt := time.FixedZone("AEDT", 11*60*60)
+
+	cases := []struct {
+		name    string
+		p       *Point
+		date    time.Time
+		sunrise time.Time
+		sunset  time.Time
+	}{
+		{"New York at the June solstice", NewPoint(40.7128, -74.006), time.Date(2023, 6, 21, 0, 0, 0, 0, edt),
+			time.Date(2023, 6, 21, 5, 25, 0, 0, edt), time.Date(2023, 6, 21, 20, 31, 0, 0, edt)},
+		{"London at the December solstice", NewPoint(51.5072, -0.1276), time.Date(2023, 12, 21, 15, 0, 0, 0, time.UTC),
+			time.Date(2023, 12, 21, 8, 4, 0, 0, time.UTC), time.Date(2023, 12, 21, 15, 53, 0, 0, time.UTC)},
+		{"Sydney at the December solstice", NewPoint(-33.8688, 151.2093), time.Date(2023, 12, 21, 23, 59, 0, 0, aedt),
+			time.Date(2023, 12, 21, 5, 41, 0, 0, aedt), time.Date(2023, 12, 21, 20, 5, 0, 0, aedt)},
+	}
+
+	for _, c := range cases {
+		sunrise, sunset, err := SunriseSunset(c.p, c.date)
+		if err != nil {
+			t.Errorf("Unexpected error for %s: %v", c.name, err)
+			continue
+		}
+
+		// Allow for both the rounding of the published times and the accuracy of the sunrise equation.
+		if d := sunrise.Sub(c.sunrise); d < -2*time.Minute || d > 2*time.Minute {
+			t.Errorf("Mismatched sunrise for %s.  Expected: %s.  Actual: %s", c.name, c.sunrise, sunrise)
+		}
+
+		if d := sunset.Sub(c.sunset); d < -2*time.Minute || d > 2*time.Minute {
+			t.Errorf("Mismatched sunset for %s.  Expected: %s.  Actual: %s", c.name, c.sunset, sunset)
+		}
+
+		if sunrise.Location() != c.date.Location() || sunset.Location() != c.date.Location() {
+			t.Errorf("Expected the times for %s in the date's location.  Actual: %s, %s", c.name, sunrise.Location(), sunset.Location())
+		}
+	}
+}
+
+func TestSunriseSunsetPolar(t *testing.T) {
+	tromso := NewPoint(69.6492, 18.9553)
+
+	if _, _, err := SunriseSunset(tromso, time.Date(2023, 6, 21, 0, 0, 0, 0, time.UTC)); err != ErrMidnightSun {
+		t.Errorf("Mismatched error for the June solstice in Tromsø.  Expected: %v.  Actual: %v", ErrMidnightSun, err)
+	}
+
+	sunrise, sunset, err := SunriseSunset(tromso, time.Date(2023, 12, 21, 0, 0, 0, 0, time.UTC))
+	if err != ErrPolarNight {
+		t.Errorf("Mismatched error for the December solstice in Tromsø.  Expected: %v.  Actual: %v", ErrPolarNight, err)
+	}
+
+	if !sunrise.IsZero() || !sunset.IsZero() {
+		t.Errorf("Expected zero times during the polar night.  Actual: %s, %s", sunrise, sunset)
+	}
+
+	// The seasons are reversed in Antarctica.
+	mcmurdo := NewPoint(-77.8419, 166.6863)
+	if _, _, err := SunriseSunset(mcmurdo, time.Date(2023, 12, 21, 0, 0, 0, 0, time.UTC)); err != ErrMidnightSun {
+		t.Errorf("Mismatched error for the December solstice at McMurdo.  Expected: %v.  Actual: %v", ErrMidnightSun, err)
+	}
+}
@@ -0,0 +1,85 @@
+package geo
+
+import (
+	"math"
+)
+
+// The latitude beyond which the Web Mercator projection used by slippy map tiles is cut off.
+const maxMercatorLatitude = 85.05112878
+
+// The width and height, in pixels, of the whole world at zoom level 0.
+const worldTileSize = 256
+
+// The deepest zoom level that ZoomForBounds returns.
+const maxZoom = 21
+
+// Identifies a slippy map tile, as used by OpenStreetMap and Google Maps,
+// by its column (X) and row (Y) at the passed in zoom level.
+type Tile struct {
+	X    int
+	Y    int
+	Zoom int
+}
+
+// Returns the column and row of the slippy map tile that contains the current Point at the passed in zoom level.
+// Latitudes beyond the Web Mercator cutoff of about 85.05 degrees fall in the first or last row.
+// Original Implementation from: https://wiki.openstreetmap.org/wiki/Slippy_map_tilenames
+func (p *Point) TileXY(zoom int) (int, int) {
+	n := math.Exp2(float64(zoom))
+
+	lat := DegreesToRadians(math.Max(-maxMercatorLatitude, math.Min(maxMercatorLatitude, p.lat)))
+	lng := normalizeLongitude(p.lng)
+
+	x := math.Floor((lng + 180.0) / 360.0 * n)
+	y := math.Floor((1.0 - math.Log(math.Tan(lat)+1/math.Cos(lat))/math.Pi) / 2.0 * n)
+
+	return clampTileIndex(x, n), clampTileIndex(y, n)
+}
+
+// Returns the slippy map tile that contains the current Point at the passed in zoom level.
+func (p *Point) Tile(zoom int) Tile {
+	x, y := p.TileXY(zoom)
+	return Tile{X: x, Y: y, Zoom: zoom}
+}
+
+// Clamps the passed in tile index into the range [0, n).
+func clampTileIndex(i float64, n float64) int {
+	return int(math.Max(0, math.Min(n-1, i)))
+}
+
+// Returns the highest zoom level, between 0 and 21, at which the bounds described by the passed in
+// south-west and north-east corners fit within a map of the passed in width and height (in pixels).
+// The latitudes are compared on the Web Mercator projection that Google Maps uses,
+// and bounds whose north-east longitude is less than the south-west's are taken to cross the antimeridian.
+// Original Implementation from: https://stackoverflow.com/a/13274361
+func ZoomForBounds(sw *Point, ne *Point, mapWidthPx int, mapHeightPx int) int {
+	latFraction := (mercatorLatitude(ne.lat) - mercatorLatitude(sw.lat)) / math.Pi
+
+	lngDiff := ne.lng - sw.lng
+	if lngDiff < 0 {
+		lngDiff += 360
+	}
+	lngFraction := lngDiff / 360
+
+	latZoom := zoomForFraction(mapHeightPx, latFraction)
+	lngZoom := zoomForFraction(mapWidthPx, lngFraction)
+
+	return int(math.Max(0, math.Min(maxZoom, math.Min(latZoom, lngZoom))))
+}
+
+// Returns the zoom level at which the passed in fraction of the world spans the passed in number of pixels.
+func zoomForFraction(mapPx int, fraction float64) float64 {
+	if fraction <= 0 {
+		return maxZoom
+	}
+
+	return math.Floor(math.Log(float64(mapPx)/worldTileSize/fraction) / math.Ln2)
+}
+
+// Returns the Web Mercator y coordinate of the passed in latitude, in radians, between -Pi/2 and Pi/2.
+func mercatorLatitude(lat float64) float64 {
+	sin := math.Sin(DegreesToRadians(lat))
+	y := math.Log((1+sin)/(1-sin)) / 2
+
+	return math.Max(-math.Pi, math.Min(math.Pi, y)) / 2
+}
@@ -0,0 +1,77 @@
+package geo
+
+import (
+	"sort"
+)
+
+// A TileCluster summarizes the points that fall within a single slippy map tile.
+type TileCluster struct {
+	Tile Tile
+
+	// The mean position of the points in the tile.
+	Centroid *Point
+
+	// The number of points in the tile.
+	Count int
+}
+
+// A TileClusterer groups points into one cluster per slippy map tile at a zoom level,
+// for rendering marker clusters per tile.
+type TileClusterer struct {
+	Zoom int
+}
+
+// Creates and returns a pointer to a new TileClusterer for the passed in zoom level.
+func NewTileClusterer(zoom int) *TileClusterer {
+	return &TileClusterer{Zoom: zoom}
+}
+
+// Holds the running totals of the points in a tile.
+type tileClusterSum struct {
+	lat   float64
+	lng   float64
+	count int
+}
+
+// Buckets the passed in points by the tile that contains them, and returns a cluster for every non-empty tile.
+// Clusters are returned in row-major order (by Y, then by X), so the result is deterministic.
+// Each point is visited once, so clustering runs in linear time in the number of points,
+// plus the time to sort the clusters.
+func (c *TileClusterer) Cluster(points []*Point) []*TileCluster {
+	sums := make(map[Tile]*tileClusterSum)
+
+	for _, p := range points {
+		t := p.Tile(c.Zoom)
+
+		sum, ok := sums[t]
+		if !ok {
+			sum = &tileClusterSum{}
+			sums[t] = sum
+		}
+
+		// A tile never spans the antimeridian at zoom 1 and above, so a plain mean of the coordinates is its centroid.
+		sum.lat += p.lat
+		sum.lng += normalizeLongitude(p.lng)
+		sum.count++
+	}
+
+	clusters := make([]*TileCluster, 0, len(sums))
+	for t, sum := range sums {
+		n := float64(sum.count)
+		clusters = append(clusters, &TileCluster{
+			Tile:     t,
+			Centroid: NewPoint(sum.lat/n, sum.lng/n),
+			Count:    sum.count,
+		})
+	}
+
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].Tile.Y != clusters[j].Tile.Y {
+			return clusters[i].Tile.Y < clusters[j].Tile.Y
+		}
+
+		return clusters[i].Tile.X < clusters[j].Tile.X
+	})
+
+	return clusters
+}
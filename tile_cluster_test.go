@@ -0,0 +1,77 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTileClusterer(t *testing.T) {
+	points := []*Point{
+		NewPoint(52.52, 13.405),
+		NewPoint(52.53, 13.415),
+		NewPoint(37.619002, -122.37484),
+	}
+
+	clusters := NewTileClusterer(10).Cluster(points)
+	if len(clusters) != 2 {
+		t.Fatalf("Mismatched number of clusters.  Expected: 2.  Actual: %d", len(clusters))
+	}
+
+	// Berlin's tile has the lower row, so it comes first.
+	berlin := clusters[0]
+	if berlin.Tile != (Tile{X: 550, Y: 335, Zoom: 10}) || berlin.Count != 2 {
+		t.Errorf("Mismatched Berlin cluster: %+v", berlin)
+	}
+
+	if math.Abs(berlin.Centroid.Lat()-52.525) > 1e-9 || math.Abs(berlin.Centroid.Lng()-13.41) > 1e-9 {
+		t.Errorf("Mismatched centroid.  Expected: [52.525, 13.41].  Actual: %v", berlin.Centroid)
+	}
+
+	if clusters[1].Count != 1 || !clusters[1].Centroid.Equal(points[2]) {
+		t.Errorf("Mismatched SFO cluster: %+v", clusters[1])
+	}
+
+	if res := NewTileClusterer(10).Cluster(nil); len(res) != 0 {
+		t.Errorf("Expected no clusters for no points, but got %v", res)
+	}
+}
+
+// Ensures that clustering the same points in a different order gives the same clusters.
+func TestTileClustererDeterministic(t *testing.T) {
+	points := []*Point{}
+	for i := 0; i < 1000; i++ {
+		points = append(points, NewPoint(-60+float64(i%120), -170+float64(i%340)))
+	}
+
+	c := NewTileClusterer(3)
+	first := c.Cluster(points)
+
+	reversed := make([]*Point, len(points))
+	for i, p := range points {
+		reversed[len(points)-1-i] = p
+	}
+	second := c.Cluster(reversed)
+
+	if len(first) != len(second) {
+		t.Fatalf("Mismatched number of clusters.  Expected: %d.  Actual: %d", len(first), len(second))
+	}
+
+	for i := range first {
+		if first[i].Tile != second[i].Tile || first[i].Count != second[i].Count || !first[i].Centroid.EqualWithin(second[i].Centroid, 1e-6) {
+			t.Errorf("Mismatched cluster %d.  Expected: %+v.  Actual: %+v", i, first[i], second[i])
+		}
+	}
+}
+
+func BenchmarkTileClusterer(b *testing.B) {
+	points := make([]*Point, 200000)
+	for i := range points {
+		points[i] = NewPoint(37+float64(i%1000)/1000, -122+float64(i%997)/997)
+	}
+
+	c := NewTileClusterer(12)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Cluster(points)
+	}
+}
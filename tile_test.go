@@ -0,0 +1,62 @@
+package geo
+
+import (
+	"testing"
+)
+
+func TestTileXY(t *testing.T) {
+	cases := []struct {
+		p    *Point
+		zoom int
+		x    int
+		y    int
+	}{
+		{NewPoint(52.52, 13.405), 10, 550, 335},
+		{NewPoint(37.619002, -122.37484), 12, 655, 1585},
+		{NewPoint(-33.8688, 151.2093), 8, 235, 153},
+		{NewPoint(40.7486, -73.9864), 0, 0, 0},
+
+		// Beyond the Web Mercator cutoff and on the antimeridian
+		{NewPoint(90, 180), 3, 0, 0},
+		{NewPoint(-90, 179.9999), 3, 7, 7},
+	}
+
+	for _, c := range cases {
+		x, y := c.p.TileXY(c.zoom)
+		if x != c.x || y != c.y {
+			t.Errorf("Mismatched tile for %v at zoom %d.  Expected: [%d, %d].  Actual: [%d, %d]", c.p, c.zoom, c.x, c.y, x, y)
+		}
+	}
+
+	tile := NewPoint(52.52, 13.405).Tile(10)
+	if tile != (Tile{X: 550, Y: 335, Zoom: 10}) {
+		t.Errorf("Mismatched tile.  Actual: %+v", tile)
+	}
+}
+
+func TestZoomForBounds(t *testing.T) {
+	cases := []struct {
+		sw     *Point
+		ne     *Point
+		width  int
+		height int
+		zoom   int
+	}{
+		// SFO's viewport
+		{NewPoint(37.604291, -122.4015428), NewPoint(37.6397017, -122.3549197), 640, 480, 13},
+		// The contiguous United States
+		{NewPoint(24.396308, -124.848974), NewPoint(49.384358, -66.885444), 1024, 768, 4},
+		// Fiji, across the antimeridian
+		{NewPoint(-18, 177), NewPoint(-16, -179), 640, 480, 7},
+		// A single point is clamped to the deepest zoom level.
+		{NewPoint(37.6, -122.4), NewPoint(37.6, -122.4), 640, 480, 21},
+		// The whole world does not fit in a tiny map, and is clamped to zoom level 0.
+		{NewPoint(-85, -180), NewPoint(85, 179.9), 64, 64, 0},
+	}
+
+	for _, c := range cases {
+		if res := ZoomForBounds(c.sw, c.ne, c.width, c.height); res != c.zoom {
+			t.Errorf("Mismatched zoom for [%v, %v] in %dx%d.  Expected: %d.  Actual: %d", c.sw, c.ne, c.width, c.height, c.zoom, res)
+		}
+	}
+}
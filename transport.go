@@ -0,0 +1,113 @@
+package geo
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Tunes the connection pooling of the http.Transport that a GoogleGeocoder sends its requests with,
+// for geocoding at high volume.  Fields that are left zero keep the transport's settings,
+// which are the defaults of Go's http.DefaultTransport unless the HttpClient already had a Transport.
+type TransportConfig struct {
+	// The most idle (keep-alive) connections kept across all hosts, and to each host.
+	// Go keeps 100 across all hosts, but only 2 to each, which forces new connections under concurrent load.
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+
+	// The most connections to each host, whether active or idle.  Go does not limit them.
+	MaxConnsPerHost int
+
+	// How long an idle connection is kept before it is closed.  Go keeps them for 90 seconds.
+	IdleConnTimeout time.Duration
+
+	// Whether or not to close each connection after a single request.
+	DisableKeepAlives bool
+}
+
+// Sends requests over an http.Transport tuned by the passed in config.
+// The transport starts out as a copy of the HttpClient's, or of http.DefaultTransport if it has none,
+// so it keeps Go's defaults, including HTTP/2, for anything the config leaves zero.
+// A Transport that is not an *http.Transport cannot be tuned, and is replaced.
+// The HttpClient is copied rather than modified, since it may be shared, so this may be combined with WithHTTPClient
+// as long as it is passed after it.
+func WithTransportConfig(config TransportConfig) GoogleOption {
+	return func(g *GoogleGeocoder) {
+		tuneTransport(g, func(t *http.Transport) {
+			if config.MaxIdleConns != 0 {
+				t.MaxIdleConns = config.MaxIdleConns
+			}
+
+			if config.MaxIdleConnsPerHost != 0 {
+				t.MaxIdleConnsPerHost = config.MaxIdleConnsPerHost
+			}
+
+			if config.MaxConnsPerHost != 0 {
+				t.MaxConnsPerHost = config.MaxConnsPerHost
+			}
+
+			if config.IdleConnTimeout != 0 {
+				t.IdleConnTimeout = config.IdleConnTimeout
+			}
+
+			if config.DisableKeepAlives {
+				t.DisableKeepAlives = true
+			}
+		})
+	}
+}
+
+// Sends requests through the HTTP proxy at the passed in URL, such as "http://proxy.example.com:3128",
+// rather than the one named by the HTTP_PROXY, HTTPS_PROXY, and NO_PROXY environment variables, as Go does by default.
+// An empty URL sends requests directly, even if the environment names a proxy.
+// If the URL cannot be parsed, every request fails with the parse error.
+// Like WithTransportConfig, it tunes a copy of the HttpClient's Transport, so it may be combined with it and with WithHTTPClient,
+// as long as it is passed after WithHTTPClient.
+func WithProxy(proxyURL string) GoogleOption {
+	var proxy func(*http.Request) (*url.URL, error)
+	if proxyURL != "" {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			proxy = func(*http.Request) (*url.URL, error) {
+				return nil, err
+			}
+		} else {
+			proxy = http.ProxyURL(u)
+		}
+	}
+
+	return func(g *GoogleGeocoder) {
+		tuneTransport(g, func(t *http.Transport) {
+			t.Proxy = proxy
+		})
+	}
+}
+
+// Replaces the passed in geocoder's HttpClient with a copy whose Transport is a copy of its own,
+// or of http.DefaultTransport, modified by the passed in function.
+// If neither is an *http.Transport, the function modifies an empty one.
+func tuneTransport(g *GoogleGeocoder, tune func(*http.Transport)) {
+	client := &http.Client{}
+	if g.HttpClient != nil {
+		c := *g.HttpClient
+		client = &c
+	}
+
+	transport, ok := client.Transport.(*http.Transport)
+	if !ok {
+		transport, ok = http.DefaultTransport.(*http.Transport)
+	}
+
+	// http.DefaultTransport may itself have been replaced, such as by a test or a tracing library,
+	// in which case there are no defaults to copy, and the tuning starts from an empty transport.
+	if ok {
+		transport = transport.Clone()
+	} else {
+		transport = &http.Transport{}
+	}
+
+	tune(transport)
+
+	client.Transport = transport
+	g.HttpClient = client
+}
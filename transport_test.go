@@ -0,0 +1,126 @@
+package geo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestWithTransportConfig(t *testing.T) {
+	g := NewGoogleGeocoder(WithTransportConfig(TransportConfig{MaxIdleConnsPerHost: 64, IdleConnTimeout: time.Minute}))
+
+	transport, ok := g.HttpClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("Expected an *http.Transport, but got: %T", g.HttpClient.Transport)
+	}
+
+	if transport.MaxIdleConnsPerHost != 64 || transport.IdleConnTimeout != time.Minute {
+		t.Errorf("Mismatched transport settings.  Actual: %d, %v", transport.MaxIdleConnsPerHost, transport.IdleConnTimeout)
+	}
+
+	// Anything left zero keeps Go's defaults.
+	defaults := http.DefaultTransport.(*http.Transport)
+	if transport.MaxIdleConns != defaults.MaxIdleConns || transport.ForceAttemptHTTP2 != defaults.ForceAttemptHTTP2 || transport.DisableKeepAlives {
+		t.Errorf("Expected the untouched settings to match http.DefaultTransport.  Actual: %d, %t, %t", transport.MaxIdleConns, transport.ForceAttemptHTTP2, transport.DisableKeepAlives)
+	}
+
+	if defaults.MaxIdleConnsPerHost == 64 {
+		t.Error("Expected http.DefaultTransport to be left untouched")
+	}
+}
+
+// Ensures that a client passed in with WithHTTPClient keeps its settings, and is not itself modified.
+func TestWithTransportConfigSharedClient(t *testing.T) {
+	shared := &http.Client{Timeout: 5 * time.Second, Transport: &http.Transport{MaxConnsPerHost: 3}}
+	g := NewGoogleGeocoder(WithHTTPClient(shared), WithTransportConfig(TransportConfig{DisableKeepAlives: true}))
+
+	if g.HttpClient == shared || g.HttpClient.Timeout != 5*time.Second {
+		t.Errorf("Expected a copy of the client with its timeout.  Actual: %+v", g.HttpClient)
+	}
+
+	transport := g.HttpClient.Transport.(*http.Transport)
+	if transport.MaxConnsPerHost != 3 || !transport.DisableKeepAlives {
+		t.Errorf("Mismatched transport settings.  Actual: %d, %t", transport.MaxConnsPerHost, transport.DisableKeepAlives)
+	}
+
+	if shared.Transport.(*http.Transport).DisableKeepAlives {
+		t.Error("Expected the shared client's transport to be left untouched")
+	}
+}
+
+// Ensures that requests still go through a tuned transport.
+func TestWithTransportConfigRequest(t *testing.T) {
+	server := mockGoogleServer("test/data/google_geocode_success.json")
+	defer server.Close()
+
+	g := NewGoogleGeocoder(WithBaseURL(server.URL), WithTransportConfig(TransportConfig{MaxIdleConnsPerHost: 8}))
+	if _, err := g.Geocode("San Francisco Airport"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestWithProxy(t *testing.T) {
+	// The proxy answers every request itself, as if it had forwarded it.
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.Write([]byte(`{"results": [{"geometry": {"location": {"lat": 37.619002, "lng": -122.37484}}}], "status": "OK"}`))
+	}))
+	defer proxy.Close()
+
+	g := NewGoogleGeocoder(
+		WithBaseURL("http://geocoder.invalid/geocode"),
+		WithTransportConfig(TransportConfig{MaxIdleConnsPerHost: 8}),
+		WithProxy(proxy.URL),
+	)
+
+	if _, err := g.Geocode("San Francisco Airport"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	u, err := url.Parse(proxied)
+	if err != nil || u.Host != "geocoder.invalid" {
+		t.Errorf("Expected the request to go through the proxy.  Actual: %s", proxied)
+	}
+
+	// The transport keeps the settings of the options before it.
+	if transport := g.HttpClient.Transport.(*http.Transport); transport.MaxIdleConnsPerHost != 8 {
+		t.Errorf("Mismatched transport settings.  Actual: %d", transport.MaxIdleConnsPerHost)
+	}
+}
+
+func TestWithProxyInvalid(t *testing.T) {
+	g := NewGoogleGeocoder(WithBaseURL("http://geocoder.invalid/geocode"), WithProxy("http://[::1"))
+	if _, err := g.Geocode("San Francisco Airport"); err == nil {
+		t.Error("Expected requests to fail with an unparseable proxy")
+	}
+
+	g = NewGoogleGeocoder(WithProxy(""))
+	if transport := g.HttpClient.Transport.(*http.Transport); transport.Proxy != nil {
+		t.Error("Expected an empty proxy URL to disable proxying")
+	}
+}
+
+// Ensures that the transport can still be tuned after http.DefaultTransport has been replaced with another RoundTripper.
+func TestWithTransportConfigReplacedDefaultTransport(t *testing.T) {
+	defaultTransport := http.DefaultTransport
+	http.DefaultTransport = http.NewFileTransport(http.Dir("."))
+	defer func() { http.DefaultTransport = defaultTransport }()
+
+	g := NewGoogleGeocoder(WithTransportConfig(TransportConfig{MaxIdleConnsPerHost: 64}), WithProxy("http://proxy.example.com:3128"))
+
+	transport, ok := g.HttpClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("Expected an *http.Transport, but got: %T", g.HttpClient.Transport)
+	}
+
+	if transport.MaxIdleConnsPerHost != 64 {
+		t.Errorf("Mismatched MaxIdleConnsPerHost.  Expected: 64.  Actual: %d", transport.MaxIdleConnsPerHost)
+	}
+
+	if transport.Proxy == nil {
+		t.Error("Expected the proxy to be set on the tuned transport")
+	}
+}
@@ -0,0 +1,200 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A Geocoder that wraps another Geocoder and caches its results
+// for a configurable amount of time.  Once an entry has expired,
+// the next lookup for the same query will be fetched from the wrapped Geocoder.
+// Expired entries are removed when they are next looked up, and are otherwise swept out
+// at most once every TTL, as results are added, so the cache does not grow without bound.
+// Errors are not cached, except for ErrZeroResults if NegativeTTL is set.
+type TTLCachingGeocoder struct {
+	// The Geocoder that performs lookups on a cache miss.
+	Geocoder Geocoder
+
+	// How long a cached result remains valid.
+	TTL time.Duration
+
+	// How long ErrZeroResults is cached for a query that the wrapped Geocoder found nothing for,
+	// so that addresses known to be unmappable are not looked up over and over.
+	// It is usually much shorter than TTL, since the provider's data may improve.
+	// Zero, the default, does not cache it.  Any other error, such as a timeout or a rejected API key, is never cached.
+	NegativeTTL time.Duration
+
+	// Returns the current time.  Defaults to time.Now,
+	// but may be swapped out so that tests do not need to sleep.
+	Now func() time.Time
+
+	mu       sync.Mutex
+	points   map[string]*ttlPointEntry
+	reverses map[string]*ttlAddressEntry
+
+	// When the expired entries are next swept out.
+	nextSweep time.Time
+}
+
+// A cached result, which is ErrZeroResults rather than a Point if the query was negatively cached.
+type ttlPointEntry struct {
+	point   *Point
+	err     error
+	expires time.Time
+}
+
+// A cached address, which is ErrZeroResults rather than an address if the Point was negatively cached.
+type ttlAddressEntry struct {
+	address string
+	err     error
+	expires time.Time
+}
+
+// Creates and returns a pointer to a new TTLCachingGeocoder
+// that caches the results of the passed in Geocoder for the passed in duration.
+func NewTTLCachingGeocoder(g Geocoder, ttl time.Duration) *TTLCachingGeocoder {
+	return &TTLCachingGeocoder{Geocoder: g, TTL: ttl, Now: time.Now}
+}
+
+// Returns the cached Point for the passed in query if one exists and has not expired.
+// Otherwise geocodes the query with the wrapped Geocoder and caches the result, or ErrZeroResults if NegativeTTL is set.
+func (c *TTLCachingGeocoder) Geocode(query string, opts ...GeocodeOption) (*Point, error) {
+	key := geocodeCacheKey(query, opts)
+
+	c.mu.Lock()
+	entry, ok := c.points[key]
+	if ok && c.now().Before(entry.expires) {
+		c.mu.Unlock()
+		return entry.point, entry.err
+	}
+	if ok {
+		delete(c.points, key)
+	}
+	c.mu.Unlock()
+
+	p, err := c.Geocoder.Geocode(query, opts...)
+	if err != nil {
+		p = nil
+	}
+
+	ttl, cacheable := c.ttlFor(err)
+	if !cacheable {
+		return p, err
+	}
+
+	c.mu.Lock()
+	if c.points == nil {
+		c.points = make(map[string]*ttlPointEntry)
+	}
+	c.points[key] = &ttlPointEntry{point: p, err: err, expires: c.now().Add(ttl)}
+	c.sweep()
+	c.mu.Unlock()
+
+	return p, err
+}
+
+// Returns the cached address for the passed in Point if one exists and has not expired.
+// Otherwise reverse geocodes the Point with the wrapped Geocoder and caches the result, or ErrZeroResults if NegativeTTL is set.
+func (c *TTLCachingGeocoder) ReverseGeocode(p *Point, opts ...GeocodeOption) (string, error) {
+	key := reverseGeocodeCacheKey(p, opts)
+
+	c.mu.Lock()
+	entry, ok := c.reverses[key]
+	if ok && c.now().Before(entry.expires) {
+		c.mu.Unlock()
+		return entry.address, entry.err
+	}
+	if ok {
+		delete(c.reverses, key)
+	}
+	c.mu.Unlock()
+
+	address, err := c.Geocoder.ReverseGeocode(p, opts...)
+	if err != nil {
+		address = ""
+	}
+
+	ttl, cacheable := c.ttlFor(err)
+	if !cacheable {
+		return address, err
+	}
+
+	c.mu.Lock()
+	if c.reverses == nil {
+		c.reverses = make(map[string]*ttlAddressEntry)
+	}
+	c.reverses[key] = &ttlAddressEntry{address: address, err: err, expires: c.now().Add(ttl)}
+	c.sweep()
+	c.mu.Unlock()
+
+	return address, err
+}
+
+// Returns how long to cache a lookup that returned the passed in error, and whether or not to cache it at all.
+// Successful lookups are cached for the TTL, and ErrZeroResults for the NegativeTTL, if it is set.
+func (c *TTLCachingGeocoder) ttlFor(err error) (time.Duration, bool) {
+	if err == nil {
+		return c.TTL, true
+	}
+
+	if errors.Is(err, ErrZeroResults) && c.NegativeTTL > 0 {
+		return c.NegativeTTL, true
+	}
+
+	return 0, false
+}
+
+// Removes every expired entry, for queries and Points that are never looked up again,
+// if the TTL has elapsed since the last sweep.  Sweeping at most once every TTL keeps adding a result cheap,
+// while every entry is still removed within twice its TTL of being added.  The caller must hold c.mu.
+func (c *TTLCachingGeocoder) sweep() {
+	now := c.now()
+	if now.Before(c.nextSweep) {
+		return
+	}
+	c.nextSweep = now.Add(c.TTL)
+
+	for key, entry := range c.points {
+		if !now.Before(entry.expires) {
+			delete(c.points, key)
+		}
+	}
+
+	for key, entry := range c.reverses {
+		if !now.Before(entry.expires) {
+			delete(c.reverses, key)
+		}
+	}
+}
+
+func (c *TTLCachingGeocoder) now() time.Time {
+	if c.Now == nil {
+		return time.Now()
+	}
+
+	return c.Now()
+}
+
+// Normalizes a query so that trivially different inputs
+// (case, surrounding or repeated whitespace) share a cache entry.
+func normalizeQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
+func pointCacheKey(p *Point) string {
+	return fmt.Sprintf("%f,%f", p.lat, p.lng)
+}
+
+// Returns the cache key for a geocoding request, which includes its options
+// so that, for example, the same query in two languages is cached separately.
+func geocodeCacheKey(query string, opts []GeocodeOption) string {
+	return fmt.Sprintf("%s#%s", normalizeQuery(query), newGeocodeOptions(opts).cacheKey())
+}
+
+// Returns the cache key for a reverse geocoding request, including its options.
+func reverseGeocodeCacheKey(p *Point, opts []GeocodeOption) string {
+	return fmt.Sprintf("%s#%s", pointCacheKey(p), newGeocodeOptions(opts).cacheKey())
+}
@@ -0,0 +1,200 @@
+package geo
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// A Geocoder used in tests that counts the lookups it receives.
+type countingGeocoder struct {
+	geocodes int
+	reverses int
+	err      error
+}
+
+func (g *countingGeocoder) Geocode(query string, opts ...GeocodeOption) (*Point, error) {
+	g.geocodes++
+	if g.err != nil {
+		return nil, g.err
+	}
+
+	return NewPoint(37.619002, -122.37484), nil
+}
+
+func (g *countingGeocoder) ReverseGeocode(p *Point, opts ...GeocodeOption) (string, error) {
+	g.reverses++
+	if g.err != nil {
+		return "", g.err
+	}
+
+	return "San Francisco International Airport", nil
+}
+
+// A clock used in tests that only moves when told to.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// Ensures that a cached result is served until it expires, and is refetched afterwards.
+func TestTTLCachingGeocoderExpiry(t *testing.T) {
+	g := &countingGeocoder{}
+	clock := &fakeClock{now: time.Date(2015, 6, 24, 0, 0, 0, 0, time.UTC)}
+	c := NewTTLCachingGeocoder(g, 24*time.Hour)
+	c.Now = clock.Now
+
+	c.Geocode("San Francisco International Airport")
+	c.Geocode("  san francisco   INTERNATIONAL airport ")
+	if g.geocodes != 1 {
+		t.Errorf("Expected normalized queries to share a cache entry.  Expected: 1 lookup, Actual: %d", g.geocodes)
+	}
+
+	clock.Advance(23 * time.Hour)
+	c.Geocode("San Francisco International Airport")
+	if g.geocodes != 1 {
+		t.Errorf("Expected an unexpired entry to be served from the cache.  Expected: 1 lookup, Actual: %d", g.geocodes)
+	}
+
+	clock.Advance(time.Hour)
+	c.Geocode("San Francisco International Airport")
+	if g.geocodes != 2 {
+		t.Errorf("Expected an expired entry to trigger a fresh lookup.  Expected: 2 lookups, Actual: %d", g.geocodes)
+	}
+}
+
+// Ensures that expired entries are removed, whether or not they are looked up again, so the cache does not grow without bound.
+func TestTTLCachingGeocoderEvictsExpired(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2015, 6, 24, 0, 0, 0, 0, time.UTC)}
+	c := NewTTLCachingGeocoder(&countingGeocoder{}, time.Hour)
+	c.Now = clock.Now
+
+	c.Geocode("San Francisco International Airport")
+	c.ReverseGeocode(NewPoint(37.619002, -122.37484))
+	c.Geocode("Oakland International Airport")
+
+	// Looking up an expired entry removes it, even if the fresh lookup is not cached.
+	clock.Advance(time.Hour)
+	c.Geocoder = &countingGeocoder{err: errors.New("boom")}
+	c.Geocode("San Francisco International Airport")
+	if _, ok := c.points[geocodeCacheKey("San Francisco International Airport", nil)]; ok {
+		t.Error("Expected the expired entry to be removed when it was looked up")
+	}
+
+	// Adding a result once the TTL has passed sweeps out the entries that were never looked up again.
+	c.Geocoder = &countingGeocoder{}
+	c.Geocode("San Jose International Airport")
+	if len(c.points) != 1 || len(c.reverses) != 0 {
+		t.Errorf("Mismatched number of cached entries.  Expected: 1 and 0.  Actual: %d and %d", len(c.points), len(c.reverses))
+	}
+}
+
+// Ensures that reverse geocoding results are cached as well.
+func TestTTLCachingGeocoderReverseGeocode(t *testing.T) {
+	g := &countingGeocoder{}
+	clock := &fakeClock{now: time.Date(2015, 6, 24, 0, 0, 0, 0, time.UTC)}
+	c := NewTTLCachingGeocoder(g, time.Minute)
+	c.Now = clock.Now
+
+	p := NewPoint(37.619002, -122.37484)
+	c.ReverseGeocode(p)
+	address, err := c.ReverseGeocode(p)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if address != "San Francisco International Airport" || g.reverses != 1 {
+		t.Errorf("Expected a cached address from a single lookup.  Actual: %s after %d lookups", address, g.reverses)
+	}
+
+	clock.Advance(time.Minute)
+	c.ReverseGeocode(p)
+	if g.reverses != 2 {
+		t.Errorf("Expected an expired entry to trigger a fresh lookup.  Expected: 2 lookups, Actual: %d", g.reverses)
+	}
+}
+
+// Ensures that errors from the wrapped Geocoder are not cached.
+func TestTTLCachingGeocoderDoesNotCacheErrors(t *testing.T) {
+	g := &countingGeocoder{err: errors.New("boom")}
+	c := NewTTLCachingGeocoder(g, time.Hour)
+
+	c.Geocode("foo")
+	_, err := c.Geocode("foo")
+	if err == nil {
+		t.Error("Expected the wrapped Geocoder's error to be returned")
+	}
+
+	if g.geocodes != 2 {
+		t.Errorf("Expected errors not to be cached.  Expected: 2 lookups, Actual: %d", g.geocodes)
+	}
+}
+
+// Ensures that ErrZeroResults is cached for the NegativeTTL, but that other errors never are.
+func TestTTLCachingGeocoderNegativeTTL(t *testing.T) {
+	g := &countingGeocoder{err: ErrZeroResults}
+	clock := &fakeClock{now: time.Date(2015, 6, 24, 0, 0, 0, 0, time.UTC)}
+	c := NewTTLCachingGeocoder(g, 24*time.Hour)
+	c.Now = clock.Now
+	c.NegativeTTL = time.Hour
+
+	c.Geocode("Nowhere")
+	p, err := c.Geocode("Nowhere")
+	if p != nil || err != ErrZeroResults {
+		t.Errorf("Expected the cached ErrZeroResults.  Actual: %v, %v", p, err)
+	}
+
+	c.ReverseGeocode(NewPoint(0, 0))
+	if _, err := c.ReverseGeocode(NewPoint(0, 0)); err != ErrZeroResults {
+		t.Errorf("Mismatched error.  Expected: %v.  Actual: %v", ErrZeroResults, err)
+	}
+
+	if g.geocodes != 1 || g.reverses != 1 {
+		t.Errorf("Expected ErrZeroResults to be cached.  Expected: 1 lookup each, Actual: %d, %d", g.geocodes, g.reverses)
+	}
+
+	// Once the NegativeTTL has passed, the query is retried, even though the TTL has not.
+	clock.Advance(time.Hour)
+	g.err = nil
+	if p, err := c.Geocode("Nowhere"); err != nil || p == nil || g.geocodes != 2 {
+		t.Errorf("Expected a fresh lookup once the NegativeTTL passed.  Actual: %v, %v after %d lookups", p, err, g.geocodes)
+	}
+
+	// Transient errors are never cached, even with a NegativeTTL.
+	g.err = errors.New("timeout")
+	c.Geocode("Somewhere")
+	c.Geocode("Somewhere")
+	if g.geocodes != 4 {
+		t.Errorf("Expected transient errors not to be cached.  Expected: 4 lookups, Actual: %d", g.geocodes)
+	}
+
+	// Without a NegativeTTL, ErrZeroResults is not cached either.
+	g = &countingGeocoder{err: ErrZeroResults}
+	c = NewTTLCachingGeocoder(g, 24*time.Hour)
+	c.Geocode("Nowhere")
+	c.Geocode("Nowhere")
+	if g.geocodes != 2 {
+		t.Errorf("Expected ErrZeroResults not to be cached by default.  Expected: 2 lookups, Actual: %d", g.geocodes)
+	}
+}
+
+// Ensures that the same query with different options is cached separately.
+func TestTTLCachingGeocoderKeysOnOptions(t *testing.T) {
+	g := &countingGeocoder{}
+	c := NewTTLCachingGeocoder(g, time.Hour)
+
+	c.Geocode("Zurich", WithLanguage("de"))
+	c.Geocode("Zurich", WithLanguage("fr"))
+	c.Geocode("zurich", WithLanguage("de"))
+
+	if g.geocodes != 2 {
+		t.Errorf("Expected one lookup per set of options.  Expected: 2 lookups, Actual: %d", g.geocodes)
+	}
+}
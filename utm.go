@@ -0,0 +1,151 @@
+package geo
+
+import (
+	"math"
+)
+
+// The parameters of the WGS-84 ellipsoid, and the scale factor on the central meridian of each UTM zone.
+const (
+	wgs84SemiMajorAxis = 6378137.0
+	wgs84Flattening    = 1 / 298.257223563
+	utmScaleFactor     = 0.9996
+	utmFalseEasting    = 500000.0
+	utmFalseNorthing   = 10000000.0
+)
+
+// Converts the current Point to Universal Transverse Mercator coordinates on the WGS-84 ellipsoid,
+// and returns its zone (1 to 60), its hemisphere ('N' or 'S'), and its easting and northing (in meters).
+// The zone follows the longitude, except for the wider zones 32V over southern Norway and 31X to 37X over Svalbard.
+// UTM is only defined between 80°S and 84°N; points beyond are converted all the same,
+// but grow less accurate, and are better served by a polar projection.
+// Uses the transverse Mercator series from Snyder's "Map Projections: A Working Manual",
+// which are accurate to well under a meter within a zone.
+func (p *Point) ToUTM() (int, byte, float64, float64) {
+	zone := utmZone(p.lat, p.lng)
+
+	hemisphere := byte('N')
+	if p.lat < 0 {
+		hemisphere = 'S'
+	}
+
+	e2 := wgs84Flattening * (2 - wgs84Flattening)
+	ep2 := e2 / (1 - e2)
+
+	lat := DegreesToRadians(p.lat)
+	// Take the short way around, for points on the far side of the antimeridian from their zone.
+	dLng := DegreesToRadians(LongitudeDiff(utmCentralMeridian(zone), p.lng))
+
+	sinLat, cosLat := math.Sincos(lat)
+	tanLat := math.Tan(lat)
+
+	n := wgs84SemiMajorAxis / math.Sqrt(1-e2*sinLat*sinLat)
+	t := tanLat * tanLat
+	c := ep2 * cosLat * cosLat
+	a := cosLat * dLng
+	m := utmMeridianDistance(lat)
+
+	easting := utmScaleFactor*n*(a+
+		(1-t+c)*math.Pow(a, 3)/6+
+		(5-18*t+t*t+72*c-58*ep2)*math.Pow(a, 5)/120) + utmFalseEasting
+
+	northing := utmScaleFactor * (m + n*tanLat*(a*a/2+
+		(5-t+9*c+4*c*c)*math.Pow(a, 4)/24+
+		(61-58*t+t*t+600*c-330*ep2)*math.Pow(a, 6)/720))
+
+	if hemisphere == 'S' {
+		northing += utmFalseNorthing
+	}
+
+	return zone, hemisphere, easting, northing
+}
+
+// Converts the passed in Universal Transverse Mercator coordinates on the WGS-84 ellipsoid back to a Point:
+// the zone (1 to 60), the hemisphere ('N' or 'S', in either case), and the easting and northing (in meters).
+// Returns nil if the zone or hemisphere is invalid.
+func FromUTM(zone int, hemisphere byte, easting float64, northing float64) *Point {
+	if zone < 1 || zone > 60 {
+		return nil
+	}
+
+	switch hemisphere {
+	case 'N', 'n':
+	case 'S', 's':
+		northing -= utmFalseNorthing
+	default:
+		return nil
+	}
+
+	e2 := wgs84Flattening * (2 - wgs84Flattening)
+	ep2 := e2 / (1 - e2)
+	e1 := (1 - math.Sqrt(1-e2)) / (1 + math.Sqrt(1-e2))
+
+	m := northing / utmScaleFactor
+	mu := m / (wgs84SemiMajorAxis * (1 - e2/4 - 3*e2*e2/64 - 5*e2*e2*e2/256))
+
+	// The footpoint latitude, where the meridian distance equals the northing.
+	lat1 := mu +
+		(3*e1/2-27*math.Pow(e1, 3)/32)*math.Sin(2*mu) +
+		(21*e1*e1/16-55*math.Pow(e1, 4)/32)*math.Sin(4*mu) +
+		(151*math.Pow(e1, 3)/96)*math.Sin(6*mu) +
+		(1097*math.Pow(e1, 4)/512)*math.Sin(8*mu)
+
+	sinLat1, cosLat1 := math.Sincos(lat1)
+	tanLat1 := math.Tan(lat1)
+
+	n1 := wgs84SemiMajorAxis / math.Sqrt(1-e2*sinLat1*sinLat1)
+	t1 := tanLat1 * tanLat1
+	c1 := ep2 * cosLat1 * cosLat1
+	r1 := wgs84SemiMajorAxis * (1 - e2) / math.Pow(1-e2*sinLat1*sinLat1, 1.5)
+	d := (easting - utmFalseEasting) / (n1 * utmScaleFactor)
+
+	lat := lat1 - (n1*tanLat1/r1)*(d*d/2-
+		(5+3*t1+10*c1-4*c1*c1-9*ep2)*math.Pow(d, 4)/24+
+		(61+90*t1+298*c1+45*t1*t1-252*ep2-3*c1*c1)*math.Pow(d, 6)/720)
+
+	dLng := (d -
+		(1+2*t1+c1)*math.Pow(d, 3)/6 +
+		(5-2*c1+28*t1-3*c1*c1+8*ep2+24*t1*t1)*math.Pow(d, 5)/120) / cosLat1
+
+	return NewPoint(RadiansToDegrees(lat), normalizeLongitude(utmCentralMeridian(zone)+RadiansToDegrees(dLng)))
+}
+
+// Returns the UTM zone that contains the passed in coordinates, including the exceptions over Norway and Svalbard.
+func utmZone(lat float64, lng float64) int {
+	lng = normalizeLongitude(lng)
+
+	if lat >= 56 && lat < 64 && lng >= 3 && lng < 12 {
+		return 32
+	}
+
+	if lat >= 72 && lat <= 84 && lng >= 0 && lng < 42 {
+		switch {
+		case lng < 9:
+			return 31
+		case lng < 21:
+			return 33
+		case lng < 33:
+			return 35
+		default:
+			return 37
+		}
+	}
+
+	return int(math.Floor((lng+180)/6)) + 1
+}
+
+// Returns the longitude (in degrees) of the central meridian of the passed in UTM zone.
+func utmCentralMeridian(zone int) float64 {
+	return float64(zone-1)*6 - 180 + 3
+}
+
+// Returns the distance (in meters) along the meridian from the equator to the passed in latitude (in radians).
+func utmMeridianDistance(lat float64) float64 {
+	e2 := wgs84Flattening * (2 - wgs84Flattening)
+	e4 := e2 * e2
+	e6 := e4 * e2
+
+	return wgs84SemiMajorAxis * ((1-e2/4-3*e4/64-5*e6/256)*lat -
+		(3*e2/8+3*e4/32+45*e6/1024)*math.Sin(2*lat) +
+		(15*e4/256+45*e6/1024)*math.Sin(4*lat) -
+		(35*e6/3072)*math.Sin(6*lat))
+}
@@ -0,0 +1,97 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+// The expected coordinates were computed independently, with Krüger's series.
+func TestToUTM(t *testing.T) {
+	cases := []struct {
+		p          *Point
+		zone       int
+		hemisphere byte
+		easting    float64
+		northing   float64
+	}{
+		{NewPoint(40.7486, -73.9864), 18, 'N', 585569.057, 4511343.966},
+		{NewPoint(-33.8688, 151.2093), 56, 'S', 334368.634, 6250948.345},
+		{NewPoint(51.5072, -0.1276), 30, 'N', 699330.984, 5710142.067},
+		{NewPoint(-0.5, 3.5), 31, 'S', 555636.088, 9944732.844},
+		{NewPoint(0, -177), 1, 'N', 500000, 0},
+		// Southern Norway is in the wider zone 32V, rather than 31V.
+		{NewPoint(60.3913, 5.3221), 32, 'N', 297353.933, 6700648.345},
+		// Svalbard is covered by the odd-numbered zones 31X to 37X.
+		{NewPoint(78, 8), 31, 'N', 615914.525, 8663320.201},
+		{NewPoint(79, 39), 37, 'N', 500000, 8769974.014},
+		{NewPoint(78.2232, 15.6267), 33, 'N', 514278.715, 8683355.470},
+	}
+
+	for _, c := range cases {
+		zone, hemisphere, easting, northing := c.p.ToUTM()
+		if zone != c.zone || hemisphere != c.hemisphere {
+			t.Errorf("Mismatched zone for %v.  Expected: %d%c.  Actual: %d%c", c.p, c.zone, c.hemisphere, zone, hemisphere)
+		}
+
+		if math.Abs(easting-c.easting) > 0.01 || math.Abs(northing-c.northing) > 0.01 {
+			t.Errorf("Mismatched easting and northing for %v.  Expected: %f, %f.  Actual: %f, %f", c.p, c.easting, c.northing, easting, northing)
+		}
+	}
+}
+
+func TestUTMZone(t *testing.T) {
+	cases := []struct {
+		lat      float64
+		lng      float64
+		expected int
+	}{
+		{0, -180, 1},
+		{0, 180, 1},
+		{0, 179.9, 60},
+		{0, 0, 31},
+		{56, 2.9, 31},
+		{56, 3, 32},
+		{63.9, 11.9, 32},
+		{64, 3, 31},
+		{72, 8.9, 31},
+		{72, 9, 33},
+		{84, 32.9, 35},
+		{84, 41.9, 37},
+		{84, 42, 38},
+		{71.9, 9, 32},
+	}
+
+	for _, c := range cases {
+		if res := utmZone(c.lat, c.lng); res != c.expected {
+			t.Errorf("Mismatched zone for %f, %f.  Expected: %d.  Actual: %d", c.lat, c.lng, c.expected, res)
+		}
+	}
+}
+
+func TestFromUTM(t *testing.T) {
+	points := []*Point{
+		NewPoint(40.7486, -73.9864),
+		NewPoint(-33.8688, 151.2093),
+		NewPoint(60.3913, 5.3221),
+		NewPoint(78, 8),
+		NewPoint(-79.9, -179.9),
+		NewPoint(83.9, 179.9),
+		NewPoint(0, 0),
+	}
+
+	for _, p := range points {
+		zone, hemisphere, easting, northing := p.ToUTM()
+		res := FromUTM(zone, hemisphere, easting, northing)
+		if res == nil || res.GreatCircleDistance(p)*1000 > 0.01 {
+			t.Errorf("Expected %v to round trip through UTM %d%c %f %f.  Actual: %v", p, zone, hemisphere, easting, northing, res)
+		}
+	}
+
+	if res := FromUTM(18, 'n', 585569.057, 4511343.966); res == nil || res.GreatCircleDistance(NewPoint(40.7486, -73.9864))*1000 > 0.01 {
+		t.Errorf("Expected a lower case hemisphere to be accepted.  Actual: %v", res)
+	}
+
+	if FromUTM(0, 'N', 500000, 0) != nil || FromUTM(61, 'N', 500000, 0) != nil || FromUTM(31, 'X', 500000, 0) != nil {
+		t.Error("Expected an invalid zone or hemisphere to return nil")
+	}
+}
@@ -0,0 +1,88 @@
+package geo
+
+import (
+	"strings"
+)
+
+// The similarity above which VerifyGeocode reports a match, unless a VerifyingGeocoder sets its own.
+const DefaultVerifyThreshold = 0.7
+
+// Geocodes addresses with another Geocoder and checks each result by reverse geocoding it again,
+// to flag low-confidence matches whose round trip address has little in common with the query.
+type VerifyingGeocoder struct {
+	Geocoder Geocoder
+
+	// Scores how well the round trip address (the second argument) matches the query (the first),
+	// from 0 for no match to 1 for a full match.  Defaults to AddressSimilarity.
+	Similarity func(string, string) float64
+
+	// The score a round trip must reach to be reported as a match.  Defaults to DefaultVerifyThreshold.
+	Threshold float64
+}
+
+// Creates and returns a pointer to a new VerifyingGeocoder
+// that checks the results of the passed in Geocoder with AddressSimilarity and DefaultVerifyThreshold.
+func NewVerifyingGeocoder(g Geocoder) *VerifyingGeocoder {
+	return &VerifyingGeocoder{Geocoder: g, Similarity: AddressSimilarity, Threshold: DefaultVerifyThreshold}
+}
+
+// Geocodes the passed in address, reverse geocodes the resulting Point, and returns a pointer to a new GeocodeResult
+// holding the Point and the round trip address as its FormattedAddress, along with whether or not
+// the round trip address is similar enough to the query to trust the result.
+// The passed in options are used for both requests.
+// Returns an error if either request fails, along with the Point if it was geocoded.
+func (v *VerifyingGeocoder) VerifyGeocode(address string, opts ...GeocodeOption) (*GeocodeResult, bool, error) {
+	p, err := v.Geocoder.Geocode(address, opts...)
+	if err != nil {
+		return nil, false, err
+	}
+
+	res := &GeocodeResult{Point: p}
+
+	res.FormattedAddress, err = v.Geocoder.ReverseGeocode(p, opts...)
+	if err != nil {
+		return res, false, err
+	}
+
+	similarity := v.Similarity
+	if similarity == nil {
+		similarity = AddressSimilarity
+	}
+
+	threshold := v.Threshold
+	if threshold == 0 {
+		threshold = DefaultVerifyThreshold
+	}
+
+	return res, similarity(address, res.FormattedAddress) >= threshold, nil
+}
+
+// Returns the fraction of the words of the passed in query that also appear in the passed in address,
+// once both are normalized with NormalizeAddress and stripped of commas.
+// Words the address adds, such as a postal code or country, do not lower the score.
+// Returns 0 if the query has no words.
+func AddressSimilarity(query string, address string) float64 {
+	queryWords := addressWords(query)
+	if len(queryWords) == 0 {
+		return 0
+	}
+
+	addressWordSet := map[string]bool{}
+	for _, word := range addressWords(address) {
+		addressWordSet[word] = true
+	}
+
+	matches := 0
+	for _, word := range queryWords {
+		if addressWordSet[word] {
+			matches++
+		}
+	}
+
+	return float64(matches) / float64(len(queryWords))
+}
+
+// Returns the normalized words of the passed in address, without the commas between them.
+func addressWords(address string) []string {
+	return strings.Fields(strings.Replace(NormalizeAddress(address), ",", " ", -1))
+}
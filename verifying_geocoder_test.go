@@ -0,0 +1,109 @@
+package geo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAddressSimilarity(t *testing.T) {
+	cases := []struct {
+		query    string
+		address  string
+		expected float64
+	}{
+		{"1600 Amphitheatre Parkway, Mountain View, CA", "1600 Amphitheatre Pkwy, Mountain View, CA 94043, USA", 1},
+		{"123 Main Street", "123 main st.", 1},
+		{"123 Main Street", "125 Main St", 2.0 / 3.0},
+		{"123 Main Street", "San Francisco International Airport", 0},
+		{"", "123 Main St", 0},
+	}
+
+	for _, c := range cases {
+		if res := AddressSimilarity(c.query, c.address); res != c.expected {
+			t.Errorf("Mismatched similarity of %q and %q.  Expected: %f.  Actual: %f", c.query, c.address, c.expected, res)
+		}
+	}
+}
+
+func TestVerifyGeocode(t *testing.T) {
+	g := NewVerifyingGeocoder(&countingGeocoder{})
+
+	res, ok, err := g.VerifyGeocode("San Francisco International Airport, CA")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !ok {
+		t.Error("Expected the round trip to match the query")
+	}
+
+	if !res.Point.Equal(NewPoint(37.619002, -122.37484)) || res.FormattedAddress != "San Francisco International Airport" {
+		t.Errorf("Mismatched result.  Actual: %+v", res)
+	}
+
+	res, ok, err = g.VerifyGeocode("1 Infinite Loop, Cupertino")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if ok {
+		t.Errorf("Expected the round trip not to match the query.  Actual: %s", res.FormattedAddress)
+	}
+}
+
+func TestVerifyGeocodeCustomSimilarity(t *testing.T) {
+	var query, address string
+	g := &VerifyingGeocoder{
+		Geocoder: &countingGeocoder{},
+		Similarity: func(q string, a string) float64 {
+			query, address = q, a
+			return 0.5
+		},
+		Threshold: 0.4,
+	}
+
+	_, ok, err := g.VerifyGeocode("SFO")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !ok {
+		t.Error("Expected a similarity above the custom threshold to match")
+	}
+
+	if query != "SFO" || address != "San Francisco International Airport" {
+		t.Errorf("Mismatched similarity arguments.  Actual: %q, %q", query, address)
+	}
+
+	g.Threshold = 0.6
+	if _, ok, _ := g.VerifyGeocode("SFO"); ok {
+		t.Error("Expected a similarity below the custom threshold not to match")
+	}
+}
+
+func TestVerifyGeocodeErrors(t *testing.T) {
+	geocodeErr := errors.New("geocode failed")
+	res, ok, err := NewVerifyingGeocoder(&countingGeocoder{err: geocodeErr}).VerifyGeocode("SFO")
+	if err != geocodeErr || res != nil || ok {
+		t.Errorf("Expected the geocoding error.  Actual: %v, %t, %v", res, ok, err)
+	}
+
+	reverseErr := errors.New("reverse geocode failed")
+	g := NewVerifyingGeocoder(&GeocoderFuncs{
+		GeocodeFunc: func(query string, opts ...GeocodeOption) (*Point, error) {
+			return NewPoint(1, 2), nil
+		},
+		ReverseGeocodeFunc: func(p *Point, opts ...GeocodeOption) (string, error) {
+			return "", reverseErr
+		},
+	})
+
+	res, ok, err = g.VerifyGeocode("SFO")
+	if err != reverseErr || ok {
+		t.Errorf("Expected the reverse geocoding error.  Actual: %t, %v", ok, err)
+	}
+
+	if res == nil || !res.Point.Equal(NewPoint(1, 2)) {
+		t.Errorf("Expected the geocoded point along with the error.  Actual: %+v", res)
+	}
+}
@@ -0,0 +1,267 @@
+package geo
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Parses a Point from its Well-Known Text representation, e.g. "POINT (30 10)".
+// Coordinates are in x y (lng lat) order.
+// Returns a *ParseError pointing at the offending token if the input is malformed.
+func ParsePointWKT(s string, mode ParseMode) (*Point, error) {
+	p := newWKTParser(s, mode)
+
+	if err := p.expectKeyword("POINT"); err != nil {
+		return nil, err
+	}
+
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	offset := p.peek().offset
+	point, err := p.coordinate()
+	if err != nil {
+		return nil, err
+	}
+
+	if mode == ParseStrict {
+		if err := validateCoordinate(point, offset); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+
+	if err := p.expectEOF(); err != nil {
+		return nil, err
+	}
+
+	return point, nil
+}
+
+// Parses a Polygon from its Well-Known Text representation, e.g. "POLYGON ((30 10, 40 40, 20 40, 30 10))".
+// Coordinates are in x y (lng lat) order.  Any interior rings (holes) are appended to the
+// exterior ring as additional contours, in the same way as Polygon.Add.
+// Returns a *ParseError pointing at the offending token if the input is malformed.
+func ParsePolygonWKT(s string, mode ParseMode) (*Polygon, error) {
+	p := newWKTParser(s, mode)
+
+	if err := p.expectKeyword("POLYGON"); err != nil {
+		return nil, err
+	}
+
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	polygon := &Polygon{}
+	for {
+		offset := p.peek().offset
+		ring, err := p.ring()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := validateRing(ring, mode, offset); err != nil {
+			return nil, err
+		}
+
+		for _, point := range ring {
+			polygon.Add(point)
+		}
+
+		more, err := p.list()
+		if err != nil {
+			return nil, err
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	if err := p.expectEOF(); err != nil {
+		return nil, err
+	}
+
+	return polygon, nil
+}
+
+type wktToken struct {
+	text   string
+	offset int
+}
+
+// A recursive descent parser over a pre-lexed list of WKT tokens.
+type wktParser struct {
+	tokens []wktToken
+	pos    int
+	mode   ParseMode
+	eof    int
+}
+
+func newWKTParser(s string, mode ParseMode) *wktParser {
+	return &wktParser{tokens: lexWKT(s), mode: mode, eof: len(s)}
+}
+
+// Splits the passed in string into words, numbers, and punctuation.
+func lexWKT(s string) []wktToken {
+	tokens := []wktToken{}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')' || c == ',':
+			tokens = append(tokens, wktToken{text: string(c), offset: i})
+			i++
+		default:
+			start := i
+			for i < len(s) && !strings.ContainsRune(" \t\n\r(),", rune(s[i])) {
+				i++
+			}
+			tokens = append(tokens, wktToken{text: s[start:i], offset: start})
+		}
+	}
+
+	return tokens
+}
+
+// Returns the next token without consuming it, or an empty token at the end of the input.
+func (p *wktParser) peek() wktToken {
+	if p.pos >= len(p.tokens) {
+		return wktToken{offset: p.eof}
+	}
+
+	return p.tokens[p.pos]
+}
+
+func (p *wktParser) next() wktToken {
+	t := p.peek()
+	if p.pos < len(p.tokens) {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *wktParser) errorAt(t wktToken, msg string) error {
+	if t.text == "" {
+		return &ParseError{Offset: t.offset, Msg: msg + ", found end of input"}
+	}
+
+	return &ParseError{Offset: t.offset, Token: t.text, Msg: msg}
+}
+
+// Consumes the passed in keyword.  Strict mode requires it to be upper case.
+func (p *wktParser) expectKeyword(keyword string) error {
+	t := p.next()
+
+	if t.text == keyword || (p.mode != ParseStrict && strings.EqualFold(t.text, keyword)) {
+		return nil
+	}
+
+	return p.errorAt(t, "expected "+keyword)
+}
+
+func (p *wktParser) expect(text string) error {
+	t := p.next()
+	if t.text != text {
+		return p.errorAt(t, "expected \""+text+"\"")
+	}
+
+	return nil
+}
+
+func (p *wktParser) expectEOF() error {
+	t := p.next()
+	if t.text != "" {
+		return p.errorAt(t, "unexpected trailing input")
+	}
+
+	return nil
+}
+
+// Consumes the separator after a list item.  Returns true if another item follows,
+// or false if the list was closed.  Lenient mode allows a trailing comma before the close.
+func (p *wktParser) list() (bool, error) {
+	t := p.next()
+
+	switch t.text {
+	case ")":
+		return false, nil
+	case ",":
+		if p.peek().text == ")" {
+			if p.mode == ParseStrict {
+				return false, p.errorAt(t, "trailing comma")
+			}
+
+			p.next()
+			return false, nil
+		}
+
+		return true, nil
+	default:
+		return false, p.errorAt(t, "expected \",\" or \")\"")
+	}
+}
+
+// Parses a parenthesized, comma separated list of coordinates.
+func (p *wktParser) ring() ([]*Point, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	points := []*Point{}
+	for {
+		point, err := p.coordinate()
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, point)
+
+		more, err := p.list()
+		if err != nil {
+			return nil, err
+		}
+
+		if !more {
+			return points, nil
+		}
+	}
+}
+
+// Parses an "x y" coordinate pair into a Point.
+func (p *wktParser) coordinate() (*Point, error) {
+	lng, err := p.number()
+	if err != nil {
+		return nil, err
+	}
+
+	lat, err := p.number()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPoint(lat, lng), nil
+}
+
+func (p *wktParser) number() (float64, error) {
+	t := p.next()
+
+	// ParseFloat also accepts forms such as "NaN", "Inf", and hex floats, which are not valid WKT numbers.
+	if t.text == "" || strings.Trim(t.text, "+-.0123456789eE") != "" {
+		return 0, p.errorAt(t, "expected a number")
+	}
+
+	f, err := strconv.ParseFloat(t.text, 64)
+	if err != nil {
+		return 0, p.errorAt(t, "expected a number")
+	}
+
+	return f, nil
+}
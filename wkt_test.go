@@ -0,0 +1,113 @@
+package geo
+
+import (
+	"testing"
+)
+
+func TestParsePointWKT(t *testing.T) {
+	p, err := ParsePointWKT("POINT (30 10)", ParseStrict)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if p.lat != 10 || p.lng != 30 {
+		t.Errorf("Mismatched point.  Expected: [10, 30], Actual: %v", p)
+	}
+
+	p, err = ParsePointWKT("  point(  -122.37484   37.619002 )\n", ParseLenient)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if p.lat != 37.619002 || p.lng != -122.37484 {
+		t.Errorf("Mismatched point.  Expected: [37.619002, -122.37484], Actual: %v", p)
+	}
+}
+
+func TestParsePolygonWKT(t *testing.T) {
+	poly, err := ParsePolygonWKT("POLYGON ((30 10, 40 40, 20 40, 10 20, 30 10))", ParseStrict)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(poly.Points()) != 5 {
+		t.Errorf("Expected 5 points, but got %d", len(poly.Points()))
+	}
+
+	if !poly.Contains(NewPoint(30, 25)) {
+		t.Error("Expected the parsed polygon to contain [30, 25]")
+	}
+
+	// Holes are appended as additional contours.
+	poly, err = ParsePolygonWKT("POLYGON ((35 10, 45 45, 15 40, 10 20, 35 10), (20 30, 35 35, 30 20, 20 30))", ParseStrict)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(poly.Points()) != 9 {
+		t.Errorf("Expected 9 points, but got %d", len(poly.Points()))
+	}
+
+	// Lenient mode tolerates trailing commas and unclosed rings.
+	poly, err = ParsePolygonWKT("polygon ((30 10, 40 40, 20 40,),)", ParseLenient)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(poly.Points()) != 3 {
+		t.Errorf("Expected 3 points, but got %d", len(poly.Points()))
+	}
+}
+
+func TestParseWKTErrors(t *testing.T) {
+	cases := []struct {
+		input  string
+		mode   ParseMode
+		offset int
+		token  string
+	}{
+		{"POINT (30)", ParseStrict, 9, ")"},
+		{"POINT (30 abc)", ParseStrict, 10, "abc"},
+		{"POINT (30 10) garbage", ParseLenient, 14, "garbage"},
+		{"point (30 10)", ParseStrict, 0, "point"},
+		{"LINESTRING (30 10, 10 30)", ParseLenient, 0, "LINESTRING"},
+		{"POINT (30 NaN)", ParseLenient, 10, "NaN"},
+		{"POINT (200 10)", ParseStrict, 7, "200 10"},
+		{"POLYGON ((30 10, 40 40, 20 40,))", ParseStrict, 29, ","},
+		{"POLYGON ((30 10, 40 40, 20 40))", ParseStrict, 9, ""},
+		{"POLYGON ((30 10, 40 40))", ParseLenient, 9, ""},
+		{"POLYGON ((30 10, 40 40, 20 40, 30 10)", ParseStrict, 37, ""},
+	}
+
+	for _, c := range cases {
+		_, err := ParsePointWKT(c.input, c.mode)
+		if c.input[0] == 'P' && c.input[1] == 'O' && c.input[2] == 'L' {
+			_, err = ParsePolygonWKT(c.input, c.mode)
+		}
+
+		parseErr, ok := err.(*ParseError)
+		if !ok {
+			t.Errorf("Expected a *ParseError for %q, but got: %v", c.input, err)
+			continue
+		}
+
+		if parseErr.Offset != c.offset || parseErr.Token != c.token {
+			t.Errorf("Mismatched error for %q.  Expected offset %d near %q, Actual: %v", c.input, c.offset, c.token, parseErr)
+		}
+	}
+}
+
+// Ensures that the WKT parsers never panic, whatever bytes they are given.
+func FuzzParsePolygonWKT(f *testing.F) {
+	f.Add("POLYGON ((30 10, 40 40, 20 40, 10 20, 30 10))")
+	f.Add("POINT (30 10)")
+	f.Add("POLYGON ((")
+	f.Add(")),(( 1e999 -")
+
+	f.Fuzz(func(t *testing.T, s string) {
+		ParsePolygonWKT(s, ParseStrict)
+		ParsePolygonWKT(s, ParseLenient)
+		ParsePointWKT(s, ParseStrict)
+		ParsePointWKT(s, ParseLenient)
+	})
+}